@@ -1,13 +1,56 @@
 package config
 
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
 type Config struct {
 	// Define your configuration fields here
 	DataNodeAddress string
+	// GRPCPort is the port the DataNodeService listens on
+	GRPCPort string
+	// HTTPPort is the port the REST API listens on
+	HTTPPort string
+	// BaseDir is where blocks and their metadata are stored
+	BaseDir string
+	// NameNodeAddress is the gRPC address of the NameNode
+	NameNodeAddress string
+	// BlockReportInterval is how often every stored block is reported to the NameNode
+	BlockReportInterval time.Duration
+	// HeartbeatInterval is how often liveness and storage statistics are sent
+	// to the NameNode
+	HeartbeatInterval time.Duration
 }
 
 func LoadConfig() (*Config, error) {
-	// Load configuration from file, environment, etc.
+	// Load configuration from the environment, falling back to defaults so
+	// several DataNodes can run side by side on one host
+	blockReportInterval, err := time.ParseDuration(getEnv("DATANODE_BLOCK_REPORT_INTERVAL", "1h"))
+	if err != nil || blockReportInterval <= 0 {
+		return nil, fmt.Errorf("invalid DATANODE_BLOCK_REPORT_INTERVAL: %q", os.Getenv("DATANODE_BLOCK_REPORT_INTERVAL"))
+	}
+	heartbeatInterval, err := time.ParseDuration(getEnv("DATANODE_HEARTBEAT_INTERVAL", "30s"))
+	if err != nil || heartbeatInterval <= 0 {
+		return nil, fmt.Errorf("invalid DATANODE_HEARTBEAT_INTERVAL: %q", os.Getenv("DATANODE_HEARTBEAT_INTERVAL"))
+	}
+
 	return &Config{
-		DataNodeAddress: "localhost:50010", // Example address
+		DataNodeAddress:     "localhost:50010", // Example address
+		GRPCPort:            getEnv("DATANODE_GRPC_PORT", "50052"),
+		HTTPPort:            getEnv("DATANODE_HTTP_PORT", "8081"),
+		BaseDir:             getEnv("DATANODE_BASE_DIR", "./"),
+		NameNodeAddress:     getEnv("NAMENODE_ADDRESS", "localhost:50051"),
+		BlockReportInterval: blockReportInterval,
+		HeartbeatInterval:   heartbeatInterval,
 	}, nil
 }
+
+// getEnv returns the value of the environment variable key, or fallback if unset
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,101 @@
+package datamgmt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// StoredBlock is a block replica held by this DataNode
+type StoredBlock struct {
+	ID   string
+	Size int64
+}
+
+// ListBlocks scans the data and metadata directories for the blocks stored on
+// this DataNode. A block file without metadata was never committed and is
+// left out.
+func (dm *DataManager) ListBlocks() ([]StoredBlock, error) {
+	entries, err := os.ReadDir(dm.dataPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data directory: %v", err)
+	}
+
+	blocks := make([]StoredBlock, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), blockPrefix) {
+			continue
+		}
+		blockID := strings.TrimPrefix(entry.Name(), blockPrefix)
+		if _, err := os.Stat(dm.metadataFile(blockID)); err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		blocks = append(blocks, StoredBlock{ID: blockID, Size: info.Size()})
+	}
+
+	return blocks, nil
+}
+
+// blockChanges collects the blocks stored and deleted since the last
+// incremental block report
+type blockChanges struct {
+	mu sync.Mutex
+	// changes maps block IDs to the block when stored, or nil when deleted;
+	// the latest change to a block wins
+	changes map[string]*StoredBlock
+}
+
+func (c *blockChanges) record(blockID string, block *StoredBlock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.changes == nil {
+		c.changes = make(map[string]*StoredBlock)
+	}
+	c.changes[blockID] = block
+}
+
+// TakeBlockChanges returns the blocks stored and deleted since the previous call
+func (dm *DataManager) TakeBlockChanges() (received []StoredBlock, deleted []string) {
+	dm.changes.mu.Lock()
+	defer dm.changes.mu.Unlock()
+
+	for blockID, block := range dm.changes.changes {
+		if block != nil {
+			received = append(received, *block)
+		} else {
+			deleted = append(deleted, blockID)
+		}
+	}
+	dm.changes.changes = nil
+
+	return received, deleted
+}
+
+// RestoreBlockChanges puts back changes that could not be reported, unless
+// the same blocks changed again in the meantime
+func (dm *DataManager) RestoreBlockChanges(received []StoredBlock, deleted []string) {
+	dm.changes.mu.Lock()
+	defer dm.changes.mu.Unlock()
+
+	if dm.changes.changes == nil {
+		dm.changes.changes = make(map[string]*StoredBlock)
+	}
+	for i := range received {
+		if _, newer := dm.changes.changes[received[i].ID]; !newer {
+			dm.changes.changes[received[i].ID] = &received[i]
+		}
+	}
+	for _, blockID := range deleted {
+		if _, newer := dm.changes.changes[blockID]; !newer {
+			dm.changes.changes[blockID] = nil
+		}
+	}
+}
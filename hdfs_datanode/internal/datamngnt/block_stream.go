@@ -0,0 +1,298 @@
+package datamgmt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// chunkSize is the amount of block data covered by each chunk checksum
+const chunkSize = 64 * 1024
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// BlockWriter streams a block to disk without holding it in memory.
+// Data is written to a temporary file of its own and only moved into the data
+// directory by Commit, so a partially received block is never visible.
+type BlockWriter struct {
+	dm      *DataManager
+	blockID string
+	file    *os.File
+	tmpPath string
+	hash    hash.Hash
+	size    int64
+	done    bool
+
+	// Checksums of the complete chunks written so far, and of the current one
+	chunkChecksums []uint32
+	chunkChecksum  uint32
+	chunkFill      int64
+}
+
+// CreateBlock starts writing a new block
+func (dm *DataManager) CreateBlock(blockID string) (*BlockWriter, error) {
+	if err := validateBlockID(blockID); err != nil {
+		return nil, err
+	}
+
+	// Ensure the temporary directory exists
+	if err := os.MkdirAll(dm.tmpPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create tmp directory: %v", err)
+	}
+
+	// Writers of the same block, e.g. a retried pipeline, each get a file
+	file, err := os.CreateTemp(dm.tmpPath, blockPrefix+blockID+"-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data block: %v", err)
+	}
+	dm.activeTransfers.Add(1)
+
+	return &BlockWriter{
+		dm:      dm,
+		blockID: blockID,
+		file:    file,
+		tmpPath: file.Name(),
+		hash:    sha256.New(),
+	}, nil
+}
+
+// Write appends data to the block
+func (w *BlockWriter) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	w.hash.Write(p[:n])
+	w.updateChunkChecksums(p[:n])
+	w.size += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("failed to write data block: %v", err)
+	}
+	return n, nil
+}
+
+// updateChunkChecksums adds data written to the block to the chunk checksums
+func (w *BlockWriter) updateChunkChecksums(p []byte) {
+	for len(p) > 0 {
+		n := min(int64(len(p)), chunkSize-w.chunkFill)
+		w.chunkChecksum = crc32.Update(w.chunkChecksum, castagnoliTable, p[:n])
+		w.chunkFill += n
+		p = p[n:]
+		if w.chunkFill == chunkSize {
+			w.chunkChecksums = append(w.chunkChecksums, w.chunkChecksum)
+			w.chunkChecksum, w.chunkFill = 0, 0
+		}
+	}
+}
+
+// ReadAt reads back data written to the block so far
+func (w *BlockWriter) ReadAt(p []byte, off int64) (int, error) {
+	return w.file.ReadAt(p, off)
+}
+
+// Size returns the number of bytes written so far
+func (w *BlockWriter) Size() int64 {
+	return w.size
+}
+
+// Commit flushes the block to disk, stores its metadata and moves it into the
+// data directory. The metadata is durable before the block is visible, so a
+// stored block can always be verified.
+func (w *BlockWriter) Commit() error {
+	defer w.finish()
+
+	if err := w.file.Sync(); err != nil {
+		w.Abort()
+		return fmt.Errorf("failed to sync data block: %v", err)
+	}
+	if err := w.file.Close(); err != nil {
+		os.Remove(w.tmpPath)
+		return fmt.Errorf("failed to close data block: %v", err)
+	}
+
+	// Ensure the data directory exists
+	if err := os.MkdirAll(w.dm.dataPath, 0755); err != nil {
+		os.Remove(w.tmpPath)
+		return fmt.Errorf("failed to create data directory: %v", err)
+	}
+
+	if w.chunkFill > 0 {
+		w.chunkChecksums = append(w.chunkChecksums, w.chunkChecksum)
+	}
+	metadata := &BlockMetadata{
+		ID:             w.blockID,
+		Size:           w.size,
+		Checksum:       hex.EncodeToString(w.hash.Sum(nil)),
+		ChunkSize:      chunkSize,
+		ChunkChecksums: w.chunkChecksums,
+		CreatedAt:      time.Now(),
+	}
+	if err := w.dm.SaveMetadata(metadata); err != nil {
+		os.Remove(w.tmpPath)
+		return fmt.Errorf("failed to save metadata: %v", err)
+	}
+
+	blockPath := filepath.Join(w.dm.dataPath, blockPrefix+w.blockID)
+	if err := os.Rename(w.tmpPath, blockPath); err != nil {
+		os.Remove(w.tmpPath)
+		os.Remove(w.dm.metadataFile(w.blockID))
+		return fmt.Errorf("failed to finalize data block: %v", err)
+	}
+	if err := syncDir(w.dm.dataPath); err != nil {
+		return fmt.Errorf("failed to sync data directory: %v", err)
+	}
+	w.dm.changes.record(w.blockID, &StoredBlock{ID: w.blockID, Size: w.size})
+
+	return nil
+}
+
+// Abort discards a partially written block
+func (w *BlockWriter) Abort() {
+	defer w.finish()
+
+	w.file.Close()
+	os.Remove(w.tmpPath)
+}
+
+// finish ends the transfer, whether the block was committed or aborted
+func (w *BlockWriter) finish() {
+	if !w.done {
+		w.done = true
+		w.dm.activeTransfers.Add(-1)
+	}
+}
+
+// BlockReader streams a stored block from disk.
+// Every chunk is verified against its checksum before any of it is returned,
+// so corrupt data is reported with ErrCorruptBlock instead of being read.
+// Blocks stored without chunk checksums are verified as a whole when opened.
+type BlockReader struct {
+	dm        *DataManager
+	file      *os.File
+	blockID   string
+	size      int64
+	checksums []uint32
+	// chunk is the verified data of the current chunk not read yet, and
+	// next the index of the chunk after it
+	chunk []byte
+	buf   []byte
+	next  int
+}
+
+// OpenBlock opens a stored block for reading, starting at offset
+func (dm *DataManager) OpenBlock(blockID string, offset int64) (*BlockReader, error) {
+	if err := validateBlockID(blockID); err != nil {
+		return nil, err
+	}
+
+	blockPath := filepath.Join(dm.dataPath, blockPrefix+blockID)
+	file, err := os.Open(blockPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrBlockNotFound, blockID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data block: %v", err)
+	}
+
+	metadata, err := dm.LoadMetadata(blockID)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to load metadata: %v", err)
+	}
+
+	dm.activeTransfers.Add(1)
+	reader := &BlockReader{
+		dm:        dm,
+		file:      file,
+		blockID:   blockID,
+		size:      metadata.Size,
+		checksums: metadata.ChunkChecksums,
+	}
+
+	if metadata.ChunkSize != chunkSize || len(metadata.ChunkChecksums) == 0 {
+		// Older blocks only have a checksum of the whole block
+		hash := sha256.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			reader.Close()
+			return nil, fmt.Errorf("failed to read data block: %v", err)
+		}
+		if hex.EncodeToString(hash.Sum(nil)) != metadata.Checksum {
+			reader.Close()
+			return nil, fmt.Errorf("%w: %s", ErrCorruptBlock, blockID)
+		}
+		reader.checksums = nil
+		if _, err := file.Seek(offset, io.SeekStart); err != nil {
+			reader.Close()
+			return nil, fmt.Errorf("failed to seek data block: %v", err)
+		}
+		return reader, nil
+	}
+
+	// A resumed read starts at the chunk holding offset, to verify it
+	reader.buf = make([]byte, chunkSize)
+	reader.next = int(offset / chunkSize)
+	if _, err := file.Seek(int64(reader.next)*chunkSize, io.SeekStart); err != nil {
+		reader.Close()
+		return nil, fmt.Errorf("failed to seek data block: %v", err)
+	}
+	if offset%chunkSize > 0 {
+		if err := reader.readChunk(); err != nil {
+			reader.Close()
+			return nil, err
+		}
+		reader.chunk = reader.chunk[min(offset%chunkSize, int64(len(reader.chunk))):]
+	}
+
+	return reader, nil
+}
+
+// readChunk reads and verifies the next chunk of the block
+func (r *BlockReader) readChunk() error {
+	start := int64(r.next) * chunkSize
+	length := min(chunkSize, r.size-start)
+	if r.next >= len(r.checksums) || length <= 0 {
+		return io.EOF
+	}
+	if _, err := io.ReadFull(r.file, r.buf[:length]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%w: %s is truncated", ErrCorruptBlock, r.blockID)
+		}
+		return fmt.Errorf("failed to read data block: %v", err)
+	}
+	if crc32.Checksum(r.buf[:length], castagnoliTable) != r.checksums[r.next] {
+		return fmt.Errorf("%w: %s at offset %d", ErrCorruptBlock, r.blockID, start)
+	}
+	r.chunk = r.buf[:length]
+	r.next++
+	return nil
+}
+
+// Read reads the next verified part of the block
+func (r *BlockReader) Read(p []byte) (int, error) {
+	if r.checksums == nil {
+		return r.file.Read(p)
+	}
+	if len(r.chunk) == 0 {
+		if err := r.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+	return n, nil
+}
+
+// Close closes the underlying block file
+func (r *BlockReader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	r.dm.activeTransfers.Add(-1)
+	return err
+}
@@ -0,0 +1,169 @@
+package datamgmt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// blockData returns size bytes of data that differ from chunk to chunk
+func blockData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+func readBlock(dm *DataManager, blockID string, offset int64) ([]byte, error) {
+	reader, err := dm.OpenBlock(blockID, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func TestBlockRoundTrip(t *testing.T) {
+	dm := NewDataManager(t.TempDir())
+	data := blockData(2*chunkSize + 100)
+	if err := dm.StoreBlock("round-trip", data); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := dm.LoadMetadata("round-trip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.ChunkSize != chunkSize || len(metadata.ChunkChecksums) != 3 {
+		t.Fatalf("unexpected chunk checksums: %d of %d bytes", len(metadata.ChunkChecksums), metadata.ChunkSize)
+	}
+
+	// Reads may start anywhere, including inside a chunk and at the end
+	for _, offset := range []int64{0, 10, chunkSize, chunkSize + 1, int64(len(data))} {
+		got, err := readBlock(dm, "round-trip", offset)
+		if err != nil {
+			t.Fatalf("read from %d: %v", offset, err)
+		}
+		if !bytes.Equal(got, data[offset:]) {
+			t.Fatalf("read from %d returned the wrong data", offset)
+		}
+	}
+
+	if got, err := dm.RetrieveBlock("round-trip"); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("RetrieveBlock returned %d bytes, %v", len(got), err)
+	}
+}
+
+func TestBlockReaderStopsAtCorruptChunk(t *testing.T) {
+	dm := NewDataManager(t.TempDir())
+	data := blockData(3 * chunkSize)
+	if err := dm.StoreBlock("corrupt", data); err != nil {
+		t.Fatal(err)
+	}
+
+	// Flip a byte in the second chunk
+	blockPath := filepath.Join(dm.dataPath, blockPrefix+"corrupt")
+	file, err := os.OpenFile(blockPath, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteAt([]byte{^data[chunkSize+5]}, chunkSize+5); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	// The first chunk is served, but none of the corrupt one
+	reader, err := dm.OpenBlock("corrupt", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	got, err := io.ReadAll(reader)
+	if !errors.Is(err, ErrCorruptBlock) {
+		t.Fatalf("expected ErrCorruptBlock, got %v", err)
+	}
+	if !bytes.Equal(got, data[:chunkSize]) {
+		t.Fatalf("read %d bytes before the corrupt chunk, expected %d", len(got), chunkSize)
+	}
+
+	// Resuming inside the corrupt chunk fails before returning anything
+	if _, err := dm.OpenBlock("corrupt", chunkSize+10); !errors.Is(err, ErrCorruptBlock) {
+		t.Fatalf("expected ErrCorruptBlock, got %v", err)
+	}
+}
+
+func TestBlockReaderVerifiesBlocksWithoutChunkChecksums(t *testing.T) {
+	dm := NewDataManager(t.TempDir())
+	data := blockData(100)
+	if err := dm.StoreBlock("legacy", data); err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := dm.LoadMetadata("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata.ChunkSize, metadata.ChunkChecksums = 0, nil
+	if err := dm.SaveMetadata(metadata); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := readBlock(dm, "legacy", 40); err != nil || !bytes.Equal(got, data[40:]) {
+		t.Fatalf("read returned %d bytes, %v", len(got), err)
+	}
+
+	// A corrupt block is rejected before anything is read
+	blockPath := filepath.Join(dm.dataPath, blockPrefix+"legacy")
+	if err := os.WriteFile(blockPath, blockData(99), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dm.OpenBlock("legacy", 0); !errors.Is(err, ErrCorruptBlock) {
+		t.Fatalf("expected ErrCorruptBlock, got %v", err)
+	}
+}
+
+func TestConcurrentWritersOfABlock(t *testing.T) {
+	dm := NewDataManager(t.TempDir())
+
+	// Two writers of the same block don't share a temporary file, so the
+	// one aborted leaves the other intact
+	first, err := dm.CreateBlock("shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := dm.CreateBlock("shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.tmpPath == second.tmpPath {
+		t.Fatalf("writers share %s", first.tmpPath)
+	}
+
+	data := blockData(1000)
+	if _, err := first.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Write([]byte("partial")); err != nil {
+		t.Fatal(err)
+	}
+	second.Abort()
+	if err := first.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := readBlock(dm, "shared", 0); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("read returned %d bytes, %v", len(got), err)
+	}
+	if entries, _ := os.ReadDir(dm.tmpPath); len(entries) != 0 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+	if entries, _ := os.ReadDir(dm.metadataPath); len(entries) != 1 {
+		t.Fatalf("expected only the block metadata, got %v", entries)
+	}
+	if stats := dm.activeTransfers.Load(); stats != 0 {
+		t.Fatalf("%d transfers still active", stats)
+	}
+}
@@ -2,84 +2,141 @@ package datamgmt
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"time"
+	"strings"
+	"sync/atomic"
 )
 
 const (
 	dataDir     = "data"
 	metadataDir = "metadata"
+	tmpDir      = "tmp"
 	blockPrefix = "block_"
 )
 
+var (
+	// ErrBlockNotFound is returned when a block is not stored on this DataNode
+	ErrBlockNotFound = errors.New("block not found")
+	// ErrInvalidBlockID is returned for block IDs that cannot be mapped to a file
+	ErrInvalidBlockID = errors.New("invalid block ID")
+	// ErrCorruptBlock is returned when a block does not match its checksum
+	ErrCorruptBlock = errors.New("block is corrupt")
+)
+
 // DataManager handles storage and retrieval of data blocks
 type DataManager struct {
+	basePath     string
 	dataPath     string
 	metadataPath string
+	tmpPath      string
+
+	// Number of block reads and writes in progress
+	activeTransfers atomic.Int32
+	// Blocks stored and deleted since the last incremental block report
+	changes blockChanges
 }
 
 // NewDataManager creates a new instance of DataManager
 func NewDataManager(basePath string) *DataManager {
 	return &DataManager{
+		basePath:     basePath,
 		dataPath:     filepath.Join(basePath, dataDir),
 		metadataPath: filepath.Join(basePath, metadataDir),
+		tmpPath:      filepath.Join(basePath, tmpDir),
 	}
 }
 
+// StoreBlock writes a data block and its metadata to disk
 func (dm *DataManager) StoreBlock(blockID string, data []byte) error {
-	// Ensure the data directory exists
-	if err := os.MkdirAll(dm.dataPath, 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %v", err)
-	}
-
-	blockPath := filepath.Join(dm.dataPath, blockPrefix+blockID)
-
-	if err := os.WriteFile(blockPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write data block: %v", err)
+	writer, err := dm.CreateBlock(blockID)
+	if err != nil {
+		return err
 	}
 
-	// Create and store metadata
-	metadata := &BlockMetadata{
-		ID:        blockID,
-		Size:      int64(len(data)),
-		Checksum:  calculateChecksum(data),
-		CreatedAt: time.Now(),
-	}
-	if err := dm.SaveMetadata(metadata); err != nil {
-		return fmt.Errorf("failed to save metadata: %v", err)
+	if _, err := writer.Write(data); err != nil {
+		writer.Abort()
+		return err
 	}
 
-	return nil
+	return writer.Commit()
 }
 
 // RetrieveBlock retrieves the data for the given block ID
+// and verifies it against the checksum recorded when it was stored
 func (dm *DataManager) RetrieveBlock(blockID string) ([]byte, error) {
+	if err := validateBlockID(blockID); err != nil {
+		return nil, err
+	}
+
+	dm.activeTransfers.Add(1)
+	defer dm.activeTransfers.Add(-1)
+
 	blockPath := filepath.Join(dm.dataPath, blockPrefix+blockID)
 	data, err := ioutil.ReadFile(blockPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrBlockNotFound, blockID)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read data block: %v", err)
 	}
+
+	metadata, err := dm.LoadMetadata(blockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metadata: %v", err)
+	}
+	if metadata.Checksum != calculateChecksum(data) {
+		return nil, fmt.Errorf("%w: %s", ErrCorruptBlock, blockID)
+	}
+
 	return data, nil
 }
 
 // File: internal/datamgmt/data_manager.go (continued)
 
-// SaveMetadata saves the metadata for a given data block
+// SaveMetadata saves the metadata for a given data block. It is written to a
+// temporary file and renamed into place, and synced to disk before returning.
 func (dm *DataManager) SaveMetadata(metadata *BlockMetadata) error {
-	metadataPath := filepath.Join(dm.metadataPath, metadata.ID+".metadata")
+	// Ensure the metadata directory exists
+	if err := os.MkdirAll(dm.metadataPath, 0755); err != nil {
+		return fmt.Errorf("failed to create metadata directory: %v", err)
+	}
+
 	data, err := json.Marshal(metadata)
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(metadataPath, data, 0644)
+	file, err := os.CreateTemp(dm.metadataPath, metadata.ID+".metadata-*")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), dm.metadataFile(metadata.ID))
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return syncDir(dm.metadataPath)
+}
+
+// metadataFile returns the path of the metadata of a block
+func (dm *DataManager) metadataFile(blockID string) string {
+	return filepath.Join(dm.metadataPath, blockID+".metadata")
 }
 
 // LoadMetadata loads the metadata for a given block ID
 func (dm *DataManager) LoadMetadata(blockID string) (*BlockMetadata, error) {
-	metadataPath := filepath.Join(dm.metadataPath, blockID+".metadata")
+	metadataPath := dm.metadataFile(blockID)
 	data, err := ioutil.ReadFile(metadataPath)
 	if err != nil {
 		return nil, err
@@ -91,3 +148,29 @@ func (dm *DataManager) LoadMetadata(blockID string) (*BlockMetadata, error) {
 	}
 	return &metadata, nil
 }
+
+// DeleteBlock removes a block and its metadata. Deleting a block that is not
+// stored is not an error, so a repeated delete command is harmless.
+func (dm *DataManager) DeleteBlock(blockID string) error {
+	if err := validateBlockID(blockID); err != nil {
+		return err
+	}
+
+	blockPath := filepath.Join(dm.dataPath, blockPrefix+blockID)
+	if err := os.Remove(blockPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete data block: %v", err)
+	}
+	if err := os.Remove(dm.metadataFile(blockID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete metadata: %v", err)
+	}
+	dm.changes.record(blockID, nil)
+	return nil
+}
+
+// validateBlockID rejects block IDs that would escape the data directory
+func validateBlockID(blockID string) error {
+	if blockID == "" || strings.ContainsAny(blockID, `/\`) || strings.Contains(blockID, "..") {
+		return fmt.Errorf("%w: %q", ErrInvalidBlockID, blockID)
+	}
+	return nil
+}
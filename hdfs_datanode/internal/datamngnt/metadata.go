@@ -5,8 +5,12 @@ import (
 )
 
 type BlockMetadata struct {
-	ID        string    `json:"id"`
-	Size      int64     `json:"size"`
-	Checksum  string    `json:"checksum"` // You can use hash functions like SHA-256
-	CreatedAt time.Time `json:"created_at"`
+	ID       string `json:"id"`
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"` // You can use hash functions like SHA-256
+	// ChunkChecksums are the CRC-32C checksums of every ChunkSize bytes of
+	// the block, so a read can verify each chunk before serving it
+	ChunkSize      int64     `json:"chunk_size,omitempty"`
+	ChunkChecksums []uint32  `json:"chunk_checksums,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
 }
@@ -0,0 +1,66 @@
+package datamgmt
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// StorageStats summarises the storage of a DataNode for its heartbeats
+type StorageStats struct {
+	CapacityBytes   int64
+	DfsUsedBytes    int64
+	RemainingBytes  int64
+	BlockCount      int64
+	ActiveTransfers int32
+	VolumeFailures  int32
+}
+
+// Stats reports the capacity and usage of the volume holding the blocks
+func (dm *DataManager) Stats() (*StorageStats, error) {
+	stats := &StorageStats{
+		ActiveTransfers: dm.activeTransfers.Load(),
+		VolumeFailures:  dm.volumeFailures(),
+	}
+
+	capacity, available, err := diskUsage(dm.basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read disk usage: %v", err)
+	}
+	stats.CapacityBytes = capacity
+	stats.RemainingBytes = available
+
+	entries, err := os.ReadDir(dm.dataPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read data directory: %v", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), blockPrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		stats.BlockCount++
+		stats.DfsUsedBytes += info.Size()
+	}
+
+	return stats, nil
+}
+
+// volumeFailures counts the storage directories that exist but can't be used
+func (dm *DataManager) volumeFailures() int32 {
+	var failures int32
+	for _, dir := range []string{dm.dataPath, dm.metadataPath, dm.tmpPath} {
+		info, err := os.Stat(dir)
+		if os.IsNotExist(err) {
+			// Created lazily on the first write
+			continue
+		}
+		if err != nil || !info.IsDir() {
+			failures++
+		}
+	}
+	return failures
+}
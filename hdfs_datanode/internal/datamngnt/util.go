@@ -3,9 +3,21 @@ package datamgmt
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"os"
 )
 
 func calculateChecksum(data []byte) string {
 	hash := sha256.Sum256(data)
 	return hex.EncodeToString(hash[:])
 }
+
+// syncDir flushes the entries of a directory, such as a file renamed into it,
+// to disk
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
@@ -0,0 +1,100 @@
+package datanode
+
+import (
+	"log"
+	"time"
+
+	gRPC "github.com/aarrasseayoub01/namenode/datanode/internal/gRPC"
+)
+
+// incrementalReportInterval is how often blocks stored and deleted since the
+// last report are sent to the NameNode
+const incrementalReportInterval = 3 * time.Second
+
+// requestBlockReport schedules a full block report, e.g. after registering
+// again with a NameNode that lost track of us
+func (dn *DataNode) requestBlockReport() {
+	select {
+	case dn.fullReport <- struct{}{}:
+	default:
+		// A full report is already pending
+	}
+}
+
+// reportBlocks sends a full block report right away and then every
+// BlockReportInterval, with incremental reports in between
+func (dn *DataNode) reportBlocks(address string) {
+	client, err := gRPC.NewDataNodeClient(dn.config.NameNodeAddress)
+	if err != nil {
+		log.Fatalf("Failed to create DataNode client: %v", err)
+	}
+	defer client.Close()
+
+	fullTicker := time.NewTicker(dn.config.BlockReportInterval)
+	defer fullTicker.Stop()
+	incrementalTicker := time.NewTicker(incrementalReportInterval)
+	defer incrementalTicker.Stop()
+
+	dn.sendBlockReport(client, address)
+	for {
+		select {
+		case <-fullTicker.C:
+			dn.sendBlockReport(client, address)
+		case <-dn.fullReport:
+			dn.sendBlockReport(client, address)
+		case <-incrementalTicker.C:
+			dn.sendIncrementalBlockReport(client, address)
+		case <-dn.shutdown:
+			// Report the blocks deleted by the last commands before going away
+			dn.sendIncrementalBlockReport(client, address)
+			return
+		}
+	}
+}
+
+// sendBlockReport reports every block stored on this DataNode
+func (dn *DataNode) sendBlockReport(client *gRPC.DataNodeClient, address string) {
+	// Changes made before the scan are part of the full report
+	received, deleted := dn.dataManager.TakeBlockChanges()
+	blocks, err := dn.dataManager.ListBlocks()
+	if err != nil {
+		log.Printf("Error listing blocks: %v", err)
+		dn.dataManager.RestoreBlockChanges(received, deleted)
+		return
+	}
+
+	known, err := client.SendBlockReport(address, blocks)
+	if err != nil {
+		log.Printf("Error sending block report: %v", err)
+		dn.dataManager.RestoreBlockChanges(received, deleted)
+		return
+	}
+	if !known {
+		// The next heartbeat asks us to register again, which brings
+		// another full report
+		log.Printf("NameNode rejected block report: DataNode is not registered")
+		return
+	}
+	log.Printf("Reported %d blocks to NameNode", len(blocks))
+}
+
+// sendIncrementalBlockReport reports the blocks stored and deleted since the
+// last report
+func (dn *DataNode) sendIncrementalBlockReport(client *gRPC.DataNodeClient, address string) {
+	received, deleted := dn.dataManager.TakeBlockChanges()
+	if len(received) == 0 && len(deleted) == 0 {
+		return
+	}
+
+	known, err := client.SendIncrementalBlockReport(address, received, deleted)
+	if err != nil {
+		log.Printf("Error sending incremental block report: %v", err)
+		// Sent again with the next report
+		dn.dataManager.RestoreBlockChanges(received, deleted)
+		return
+	}
+	if !known {
+		// Covered by the full report sent once we are registered again
+		log.Printf("NameNode rejected incremental block report: DataNode is not registered")
+	}
+}
@@ -0,0 +1,187 @@
+package datanode
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sort"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	gRPC "github.com/aarrasseayoub01/namenode/datanode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// fakeNameNode records the block reports it receives, and fails them while
+// failing is set
+type fakeNameNode struct {
+	protobuf.UnimplementedNameNodeServiceServer
+	failing     bool
+	full        []*protobuf.BlockReportRequest
+	incremental []*protobuf.IncrementalBlockReportRequest
+}
+
+func (n *fakeNameNode) BlockReport(ctx context.Context, req *protobuf.BlockReportRequest) (*protobuf.BlockReportResponse, error) {
+	if n.failing {
+		return nil, status.Error(codes.Unavailable, "NameNode is down")
+	}
+	n.full = append(n.full, req)
+	return &protobuf.BlockReportResponse{Success: true}, nil
+}
+
+func (n *fakeNameNode) BlockReceivedAndDeleted(ctx context.Context, req *protobuf.IncrementalBlockReportRequest) (*protobuf.BlockReportResponse, error) {
+	if n.failing {
+		return nil, status.Error(codes.Unavailable, "NameNode is down")
+	}
+	n.incremental = append(n.incremental, req)
+	return &protobuf.BlockReportResponse{Success: true}, nil
+}
+
+// startFakeNameNode serves nameNode on a local port and returns a client of it
+func startFakeNameNode(t *testing.T, nameNode *fakeNameNode) *gRPC.DataNodeClient {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	protobuf.RegisterNameNodeServiceServer(server, nameNode)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	client, err := gRPC.NewDataNodeClient(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// reportedIDs returns the sorted IDs of reported blocks
+func reportedIDs(blocks []*protobuf.ReportedBlock) []string {
+	var ids []string
+	for _, block := range blocks {
+		ids = append(ids, block.GetBlockId())
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestIncrementalBlockReport(t *testing.T) {
+	dn := newTestDataNode(t)
+	nameNode := &fakeNameNode{}
+	client := startFakeNameNode(t, nameNode)
+
+	for _, blockID := range []string{"blk_1", "blk_2", "blk_3"} {
+		if err := dn.dataManager.StoreBlock(blockID, []byte(blockID+" data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dn.dataManager.DeleteBlock("blk_3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := dn.dataManager.DeleteBlock("blk_old"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the latest change of each block is reported, with its size
+	dn.sendIncrementalBlockReport(client, "10.0.0.1:50052")
+	if len(nameNode.incremental) != 1 {
+		t.Fatalf("sent %d incremental reports", len(nameNode.incremental))
+	}
+	report := nameNode.incremental[0]
+	if report.GetDatanodeAddress() != "10.0.0.1:50052" {
+		t.Fatalf("report from %s", report.GetDatanodeAddress())
+	}
+	if ids := reportedIDs(report.GetReceived()); !slices.Equal(ids, []string{"blk_1", "blk_2"}) {
+		t.Fatalf("unexpected received blocks %v", ids)
+	}
+	for _, block := range report.GetReceived() {
+		if block.GetSize() != int64(len(block.GetBlockId()+" data")) {
+			t.Fatalf("block %s reported with size %d", block.GetBlockId(), block.GetSize())
+		}
+	}
+	deleted := slices.Clone(report.GetDeleted())
+	sort.Strings(deleted)
+	if !slices.Equal(deleted, []string{"blk_3", "blk_old"}) {
+		t.Fatalf("unexpected deleted blocks %v", deleted)
+	}
+
+	// Nothing changed, nothing is sent
+	dn.sendIncrementalBlockReport(client, "10.0.0.1:50052")
+	if len(nameNode.incremental) != 1 {
+		t.Fatalf("sent %d incremental reports", len(nameNode.incremental))
+	}
+
+	// Changes that could not be reported are sent with the next report,
+	// unless the block changed again in the meantime
+	if err := dn.dataManager.StoreBlock("blk_4", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := dn.dataManager.StoreBlock("blk_5", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	nameNode.failing = true
+	dn.sendIncrementalBlockReport(client, "10.0.0.1:50052")
+	nameNode.failing = false
+	if err := dn.dataManager.DeleteBlock("blk_5"); err != nil {
+		t.Fatal(err)
+	}
+	dn.sendIncrementalBlockReport(client, "10.0.0.1:50052")
+	if len(nameNode.incremental) != 2 {
+		t.Fatalf("sent %d incremental reports", len(nameNode.incremental))
+	}
+	report = nameNode.incremental[1]
+	if ids := reportedIDs(report.GetReceived()); !slices.Equal(ids, []string{"blk_4"}) {
+		t.Fatalf("unexpected received blocks %v", ids)
+	}
+	if !slices.Equal(report.GetDeleted(), []string{"blk_5"}) {
+		t.Fatalf("unexpected deleted blocks %v", report.GetDeleted())
+	}
+}
+
+func TestFullBlockReport(t *testing.T) {
+	dn := newTestDataNode(t)
+	nameNode := &fakeNameNode{}
+	client := startFakeNameNode(t, nameNode)
+
+	for _, blockID := range []string{"blk_1", "blk_2"} {
+		if err := dn.dataManager.StoreBlock(blockID, []byte("data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A block file without metadata was never committed
+	writer, err := dn.dataManager.CreateBlock("blk_partial")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Abort()
+
+	// The full report lists every committed block and covers the changes
+	// made before it
+	dn.sendBlockReport(client, "10.0.0.1:50052")
+	if len(nameNode.full) != 1 {
+		t.Fatalf("sent %d full reports", len(nameNode.full))
+	}
+	if ids := reportedIDs(nameNode.full[0].GetBlocks()); !slices.Equal(ids, []string{"blk_1", "blk_2"}) {
+		t.Fatalf("unexpected blocks %v", ids)
+	}
+	dn.sendIncrementalBlockReport(client, "10.0.0.1:50052")
+	if len(nameNode.incremental) != 0 {
+		t.Fatalf("sent %d incremental reports", len(nameNode.incremental))
+	}
+
+	// A failed full report keeps the changes for the next report
+	if err := dn.dataManager.DeleteBlock("blk_1"); err != nil {
+		t.Fatal(err)
+	}
+	nameNode.failing = true
+	dn.sendBlockReport(client, "10.0.0.1:50052")
+	nameNode.failing = false
+	dn.sendIncrementalBlockReport(client, "10.0.0.1:50052")
+	if len(nameNode.incremental) != 1 || !slices.Equal(nameNode.incremental[0].GetDeleted(), []string{"blk_1"}) {
+		t.Fatalf("unexpected incremental reports %v", nameNode.incremental)
+	}
+}
@@ -0,0 +1,165 @@
+package datanode
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	gRPC "github.com/aarrasseayoub01/namenode/datanode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// commandTracker remembers the NameNode commands that are running or whose
+// outcome has not been reported yet, so a command delivered twice only runs once
+type commandTracker struct {
+	mu sync.Mutex
+	// active holds the IDs of commands not yet acknowledged to the NameNode
+	active map[int64]bool
+	// acks are the outcomes to report in the next heartbeat
+	acks []*protobuf.CommandAck
+}
+
+func newCommandTracker() *commandTracker {
+	return &commandTracker{active: make(map[int64]bool)}
+}
+
+// start reports whether the command should run, i.e. it is not already
+// running or waiting for its ack to be sent
+func (t *commandTracker) start(commandID int64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.active[commandID] {
+		return false
+	}
+	t.active[commandID] = true
+	return true
+}
+
+// blockFailures is the error of a command that failed for some of its
+// blocks only
+type blockFailures struct {
+	blockIDs []string
+}
+
+func (e *blockFailures) Error() string {
+	return fmt.Sprintf("failed on blocks %v", e.blockIDs)
+}
+
+// finish records the outcome of a command for the next heartbeat
+func (t *commandTracker) finish(commandID int64, err error) {
+	ack := &protobuf.CommandAck{CommandId: commandID, Success: err == nil}
+	if err != nil {
+		ack.Error = err.Error()
+	}
+	var failures *blockFailures
+	if errors.As(err, &failures) {
+		ack.FailedBlockIds = failures.blockIDs
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.acks = append(t.acks, ack)
+}
+
+// takeAcks returns the outcomes not yet reported
+func (t *commandTracker) takeAcks() []*protobuf.CommandAck {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	acks := t.acks
+	t.acks = nil
+	return acks
+}
+
+// reported forgets commands whose outcome reached the NameNode, or puts the
+// acks back to be sent again if the heartbeat failed
+func (t *commandTracker) reported(acks []*protobuf.CommandAck, delivered bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !delivered {
+		t.acks = append(acks, t.acks...)
+		return
+	}
+	for _, ack := range acks {
+		delete(t.active, ack.GetCommandId())
+	}
+}
+
+// executeCommands runs the commands received in a heartbeat response.
+// Block commands run in the background and are acknowledged in a later
+// heartbeat.
+func (dn *DataNode) executeCommands(client *gRPC.DataNodeClient, commands []*protobuf.DataNodeCommand) {
+	for _, command := range commands {
+		if command.GetType() == protobuf.DataNodeCommand_REREGISTER {
+			// Re-registering is answered by the NameNode directly and carries
+			// no command ID to acknowledge
+			log.Printf("NameNode asked us to re-register")
+			if err := client.RegisterWithNameNode(dn.address); err != nil {
+				log.Printf("Failed to re-register with NameNode: %v", err)
+				continue
+			}
+			// The NameNode knows nothing of our blocks yet
+			dn.requestBlockReport()
+			continue
+		}
+
+		if !dn.commands.start(command.GetCommandId()) {
+			continue
+		}
+		if command.GetType() == protobuf.DataNodeCommand_SHUTDOWN {
+			// Acknowledge first: the ack goes out with the final heartbeat
+			// sent while shutting down
+			log.Printf("NameNode asked us to shut down")
+			dn.commands.finish(command.GetCommandId(), nil)
+			dn.Shutdown()
+			continue
+		}
+		log.Printf("Running command %d (%s)", command.GetCommandId(), command.GetType())
+		go func(command *protobuf.DataNodeCommand) {
+			err := dn.runCommand(command)
+			if err != nil {
+				log.Printf("Command %d (%s) failed: %v", command.GetCommandId(), command.GetType(), err)
+			}
+			dn.commands.finish(command.GetCommandId(), err)
+		}(command)
+	}
+}
+
+// runCommand carries out a single NameNode command
+func (dn *DataNode) runCommand(command *protobuf.DataNodeCommand) error {
+	switch command.GetType() {
+	case protobuf.DataNodeCommand_DELETE_BLOCKS:
+		var failed []string
+		for _, blockID := range command.GetBlockIds() {
+			if err := dn.dataManager.DeleteBlock(blockID); err != nil {
+				log.Printf("Failed to delete block %s: %v", blockID, err)
+				failed = append(failed, blockID)
+				continue
+			}
+			log.Printf("Deleted block %s", blockID)
+		}
+		// The other blocks are deleted, so only these are asked for again
+		if len(failed) > 0 {
+			return &blockFailures{blockIDs: failed}
+		}
+		return nil
+
+	case protobuf.DataNodeCommand_REPLICATE_BLOCK:
+		if len(command.GetBlockIds()) != 1 {
+			return fmt.Errorf("expected exactly one block to replicate, got %d", len(command.GetBlockIds()))
+		}
+		blockID := command.GetBlockIds()[0]
+		acked, err := gRPC.ReplicateBlock(dn.dataManager, blockID, command.GetTargets())
+		if err != nil {
+			return err
+		}
+		log.Printf("Replicated block %s to %v", blockID, acked)
+		if len(acked) < len(command.GetTargets()) {
+			return fmt.Errorf("block %s only reached %v of %v", blockID, acked, command.GetTargets())
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported command type %s", command.GetType())
+	}
+}
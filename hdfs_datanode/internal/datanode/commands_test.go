@@ -0,0 +1,144 @@
+package datanode
+
+import (
+	"net"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	datamgmt "github.com/aarrasseayoub01/namenode/datanode/internal/datamngnt"
+	gRPC "github.com/aarrasseayoub01/namenode/datanode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// newTestDataNode returns a DataNode storing its blocks in a temporary
+// directory, without starting any of its servers
+func newTestDataNode(t *testing.T) *DataNode {
+	return &DataNode{
+		dataManager: datamgmt.NewDataManager(t.TempDir()),
+		commands:    newCommandTracker(),
+		fullReport:  make(chan struct{}, 1),
+		shutdown:    make(chan struct{}),
+	}
+}
+
+// serveDataNode serves the blocks of dn on a local port and returns its address
+func serveDataNode(t *testing.T, dn *DataNode) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dn.address = listener.Addr().String()
+	server := grpc.NewServer()
+	protobuf.RegisterDataNodeServiceServer(server, gRPC.NewDataNodeServer(dn.dataManager, dn.address))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return dn.address
+}
+
+// waitForAcks runs commands on dn and returns their acks once all arrived
+func waitForAcks(t *testing.T, dn *DataNode, commands ...*protobuf.DataNodeCommand) []*protobuf.CommandAck {
+	dn.executeCommands(nil, commands)
+	var acks []*protobuf.CommandAck
+	deadline := time.Now().Add(10 * time.Second)
+	for len(acks) < len(commands) {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d acks for %d commands", len(acks), len(commands))
+		}
+		acks = append(acks, dn.commands.takeAcks()...)
+		time.Sleep(10 * time.Millisecond)
+	}
+	return acks
+}
+
+func TestDeleteBlocksCommand(t *testing.T) {
+	dn := newTestDataNode(t)
+	for _, blockID := range []string{"blk_1", "blk_2", "blk_3"} {
+		if err := dn.dataManager.StoreBlock(blockID, []byte(blockID)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	acks := waitForAcks(t, dn, &protobuf.DataNodeCommand{
+		CommandId: 7,
+		Type:      protobuf.DataNodeCommand_DELETE_BLOCKS,
+		BlockIds:  []string{"blk_1", "blk_missing"},
+	})
+	if acks[0].GetCommandId() != 7 || !acks[0].GetSuccess() {
+		t.Fatalf("unexpected ack %v", acks[0])
+	}
+	blocks, err := dn.dataManager.ListBlocks()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 || blocks[0].ID != "blk_2" || blocks[1].ID != "blk_3" {
+		t.Fatalf("unexpected blocks left %v", blocks)
+	}
+
+	// A block that fails is reported alone, the others are still deleted
+	acks = waitForAcks(t, dn, &protobuf.DataNodeCommand{
+		CommandId: 8,
+		Type:      protobuf.DataNodeCommand_DELETE_BLOCKS,
+		BlockIds:  []string{"blk_2", "../blk_3"},
+	})
+	if acks[0].GetSuccess() || !slices.Equal(acks[0].GetFailedBlockIds(), []string{"../blk_3"}) {
+		t.Fatalf("unexpected ack %v", acks[0])
+	}
+	if blocks, _ := dn.dataManager.ListBlocks(); len(blocks) != 1 || blocks[0].ID != "blk_3" {
+		t.Fatalf("unexpected blocks left %v", blocks)
+	}
+}
+
+func TestDuplicateCommandsRunOnce(t *testing.T) {
+	dn := newTestDataNode(t)
+	command := &protobuf.DataNodeCommand{CommandId: 3, Type: protobuf.DataNodeCommand_DELETE_BLOCKS, BlockIds: []string{"blk_1"}}
+
+	acks := waitForAcks(t, dn, command)
+	dn.executeCommands(nil, []*protobuf.DataNodeCommand{command})
+	time.Sleep(50 * time.Millisecond)
+	if more := dn.commands.takeAcks(); len(acks) != 1 || len(more) != 0 {
+		t.Fatalf("command ran %d times", len(acks)+len(more))
+	}
+
+	// Once the ack reached the NameNode the command ID may be used again
+	dn.commands.reported(acks, true)
+	if acks := waitForAcks(t, dn, command); len(acks) != 1 {
+		t.Fatalf("command ran %d times", len(acks))
+	}
+}
+
+func TestReplicateBlockCommand(t *testing.T) {
+	source, target := newTestDataNode(t), newTestDataNode(t)
+	serveDataNode(t, source)
+	targetAddress := serveDataNode(t, target)
+	data := []byte("replicated block")
+	if err := source.dataManager.StoreBlock("blk_1", data); err != nil {
+		t.Fatal(err)
+	}
+
+	acks := waitForAcks(t, source, &protobuf.DataNodeCommand{
+		CommandId: 1,
+		Type:      protobuf.DataNodeCommand_REPLICATE_BLOCK,
+		BlockIds:  []string{"blk_1"},
+		Targets:   []string{targetAddress},
+	})
+	if !acks[0].GetSuccess() {
+		t.Fatalf("replication failed: %s", acks[0].GetError())
+	}
+	if got, err := target.dataManager.RetrieveBlock("blk_1"); err != nil || string(got) != string(data) {
+		t.Fatalf("target holds %q, %v", got, err)
+	}
+
+	// A block that is not stored can't be replicated
+	acks = waitForAcks(t, source, &protobuf.DataNodeCommand{
+		CommandId: 2,
+		Type:      protobuf.DataNodeCommand_REPLICATE_BLOCK,
+		BlockIds:  []string{"blk_missing"},
+		Targets:   []string{targetAddress},
+	})
+	if acks[0].GetSuccess() {
+		t.Fatal("replicating a missing block succeeded")
+	}
+}
@@ -1,9 +1,11 @@
 package datanode
 
 import (
+	"context"
 	"log"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc"
@@ -11,7 +13,6 @@ import (
 	"github.com/aarrasseayoub01/namenode/datanode/internal/config"
 	ctrl "github.com/aarrasseayoub01/namenode/datanode/internal/controller"
 	datamgmt "github.com/aarrasseayoub01/namenode/datanode/internal/datamngnt"
-	mng "github.com/aarrasseayoub01/namenode/datanode/internal/datamngnt" // Replace with your actual project path
 	gRPC "github.com/aarrasseayoub01/namenode/datanode/internal/gRPC"
 	"github.com/aarrasseayoub01/namenode/protobuf"
 	"github.com/gorilla/mux"
@@ -20,92 +21,172 @@ import (
 type DataNode struct {
 	config      *config.Config
 	dataManager *datamgmt.DataManager
-
-	// Add other fields as needed
+	// address is where this DataNode's gRPC server is reachable by others
+	address string
+
+	// commands tracks the NameNode commands that are not acknowledged yet
+	commands *commandTracker
+	// fullReport requests a full block report
+	fullReport chan struct{}
+
+	httpServer *http.Server
+	grpcServer *grpc.Server
+	// shutdown is closed when the DataNode is asked to stop
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+	// stopped is closed once the servers are stopped
+	stopped chan struct{}
 }
 
 func NewDataNode(cfg *config.Config) (*DataNode, error) {
-	dm := datamgmt.NewDataManager("./") // Adjust the path as needed
+	dm := datamgmt.NewDataManager(cfg.BaseDir)
 
 	dn := &DataNode{
 		config:      cfg,
 		dataManager: dm,
+		address:     gRPC.LocalAddress(cfg.GRPCPort),
+		commands:    newCommandTracker(),
+		fullReport:  make(chan struct{}, 1),
+		grpcServer:  grpc.NewServer(),
+		shutdown:    make(chan struct{}),
+		stopped:     make(chan struct{}),
 	}
 	// Additional initialization here
 	return dn, nil
 }
 
 func (dn *DataNode) Start() error {
-
-	// Start the DataNode functionality
-	go dn.startGRPCclient()
-
-	go startGRPCserver()
-
 	r := mux.NewRouter()
 
-	// Initialize DataManager
-	dataManager := mng.NewDataManager("./") // Set the base directory path
-
 	// Create a new Controller instance
-	controller := ctrl.NewController(dataManager)
+	controller := ctrl.NewController(dn.dataManager)
 	// Define the routes
 	r.HandleFunc("/addBlock", controller.AddBlock).Methods("POST")
 	r.HandleFunc("/getBlock/{blockId}", controller.GetBlock).Methods("GET") // New route
 
+	dn.httpServer = &http.Server{Addr: ":" + dn.config.HTTPPort, Handler: r}
+
+	// Start the DataNode functionality
+	go dn.startGRPCclient()
+
+	go dn.startGRPCserver()
+
 	// Start the server
-	log.Println("Starting DataNode server on :8081")
-	log.Fatal(http.ListenAndServe(":8081", r))
+	go func() {
+		log.Printf("Starting DataNode server on :%s", dn.config.HTTPPort)
+		if err := dn.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+	}()
 
-	// Add more startup logic here
+	// Run until the NameNode or Shutdown asks us to stop
+	<-dn.stopped
+	log.Printf("DataNode stopped")
 	return nil
 }
 
+// Shutdown asks the DataNode to stop. A final heartbeat reports the
+// outstanding command acks before the servers are stopped.
+func (dn *DataNode) Shutdown() {
+	dn.shutdownOnce.Do(func() {
+		close(dn.shutdown)
+	})
+}
+
+// stopServers gracefully stops the HTTP and gRPC servers, letting in-flight
+// transfers finish
+func (dn *DataNode) stopServers() {
+	log.Printf("Stopping DataNode servers")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := dn.httpServer.Shutdown(ctx); err != nil {
+		log.Printf("Error stopping HTTP server: %v", err)
+	}
+	dn.grpcServer.GracefulStop()
+	close(dn.stopped)
+}
+
 func (dn *DataNode) startGRPCclient() error {
-	nameNodeAddress := "localhost:50051" // Adjust the address of the NameNode
-	client, err := gRPC.NewDataNodeClient(nameNodeAddress)
+	client, err := gRPC.NewDataNodeClient(dn.config.NameNodeAddress)
 	if err != nil {
 		log.Fatalf("Failed to create DataNode client: %v", err)
 		return err
 	}
 	defer client.Close()
 
-	dataNodeID, err := client.RegisterWithNameNode()
-	if err != nil {
+	if err := client.RegisterWithNameNode(dn.address); err != nil {
 		log.Fatalf("Failed to register with NameNode: %v", err)
 	}
 
+	go dn.reportBlocks(dn.address)
+
 	go func(address string) {
-		ticker := time.NewTicker(30 * time.Second) // Adjust the interval as needed
-		nameNodeAddress := "localhost:50051"       // Adjust the address of the NameNode
-		client, err := gRPC.NewDataNodeClient(nameNodeAddress)
+		ticker := time.NewTicker(dn.config.HeartbeatInterval)
+		defer ticker.Stop()
+		client, err := gRPC.NewDataNodeClient(dn.config.NameNodeAddress)
 		if err != nil {
 			log.Fatalf("Failed to create DataNode client: %v", err)
 		}
 		defer client.Close()
 
-		for range ticker.C {
-			err := client.SendHeartbeat(address) // Use a unique identifier for the DataNode
-			if err != nil {
-				log.Printf("Error sending heartbeat: %v", err)
-				// Handle error, maybe with a retry mechanism
+		// Report right away so the NameNode knows our capacity before placing blocks
+		dn.sendHeartbeat(client, address)
+		for {
+			select {
+			case <-ticker.C:
+				dn.sendHeartbeat(client, address)
+			case <-dn.shutdown:
+				// Let the NameNode know which commands completed before going away
+				dn.sendHeartbeat(client, address)
+				dn.stopServers()
+				return
 			}
 		}
-	}(dataNodeID)
+	}(dn.address)
 
 	return nil
 }
 
-func startGRPCserver() {
-	lis, err := net.Listen("tcp", ":50052")
+// sendHeartbeat reports the current storage statistics and command acks to
+// the NameNode and runs the commands it sends back
+func (dn *DataNode) sendHeartbeat(client *gRPC.DataNodeClient, address string) {
+	stats, err := dn.dataManager.Stats()
+	if err != nil {
+		log.Printf("Error collecting storage statistics: %v", err)
+		stats = &datamgmt.StorageStats{VolumeFailures: 1}
+	}
+
+	acks := dn.commands.takeAcks()
+	commands, err := client.SendHeartbeat(address, stats, acks) // Use a unique identifier for the DataNode
+	dn.commands.reported(acks, err == nil)
+	if err != nil {
+		log.Printf("Error sending heartbeat: %v", err)
+		// The acks are sent again with the next heartbeat
+		return
+	}
+
+	select {
+	case <-dn.shutdown:
+		// Don't start new work while shutting down
+	default:
+		dn.executeCommands(client, commands)
+	}
+}
+
+const (
+	// shutdownTimeout bounds how long in-flight HTTP requests may delay a shutdown
+	shutdownTimeout = 30 * time.Second
+)
+
+func (dn *DataNode) startGRPCserver() {
+	lis, err := net.Listen("tcp", ":"+dn.config.GRPCPort)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
-	protobuf.RegisterNameNodeServiceServer(grpcServer, gRPC.NewDataNodeServer())
-	log.Println("Starting gRPC server on :50052")
-	if err := grpcServer.Serve(lis); err != nil {
+	protobuf.RegisterDataNodeServiceServer(dn.grpcServer, gRPC.NewDataNodeServer(dn.dataManager, dn.address))
+	log.Printf("Starting gRPC server on :%s", dn.config.GRPCPort)
+	if err := dn.grpcServer.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %s", err)
 	}
 }
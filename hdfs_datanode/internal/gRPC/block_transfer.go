@@ -0,0 +1,141 @@
+package gRPC
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// WriteBlock receives a block as a stream of packets, writes it to disk as
+// the packets arrive and forwards it to the rest of the write pipeline
+func (s *DataNodeServer) WriteBlock(stream protobuf.DataNodeService_WriteBlockServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	header := first.GetHeader()
+	if header == nil {
+		return status.Error(codes.InvalidArgument, "first message must carry the block header")
+	}
+	blockID := header.GetBlockId()
+
+	writer, err := s.dataManager.CreateBlock(blockID)
+	if err != nil {
+		log.Printf("Failed to create block %s: %v", blockID, err)
+		return toStatusError(err)
+	}
+
+	acks, err := s.forwardPackets(stream, blockID, header.GetTargets(), writer)
+	if err != nil {
+		writer.Abort()
+		log.Printf("Failed to receive block %s: %v", blockID, err)
+		return err
+	}
+
+	if err := writer.Commit(); err != nil {
+		log.Printf("Failed to commit block %s: %v", blockID, err)
+		return toStatusError(err)
+	}
+
+	log.Printf("Stored block %s (%d bytes)", blockID, writer.Size())
+	return stream.SendAndClose(&protobuf.WriteBlockResponse{
+		Success:      true,
+		BytesWritten: writer.Size(),
+		AckedNodes:   acks.acked,
+		FailedNodes:  acks.failed,
+	})
+}
+
+// receivePackets hands every packet of stream to handle until the last one,
+// validating their order and checksums
+func receivePackets(stream protobuf.DataNodeService_WriteBlockServer, handle func(*protobuf.BlockPacket) error) error {
+	var seqno, offset int64
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return status.Error(codes.InvalidArgument, "stream ended before the last packet")
+		}
+		if err != nil {
+			return err
+		}
+
+		packet := req.GetPacket()
+		if packet == nil {
+			return status.Error(codes.InvalidArgument, "expected a block packet")
+		}
+		if packet.GetSeqno() != seqno || packet.GetOffset() != offset {
+			return status.Errorf(codes.InvalidArgument, "out of order packet %d at offset %d, expected %d at offset %d",
+				packet.GetSeqno(), packet.GetOffset(), seqno, offset)
+		}
+		if protobuf.PacketChecksum(packet.GetData()) != packet.GetChecksum() {
+			return status.Errorf(codes.DataLoss, "checksum mismatch in packet %d", seqno)
+		}
+
+		if err := handle(packet); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		if packet.GetLastPacket() {
+			return nil
+		}
+		seqno++
+		offset += int64(len(packet.GetData()))
+	}
+}
+
+// ReadBlock streams a stored block to the caller in packets
+func (s *DataNodeServer) ReadBlock(req *protobuf.ReadBlockRequest, stream protobuf.DataNodeService_ReadBlockServer) error {
+	blockID := req.GetBlockId()
+	reader, err := s.dataManager.OpenBlock(blockID, req.GetOffset())
+	if err != nil {
+		log.Printf("Failed to open block %s: %v", blockID, err)
+		return toStatusError(err)
+	}
+	defer reader.Close()
+
+	err = sendPackets(reader, req.GetOffset(), func(packet *protobuf.BlockPacket) error {
+		return stream.Send(&protobuf.ReadBlockResponse{Packet: packet})
+	})
+	if err != nil {
+		log.Printf("Failed to send block %s: %v", blockID, err)
+		return toStatusError(fmt.Errorf("failed to send block %s: %w", blockID, err))
+	}
+	return nil
+}
+
+// sendPackets splits r into checksummed packets, numbered from offset, and
+// hands them to send until r is exhausted
+func sendPackets(r io.Reader, offset int64, send func(*protobuf.BlockPacket) error) error {
+	buf := make([]byte, protobuf.PacketSize)
+	seqno := int64(0)
+	for {
+		n, err := io.ReadFull(r, buf)
+		last := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !last {
+			return err
+		}
+
+		packet := &protobuf.BlockPacket{
+			Seqno:      seqno,
+			Offset:     offset,
+			Data:       buf[:n],
+			Checksum:   protobuf.PacketChecksum(buf[:n]),
+			LastPacket: last,
+		}
+		if err := send(packet); err != nil {
+			return err
+		}
+
+		if last {
+			return nil
+		}
+		seqno++
+		offset += int64(n)
+	}
+}
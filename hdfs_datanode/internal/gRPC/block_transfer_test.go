@@ -0,0 +1,217 @@
+package gRPC
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	datamgmt "github.com/aarrasseayoub01/namenode/datanode/internal/datamngnt"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// testDataNode is a DataNodeServer served on a local port
+type testDataNode struct {
+	dir         string
+	dataManager *datamgmt.DataManager
+	address     string
+	client      protobuf.DataNodeServiceClient
+}
+
+// startDataNode serves a DataNodeServer storing its blocks in a temporary
+// directory
+func startDataNode(t *testing.T) *testDataNode {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	node := &testDataNode{
+		dir:         dir,
+		dataManager: datamgmt.NewDataManager(dir),
+		address:     listener.Addr().String(),
+	}
+	server := grpc.NewServer()
+	protobuf.RegisterDataNodeServiceServer(server, NewDataNodeServer(node.dataManager, node.address))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(node.address, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	node.client = protobuf.NewDataNodeServiceClient(conn)
+	return node
+}
+
+// testPackets splits data into packets of size bytes
+func testPackets(data []byte, size int) []*protobuf.BlockPacket {
+	var packets []*protobuf.BlockPacket
+	for offset := 0; ; offset += size {
+		end := min(offset+size, len(data))
+		packets = append(packets, &protobuf.BlockPacket{
+			Seqno:      int64(len(packets)),
+			Offset:     int64(offset),
+			Data:       data[offset:end],
+			Checksum:   protobuf.PacketChecksum(data[offset:end]),
+			LastPacket: end == len(data),
+		})
+		if end == len(data) {
+			return packets
+		}
+	}
+}
+
+// writeBlock sends packets as blockID to node, with targets to forward to
+func writeBlock(node *testDataNode, blockID string, targets []string, packets []*protobuf.BlockPacket) (*protobuf.WriteBlockResponse, error) {
+	stream, err := node.client.WriteBlock(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	header := &protobuf.WriteBlockHeader{BlockId: blockID, Targets: targets}
+	if err := stream.Send(&protobuf.WriteBlockRequest{Payload: &protobuf.WriteBlockRequest_Header{Header: header}}); err != nil {
+		return nil, err
+	}
+	for _, packet := range packets {
+		if err := stream.Send(&protobuf.WriteBlockRequest{Payload: &protobuf.WriteBlockRequest_Packet{Packet: packet}}); err != nil {
+			break
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+// readBlock reads blockID from node starting at offset, returning the data
+// received before any error
+func readBlock(node *testDataNode, blockID string, offset int64) ([]byte, error) {
+	stream, err := node.client.ReadBlock(context.Background(), &protobuf.ReadBlockRequest{BlockId: blockID, Offset: offset})
+	if err != nil {
+		return nil, err
+	}
+	var data bytes.Buffer
+	for {
+		response, err := stream.Recv()
+		if err != nil {
+			return data.Bytes(), err
+		}
+		packet := response.GetPacket()
+		if packet.GetOffset() != offset+int64(data.Len()) || protobuf.PacketChecksum(packet.GetData()) != packet.GetChecksum() {
+			return data.Bytes(), errors.New("bad packet")
+		}
+		data.Write(packet.GetData())
+		if packet.GetLastPacket() {
+			return data.Bytes(), nil
+		}
+	}
+}
+
+func testData(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i * 13)
+	}
+	return data
+}
+
+func TestWriteAndReadBlock(t *testing.T) {
+	node := startDataNode(t)
+	data := testData(3*protobuf.PacketSize + 17)
+
+	response, err := writeBlock(node, "blk_1", nil, testPackets(data, protobuf.PacketSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.GetBytesWritten() != int64(len(data)) || len(response.GetAckedNodes()) != 1 || response.GetAckedNodes()[0] != node.address {
+		t.Fatalf("unexpected response %v", response)
+	}
+
+	for _, offset := range []int64{0, protobuf.PacketSize + 3} {
+		got, err := readBlock(node, "blk_1", offset)
+		if err != nil || !bytes.Equal(got, data[offset:]) {
+			t.Fatalf("read from %d returned %d bytes, %v", offset, len(got), err)
+		}
+	}
+
+	// Empty blocks are a single empty packet
+	if _, err := writeBlock(node, "blk_empty", nil, testPackets(nil, protobuf.PacketSize)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readBlock(node, "blk_empty", 0); err != nil || len(got) != 0 {
+		t.Fatalf("read returned %d bytes, %v", len(got), err)
+	}
+
+	_, err = readBlock(node, "blk_missing", 0)
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+}
+
+func TestWriteBlockRejectsBadPackets(t *testing.T) {
+	node := startDataNode(t)
+	data := testData(100)
+
+	tests := []struct {
+		name    string
+		corrupt func([]*protobuf.BlockPacket)
+		code    codes.Code
+	}{
+		{"skipped seqno", func(p []*protobuf.BlockPacket) { p[1].Seqno = 2 }, codes.InvalidArgument},
+		{"wrong offset", func(p []*protobuf.BlockPacket) { p[2].Offset += 5 }, codes.InvalidArgument},
+		{"reordered", func(p []*protobuf.BlockPacket) { p[1], p[2] = p[2], p[1] }, codes.InvalidArgument},
+		{"bad checksum", func(p []*protobuf.BlockPacket) { p[1].Checksum++ }, codes.DataLoss},
+		{"zeroed data", func(p []*protobuf.BlockPacket) { p[3].Data = make([]byte, 20) }, codes.DataLoss},
+		{"no last packet", func(p []*protobuf.BlockPacket) { p[len(p)-1].LastPacket = false }, codes.InvalidArgument},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			packets := testPackets(data, 20)
+			test.corrupt(packets)
+			_, err := writeBlock(node, "blk_bad", nil, packets)
+			if status.Code(err) != test.code {
+				t.Fatalf("expected %v, got %v", test.code, err)
+			}
+
+			// Nothing of the rejected block is stored
+			if _, err := readBlock(node, "blk_bad", 0); status.Code(err) != codes.NotFound {
+				t.Fatalf("expected NotFound, got %v", err)
+			}
+			if entries, _ := os.ReadDir(filepath.Join(node.dir, "tmp")); len(entries) != 0 {
+				t.Fatalf("temporary files left behind: %v", entries)
+			}
+		})
+	}
+}
+
+func TestReadBlockStopsBeforeCorruptData(t *testing.T) {
+	node := startDataNode(t)
+	data := testData(3 * protobuf.PacketSize)
+	if _, err := writeBlock(node, "blk_corrupt", nil, testPackets(data, protobuf.PacketSize)); err != nil {
+		t.Fatal(err)
+	}
+
+	blockPath := filepath.Join(node.dir, "data", "block_blk_corrupt")
+	file, err := os.OpenFile(blockPath, os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.WriteAt([]byte{^data[2*protobuf.PacketSize]}, 2*protobuf.PacketSize); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	// The packets before the corrupt one arrive intact, then the read fails
+	got, err := readBlock(node, "blk_corrupt", 0)
+	if status.Code(err) != codes.DataLoss {
+		t.Fatalf("expected DataLoss, got %v", err)
+	}
+	if !bytes.Equal(got, data[:2*protobuf.PacketSize]) {
+		t.Fatalf("received %d bytes, expected the %d before the corrupt packet", len(got), 2*protobuf.PacketSize)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net"
 	"time"
 
+	datamgmt "github.com/aarrasseayoub01/namenode/datanode/internal/datamngnt"
 	"github.com/aarrasseayoub01/namenode/protobuf" // Adjust this import path to where your protobuf definitions are.
 
 	"google.golang.org/grpc"
@@ -27,30 +28,87 @@ func NewDataNodeClient(nameNodeAddress string) (*DataNodeClient, error) {
 	return &DataNodeClient{conn: conn, client: client}, nil
 }
 
-func (c *DataNodeClient) RegisterWithNameNode() (string, error) {
+// RegisterWithNameNode registers the DataNode under the address its
+// DataNodeService is reachable on
+func (c *DataNodeClient) RegisterWithNameNode(dataNodeAddress string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
-	dataNodeAddress := getLocalIPAddress()
 
 	response, err := c.client.RegisterDataNode(ctx, &protobuf.RegisterDataNodeRequest{DatanodeAddress: dataNodeAddress})
 	if err != nil {
-		return "", err
+		return err
 	}
 	log.Printf("Registered with NameNode, assigned ID: %s", response.GetDatanodeId())
-	return dataNodeAddress, nil
+	return nil
 }
 
-func (c *DataNodeClient) SendHeartbeat(datanodeAddress string) error {
+// SendHeartbeat reports the DataNode's liveness and storage statistics along
+// with the outcome of finished commands, and returns the new commands the
+// NameNode has for the DataNode
+func (c *DataNodeClient) SendHeartbeat(datanodeAddress string, stats *datamgmt.StorageStats, acks []*protobuf.CommandAck) ([]*protobuf.DataNodeCommand, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	defer cancel()
 
 	// Sending a HeartbeatRequest to the NameNode
-	response, err := c.client.SendHeartbeat(ctx, &protobuf.HeartbeatRequest{DatanodeAddress: datanodeAddress})
+	response, err := c.client.SendHeartbeat(ctx, &protobuf.HeartbeatRequest{
+		DatanodeAddress: datanodeAddress,
+		CapacityBytes:   stats.CapacityBytes,
+		DfsUsedBytes:    stats.DfsUsedBytes,
+		RemainingBytes:  stats.RemainingBytes,
+		BlockCount:      stats.BlockCount,
+		ActiveTransfers: stats.ActiveTransfers,
+		VolumeFailures:  stats.VolumeFailures,
+		CommandAcks:     acks,
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	log.Printf("Heartbeat response from NameNode: %v", response.GetSuccess())
-	return nil
+	return response.GetCommands(), nil
+}
+
+// blockReportTimeout bounds a block report, which may list many blocks
+const blockReportTimeout = 30 * time.Second
+
+// SendBlockReport lists every block stored on the DataNode. It returns false
+// if the NameNode does not know the DataNode.
+func (c *DataNodeClient) SendBlockReport(datanodeAddress string, blocks []datamgmt.StoredBlock) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), blockReportTimeout)
+	defer cancel()
+
+	response, err := c.client.BlockReport(ctx, &protobuf.BlockReportRequest{
+		DatanodeAddress: datanodeAddress,
+		Blocks:          toReportedBlocks(blocks),
+	})
+	if err != nil {
+		return false, err
+	}
+	return response.GetSuccess(), nil
+}
+
+// SendIncrementalBlockReport reports the blocks stored and deleted since the
+// last report. It returns false if the NameNode does not know the DataNode.
+func (c *DataNodeClient) SendIncrementalBlockReport(datanodeAddress string, received []datamgmt.StoredBlock, deleted []string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), blockReportTimeout)
+	defer cancel()
+
+	response, err := c.client.BlockReceivedAndDeleted(ctx, &protobuf.IncrementalBlockReportRequest{
+		DatanodeAddress: datanodeAddress,
+		Received:        toReportedBlocks(received),
+		Deleted:         deleted,
+	})
+	if err != nil {
+		return false, err
+	}
+	return response.GetSuccess(), nil
+}
+
+func toReportedBlocks(blocks []datamgmt.StoredBlock) []*protobuf.ReportedBlock {
+	reported := make([]*protobuf.ReportedBlock, 0, len(blocks))
+	for _, block := range blocks {
+		reported = append(reported, &protobuf.ReportedBlock{BlockId: block.ID, Size: block.Size})
+	}
+	return reported
 }
 
 // Close closes the client connection
@@ -58,6 +116,12 @@ func (c *DataNodeClient) Close() {
 	c.conn.Close()
 }
 
+// LocalAddress returns the address other nodes use to reach the gRPC server
+// listening on grpcPort
+func LocalAddress(grpcPort string) string {
+	return net.JoinHostPort(getLocalIPAddress(), grpcPort)
+}
+
 func getLocalIPAddress() string {
 	addrs, err := net.InterfaceAddrs()
 	if err != nil {
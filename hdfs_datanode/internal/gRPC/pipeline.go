@@ -0,0 +1,270 @@
+package gRPC
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+
+	datamgmt "github.com/aarrasseayoub01/namenode/datanode/internal/datamngnt"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+const (
+	// pipelineDialTimeout bounds how long we wait for the next DataNode in a pipeline
+	pipelineDialTimeout = 5 * time.Second
+	// pipelineTimeout bounds forwarding a whole block downstream
+	pipelineTimeout = 2 * time.Minute
+)
+
+// pipelineForwarder relays a block to the next DataNode of a write pipeline.
+// The next DataNode receives the rest of the targets and forwards in turn.
+type pipelineForwarder struct {
+	conn    *grpc.ClientConn
+	stream  protobuf.DataNodeService_WriteBlockClient
+	cancel  context.CancelFunc
+	targets []string
+}
+
+// openPipeline connects to targets[0] and sends it the block header
+func openPipeline(blockID string, targets []string) (*pipelineForwarder, error) {
+	dialCtx, dialCancel := context.WithTimeout(context.Background(), pipelineDialTimeout)
+	defer dialCancel()
+
+	conn, err := grpc.DialContext(dialCtx, targets[0], grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineTimeout)
+	stream, err := protobuf.NewDataNodeServiceClient(conn).WriteBlock(ctx)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return nil, err
+	}
+
+	forwarder := &pipelineForwarder{conn: conn, stream: stream, cancel: cancel, targets: targets}
+	header := &protobuf.WriteBlockHeader{BlockId: blockID, Targets: targets[1:]}
+	if err := stream.Send(&protobuf.WriteBlockRequest{Payload: &protobuf.WriteBlockRequest_Header{Header: header}}); err != nil {
+		forwarder.abort()
+		return nil, err
+	}
+
+	return forwarder, nil
+}
+
+// send forwards a packet downstream
+func (f *pipelineForwarder) send(packet *protobuf.BlockPacket) error {
+	return f.stream.Send(&protobuf.WriteBlockRequest{Payload: &protobuf.WriteBlockRequest_Packet{Packet: packet}})
+}
+
+// finish waits for the downstream acks
+func (f *pipelineForwarder) finish() (*protobuf.WriteBlockResponse, error) {
+	defer f.abort()
+	return f.stream.CloseAndRecv()
+}
+
+// abort tears down the connection to the next DataNode
+func (f *pipelineForwarder) abort() {
+	f.cancel()
+	f.conn.Close()
+}
+
+// pipelineAcks collects the acks of a pipeline write
+type pipelineAcks struct {
+	acked  []string
+	failed []string
+}
+
+// downstreamPipeline relays a block to the rest of a write pipeline. When the
+// next DataNode fails, only it is reported failed: the pipeline is reopened on
+// the DataNodes after it and the block received so far replayed to them from
+// the local copy.
+type downstreamPipeline struct {
+	blockID   string
+	targets   []string
+	forwarder *pipelineForwarder
+	// local holds the block data received so far
+	local io.ReaderAt
+	// seqno and offset of the next packet to forward
+	seqno  int64
+	offset int64
+	failed []string
+}
+
+// open connects to the first reachable DataNode of the targets
+func (d *downstreamPipeline) open() {
+	for len(d.targets) > 0 {
+		forwarder, err := openPipeline(d.blockID, d.targets)
+		if err == nil {
+			d.forwarder = forwarder
+			d.seqno, d.offset = 0, 0
+			return
+		}
+		d.fail(err)
+	}
+}
+
+// fail reports the next DataNode failed and drops it from the pipeline
+func (d *downstreamPipeline) fail(err error) {
+	logPipelineFailure(d.blockID, d.targets[:1], err)
+	d.failed = append(d.failed, d.targets[0])
+	d.targets = d.targets[1:]
+	if d.forwarder != nil {
+		d.forwarder.abort()
+		d.forwarder = nil
+	}
+}
+
+// send forwards data as the next packet, with its checksum
+func (d *downstreamPipeline) send(data []byte, checksum uint32, last bool) error {
+	err := d.forwarder.send(&protobuf.BlockPacket{
+		Seqno:      d.seqno,
+		Offset:     d.offset,
+		Data:       data,
+		Checksum:   checksum,
+		LastPacket: last,
+	})
+	if err == io.EOF {
+		// The DataNode closed the stream; its error is in the response
+		_, err = d.forwarder.finish()
+	}
+	if err != nil {
+		return err
+	}
+	d.seqno++
+	d.offset += int64(len(data))
+	return nil
+}
+
+// forward relays a packet, rebuilding the pipeline as DataNodes fail
+func (d *downstreamPipeline) forward(packet *protobuf.BlockPacket) {
+	for d.forwarder != nil {
+		err := d.send(packet.GetData(), packet.GetChecksum(), packet.GetLastPacket())
+		if err == nil {
+			return
+		}
+		d.rebuild(err, packet.GetOffset(), false)
+	}
+}
+
+// rebuild replaces the next DataNode, which failed with err, and replays the
+// first length bytes of the block to the new pipeline, as the last packets if
+// last is set
+func (d *downstreamPipeline) rebuild(err error, length int64, last bool) {
+	for d.forwarder != nil && err != nil {
+		d.fail(err)
+		d.open()
+		if d.forwarder != nil {
+			err = d.replay(length, last)
+		}
+	}
+}
+
+// replay sends the first length bytes of the local block downstream
+func (d *downstreamPipeline) replay(length int64, last bool) error {
+	buf := make([]byte, protobuf.PacketSize)
+	for d.offset < length || last && d.seqno == 0 {
+		n := min(int64(len(buf)), length-d.offset)
+		if _, err := d.local.ReadAt(buf[:n], d.offset); err != nil {
+			return fmt.Errorf("failed to read the local block: %w", err)
+		}
+		if err := d.send(buf[:n], protobuf.PacketChecksum(buf[:n]), last && d.offset+n == length); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish waits for the downstream acks, rebuilding the pipeline on the
+// remaining DataNodes until one stores the whole block of length bytes
+func (d *downstreamPipeline) finish(length int64) []string {
+	for d.forwarder != nil {
+		response, err := d.forwarder.finish()
+		if err == nil {
+			d.failed = append(d.failed, response.GetFailedNodes()...)
+			return response.GetAckedNodes()
+		}
+		d.rebuild(err, length, true)
+	}
+	return nil
+}
+
+// abort tears down the pipeline
+func (d *downstreamPipeline) abort() {
+	if d.forwarder != nil {
+		d.forwarder.abort()
+	}
+}
+
+// forwardPackets writes every packet to the local block and relays it to the
+// rest of the pipeline. A downstream failure does not fail the local write:
+// the failed DataNodes are reported in the acks, and the block goes on to the
+// ones that are left.
+func (s *DataNodeServer) forwardPackets(stream protobuf.DataNodeService_WriteBlockServer, blockID string, targets []string, w *datamgmt.BlockWriter) (*pipelineAcks, error) {
+	downstream := &downstreamPipeline{blockID: blockID, targets: targets, local: w}
+	downstream.open()
+
+	err := receivePackets(stream, func(packet *protobuf.BlockPacket) error {
+		if _, err := w.Write(packet.GetData()); err != nil {
+			return err
+		}
+		downstream.forward(packet)
+		return nil
+	})
+	if err != nil {
+		downstream.abort()
+		return nil, err
+	}
+
+	acks := &pipelineAcks{acked: []string{s.address}}
+	acks.acked = append(acks.acked, downstream.finish(w.Size())...)
+	acks.failed = downstream.failed
+	return acks, nil
+}
+
+func logPipelineFailure(blockID string, targets []string, err error) {
+	log.Printf("Pipeline for block %s lost downstream DataNodes %v: %v", blockID, targets, err)
+}
+
+// ReplicateBlock copies a locally stored block to targets through a write
+// pipeline, as the NameNode asks when a block is under-replicated, and
+// returns the DataNodes that stored it
+func ReplicateBlock(dm *datamgmt.DataManager, blockID string, targets []string) ([]string, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no target to replicate block %s to", blockID)
+	}
+
+	reader, err := dm.OpenBlock(blockID, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	forwarder, err := openPipeline(blockID, targets)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pipeline to %v: %w", targets, err)
+	}
+	defer forwarder.abort()
+
+	if err := sendPackets(reader, 0, forwarder.send); err != nil {
+		if err == io.EOF {
+			// The DataNode closed the stream; its error is in the response
+			_, err = forwarder.finish()
+		}
+		return nil, fmt.Errorf("failed to replicate block %s: %w", blockID, err)
+	}
+
+	response, err := forwarder.finish()
+	if err != nil {
+		return nil, fmt.Errorf("failed to replicate block %s: %w", blockID, err)
+	}
+	if len(response.GetFailedNodes()) > 0 {
+		logPipelineFailure(blockID, response.GetFailedNodes(), fmt.Errorf("replication was not acknowledged"))
+	}
+	return response.GetAckedNodes(), nil
+}
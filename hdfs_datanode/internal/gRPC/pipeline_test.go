@@ -0,0 +1,111 @@
+package gRPC
+
+import (
+	"bytes"
+	"net"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// failingDataNode fails every WriteBlock after receiving failAfter packets,
+// or once it received the whole block if failAfter is 0
+type failingDataNode struct {
+	protobuf.UnimplementedDataNodeServiceServer
+	failAfter int
+}
+
+func (d *failingDataNode) WriteBlock(stream protobuf.DataNodeService_WriteBlockServer) error {
+	for received := -1; d.failAfter == 0 || received < d.failAfter; received++ {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if req.GetPacket().GetLastPacket() {
+			break
+		}
+	}
+	return status.Error(codes.Internal, "disk failure")
+}
+
+func startFailingDataNode(t *testing.T, failAfter int) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	protobuf.RegisterDataNodeServiceServer(server, &failingDataNode{failAfter: failAfter})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func TestPipelineWrite(t *testing.T) {
+	head, middle, tail := startDataNode(t), startDataNode(t), startDataNode(t)
+	data := testData(2*protobuf.PacketSize + 5)
+
+	response, err := writeBlock(head, "blk_pipeline", []string{middle.address, tail.address}, testPackets(data, protobuf.PacketSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(response.GetAckedNodes(), []string{head.address, middle.address, tail.address}) || len(response.GetFailedNodes()) > 0 {
+		t.Fatalf("unexpected acks %v, failures %v", response.GetAckedNodes(), response.GetFailedNodes())
+	}
+	for _, node := range []*testDataNode{head, middle, tail} {
+		if got, err := readBlock(node, "blk_pipeline", 0); err != nil || !bytes.Equal(got, data) {
+			t.Fatalf("%s returned %d bytes, %v", node.address, len(got), err)
+		}
+	}
+}
+
+func TestPipelineReplaysToRemainingNodes(t *testing.T) {
+	data := testData(3*protobuf.PacketSize + 5)
+
+	tests := []struct {
+		name      string
+		failAfter int
+	}{
+		{"failure mid-block", 2},
+		{"failure on the last ack", 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			head, tail := startDataNode(t), startDataNode(t)
+			failing := startFailingDataNode(t, test.failAfter)
+
+			// Only the DataNode that failed is reported, and the one after it
+			// still receives the whole block
+			response, err := writeBlock(head, "blk_replay", []string{failing, tail.address}, testPackets(data, protobuf.PacketSize))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(response.GetAckedNodes(), []string{head.address, tail.address}) {
+				t.Fatalf("unexpected acks %v", response.GetAckedNodes())
+			}
+			if !slices.Equal(response.GetFailedNodes(), []string{failing}) {
+				t.Fatalf("unexpected failures %v", response.GetFailedNodes())
+			}
+			for _, node := range []*testDataNode{head, tail} {
+				if got, err := readBlock(node, "blk_replay", 0); err != nil || !bytes.Equal(got, data) {
+					t.Fatalf("%s returned %d bytes, %v", node.address, len(got), err)
+				}
+			}
+		})
+	}
+
+	// Without any DataNode left downstream the block is still stored locally
+	head := startDataNode(t)
+	failing := []string{startFailingDataNode(t, 1), startFailingDataNode(t, 0)}
+	response, err := writeBlock(head, "blk_alone", failing, testPackets(data, protobuf.PacketSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(response.GetAckedNodes(), []string{head.address}) || !slices.Equal(response.GetFailedNodes(), failing) {
+		t.Fatalf("unexpected acks %v, failures %v", response.GetAckedNodes(), response.GetFailedNodes())
+	}
+}
@@ -2,22 +2,63 @@ package gRPC
 
 import (
 	"context"
+	"errors"
+	"log"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	datamgmt "github.com/aarrasseayoub01/namenode/datanode/internal/datamngnt"
 	"github.com/aarrasseayoub01/namenode/protobuf"
 )
 
-// NameNodeServer implements the protobuf-defined gRPC server interface
+// DataNodeServer implements the DataNodeService declared in hdfs.proto
 type DataNodeServer struct {
-	protobuf.UnimplementedNameNodeServiceServer
+	protobuf.UnimplementedDataNodeServiceServer
+	dataManager *datamgmt.DataManager
+	// address identifies this DataNode in pipeline acks
+	address string
 }
 
-// NewNameNodeServer creates a new instance of NameNodeServer
-func NewDataNodeServer() *DataNodeServer {
-	return &DataNodeServer{}
+// NewDataNodeServer creates a new instance of DataNodeServer backed by dataManager
+func NewDataNodeServer(dataManager *datamgmt.DataManager, address string) *DataNodeServer {
+	return &DataNodeServer{dataManager: dataManager, address: address}
 }
 
-// RegisterDataNode is an example method implementing a service method
+// StoreBlock persists a block sent by the NameNode or a client
 func (s *DataNodeServer) StoreBlock(ctx context.Context, in *protobuf.StoreBlockRequest) (*protobuf.StoreBlockResponse, error) {
-	// Implement the logic here
+	blockID := in.GetBlockId()
+	if err := s.dataManager.StoreBlock(blockID, in.GetBlockData()); err != nil {
+		log.Printf("Failed to store block %s: %v", blockID, err)
+		return nil, toStatusError(err)
+	}
+
+	log.Printf("Stored block %s (%d bytes)", blockID, len(in.GetBlockData()))
 	return &protobuf.StoreBlockResponse{Success: true}, nil
 }
+
+// RetrieveBlock returns the content of a stored block
+func (s *DataNodeServer) RetrieveBlock(ctx context.Context, in *protobuf.RetrieveBlockRequest) (*protobuf.RetrieveBlockResponse, error) {
+	blockID := in.GetBlockId()
+	data, err := s.dataManager.RetrieveBlock(blockID)
+	if err != nil {
+		log.Printf("Failed to retrieve block %s: %v", blockID, err)
+		return nil, toStatusError(err)
+	}
+
+	return &protobuf.RetrieveBlockResponse{Success: true, BlockData: data}, nil
+}
+
+// toStatusError maps DataManager errors to gRPC status codes
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, datamgmt.ErrInvalidBlockID):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, datamgmt.ErrBlockNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, datamgmt.ErrCorruptBlock):
+		return status.Error(codes.DataLoss, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
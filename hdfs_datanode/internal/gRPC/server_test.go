@@ -0,0 +1,89 @@
+package gRPC
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+func TestStoreAndRetrieveBlock(t *testing.T) {
+	node := startDataNode(t)
+	ctx := context.Background()
+	data := testData(1000)
+
+	if _, err := node.client.StoreBlock(ctx, &protobuf.StoreBlockRequest{BlockId: "blk_store", BlockData: data}); err != nil {
+		t.Fatal(err)
+	}
+	response, err := node.client.RetrieveBlock(ctx, &protobuf.RetrieveBlockRequest{BlockId: "blk_store"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !response.GetSuccess() || !bytes.Equal(response.GetBlockData(), data) {
+		t.Fatalf("retrieved %d bytes, expected %d", len(response.GetBlockData()), len(data))
+	}
+
+	// Blocks stored either way can be read either way
+	if got, err := readBlock(node, "blk_store", 0); err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("read returned %d bytes, %v", len(got), err)
+	}
+	if _, err := writeBlock(node, "blk_streamed", nil, testPackets(data, 100)); err != nil {
+		t.Fatal(err)
+	}
+	response, err = node.client.RetrieveBlock(ctx, &protobuf.RetrieveBlockRequest{BlockId: "blk_streamed"})
+	if err != nil || !bytes.Equal(response.GetBlockData(), data) {
+		t.Fatalf("retrieved %d bytes, %v", len(response.GetBlockData()), err)
+	}
+}
+
+func TestDataNodeServerErrors(t *testing.T) {
+	node := startDataNode(t)
+	ctx := context.Background()
+
+	_, err := node.client.RetrieveBlock(ctx, &protobuf.RetrieveBlockRequest{BlockId: "blk_missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+
+	// Block IDs may not escape the data directory
+	for _, blockID := range []string{"", "../escape", "a/b"} {
+		_, err := node.client.StoreBlock(ctx, &protobuf.StoreBlockRequest{BlockId: blockID, BlockData: []byte("x")})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("storing %q: expected InvalidArgument, got %v", blockID, err)
+		}
+		if _, err := readBlock(node, blockID, 0); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("reading %q: expected InvalidArgument, got %v", blockID, err)
+		}
+	}
+
+	// A block that no longer matches its checksum is not returned
+	if _, err := node.client.StoreBlock(ctx, &protobuf.StoreBlockRequest{BlockId: "blk_corrupt", BlockData: []byte("data")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(node.dir, "data", "block_blk_corrupt"), []byte("date"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = node.client.RetrieveBlock(ctx, &protobuf.RetrieveBlockRequest{BlockId: "blk_corrupt"})
+	if status.Code(err) != codes.DataLoss {
+		t.Fatalf("expected DataLoss, got %v", err)
+	}
+
+	// WriteBlock streams must open with a header
+	stream, err := node.client.WriteBlock(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet := testPackets([]byte("data"), 4)[0]
+	if err := stream.Send(&protobuf.WriteBlockRequest{Payload: &protobuf.WriteBlockRequest_Packet{Packet: packet}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+}
@@ -8,50 +8,125 @@ import (
 	"github.com/gorilla/mux"
 	"google.golang.org/grpc"
 
+	"github.com/aarrasseayoub01/namenode/namenode/internal/config"
 	"github.com/aarrasseayoub01/namenode/namenode/internal/controller"
 	grpc2 "github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
 	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/service"
 	"github.com/aarrasseayoub01/namenode/protobuf"
 )
 
 func main() {
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+	placementPolicy, err := grpc2.NewPlacementPolicy(cfg.BlockPlacementPolicy)
+	if err != nil {
+		log.Fatalf("Error loading configuration: %v", err)
+	}
+
+	// Track DataNode liveness
+	dataNodeManager := grpc2.GetInstance()
+	dataNodeManager.SetTimeouts(cfg.StaleNodeInterval, cfg.DeadNodeInterval)
+	if cfg.TopologyMappingFile != "" {
+		topology, err := grpc2.LoadTopologyFile(cfg.TopologyMappingFile)
+		if err != nil {
+			log.Fatalf("Error loading topology: %v", err)
+		}
+		dataNodeManager.SetTopologyResolver(topology)
+	}
+	dataNodeManager.StartLivenessMonitor(make(chan struct{}))
+	// Deleted blocks are removed from the DataNodes even across restarts
+	invalidations, err := grpc2.LoadInvalidationLog("invalidations.log")
+	if err != nil {
+		log.Fatalf("Failed to load the invalidation log: %v", err)
+	}
+	dataNodeManager.SetInvalidationLog(invalidations)
+	// Replicas on dead DataNodes no longer count
+	grpc2.GetBlockMap().TrackDataNodes(dataNodeManager)
+
+	// Initialize the file system service shared by both servers
+	persistence.SetEditLogPolicy(int64(cfg.EditLogSegmentSize), cfg.EditLogRollInterval, cfg.RetainedCheckpoints)
+	persistence.SetCheckpointPolicy(cfg.CheckpointTxns, cfg.CheckpointPeriod)
+	rootDir := persistence.InitializeFileSystem()
+	fileSystemService := service.NewFileSystemService(rootDir, cfg)
+	// Checkpoint in the background once the service guards the namespace
+	persistence.StartCheckpointer(make(chan struct{}))
+	fileSystemService.SetPlacementPolicy(placementPolicy)
+	if cfg.GroupMappingFile != "" {
+		groups, err := service.LoadGroupMapping(cfg.GroupMappingFile)
+		if err != nil {
+			log.Fatalf("Error loading group mapping: %v", err)
+		}
+		fileSystemService.SetGroupMapping(groups)
+	}
+
+	// Repair missing and excess replicas once the namespace is loaded
+	replicationMonitor := grpc2.NewReplicationMonitor(grpc2.GetBlockMap(), dataNodeManager)
+	replicationMonitor.SetLimits(cfg.ReplicationTimeout, cfg.MaxReplicationStreams)
+	replicationMonitor.SetPlacementPolicy(placementPolicy)
+	replicationMonitor.Start(cfg.ReplicationCheckInterval, make(chan struct{}))
+
 	// Start the REST server
-	go startRESTserver()
+	go startRESTserver(fileSystemService, replicationMonitor)
 
 	// Start the gRPC server
-	startGRPCserver()
+	startGRPCserver(fileSystemService)
 }
 
-func startRESTserver() {
-	// Initialize the file system service
-	rootDir := persistence.InitializeFileSystem()
-
-	// Set up the controller with the service
-	controller := controller.NewFileSystemController(rootDir)
+func startRESTserver(fileSystemService *service.FileSystemService, replicationMonitor *grpc2.ReplicationMonitor) {
+	// Set up the controllers with the service
+	clusterController := controller.NewClusterController(grpc2.GetInstance(), replicationMonitor)
+	controller := controller.NewFileSystemController(fileSystemService)
 
 	r := mux.NewRouter()
 
 	// Define the routes
 	r.HandleFunc("/createFile", controller.CreateFileHandler).Methods("POST")
+	r.HandleFunc("/completeFile", controller.CompleteFileHandler).Methods("POST")
+	r.HandleFunc("/writeFile", controller.WriteFileHandler).Methods("PUT")
 	r.HandleFunc("/readFile", controller.ReadFileHandler).Methods("GET")
+	r.HandleFunc("/readFileData", controller.ReadFileDataHandler).Methods("GET")
 	r.HandleFunc("/deleteFile", controller.DeleteFileHandler).Methods("DELETE")
+	r.HandleFunc("/rename", controller.RenameHandler).Methods("POST")
 	r.HandleFunc("/createDir", controller.CreateDirectoryHandler).Methods("POST")
 	r.HandleFunc("/readDir", controller.ReadDirectoryHandler).Methods("GET")
 	r.HandleFunc("/deleteDir", controller.DeleteDirectoryHandler).Methods("DELETE")
+	r.HandleFunc("/setPermission", controller.SetPermissionHandler).Methods("PUT")
+	r.HandleFunc("/setOwner", controller.SetOwnerHandler).Methods("PUT")
+	r.HandleFunc("/getAclStatus", controller.GetAclStatusHandler).Methods("GET")
+	r.HandleFunc("/setAcl", controller.SetAclHandler).Methods("PUT")
+	r.HandleFunc("/modifyAclEntries", controller.ModifyAclEntriesHandler).Methods("PUT")
+	r.HandleFunc("/removeAclEntries", controller.RemoveAclEntriesHandler).Methods("PUT")
+	r.HandleFunc("/removeDefaultAcl", controller.RemoveDefaultAclHandler).Methods("PUT")
+	r.HandleFunc("/removeAcl", controller.RemoveAclHandler).Methods("PUT")
+	r.HandleFunc("/setQuota", controller.SetQuotaHandler).Methods("PUT")
+	r.HandleFunc("/contentSummary", controller.ContentSummaryHandler).Methods("GET")
+	r.HandleFunc("/allowSnapshot", controller.AllowSnapshotHandler).Methods("PUT")
+	r.HandleFunc("/disallowSnapshot", controller.DisallowSnapshotHandler).Methods("PUT")
+	r.HandleFunc("/createSnapshot", controller.CreateSnapshotHandler).Methods("PUT")
+	r.HandleFunc("/deleteSnapshot", controller.DeleteSnapshotHandler).Methods("DELETE")
+	r.HandleFunc("/renameSnapshot", controller.RenameSnapshotHandler).Methods("PUT")
+	r.HandleFunc("/snapshotDiff", controller.SnapshotDiffHandler).Methods("GET")
+	r.HandleFunc("/saveNamespace", controller.SaveNamespaceHandler).Methods("PUT")
+	r.HandleFunc("/clusterStatus", clusterController.ClusterStatusHandler).Methods("GET")
+	r.HandleFunc("/replicationStatus", clusterController.ReplicationStatusHandler).Methods("GET")
 
 	// Start the server
 	log.Println("Starting server on :8080")
 	log.Fatal(http.ListenAndServe(":8080", r))
 }
 
-func startGRPCserver() {
+func startGRPCserver(fileSystemService *service.FileSystemService) {
 	lis, err := net.Listen("tcp", ":50051")
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
 	grpcServer := grpc.NewServer()
-	protobuf.RegisterNameNodeServiceServer(grpcServer, grpc2.NewNameNodeServer())
+	protobuf.RegisterNameNodeServiceServer(grpcServer, grpc2.NewNameNodeServer(fileSystemService))
 	log.Println("Starting gRPC server on :50051")
 	if err := grpcServer.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %s", err)
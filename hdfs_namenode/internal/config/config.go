@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+)
+
+type Config struct {
+	// DefaultReplication is the number of replicas kept of each block
+	// unless a file asks for another replication factor
+	DefaultReplication int
+	// MaxReplication bounds the replication factor a file can ask for
+	MaxReplication int
+
+	// StaleNodeInterval is how long a DataNode may go without heartbeating
+	// before it stops receiving new blocks
+	StaleNodeInterval time.Duration
+	// DeadNodeInterval is how long a DataNode may go without heartbeating
+	// before its replicas are considered lost
+	DeadNodeInterval time.Duration
+
+	// ReplicationCheckInterval is how often blocks are checked for missing
+	// or excess replicas
+	ReplicationCheckInterval time.Duration
+	// ReplicationTimeout is how long a scheduled copy may take before it is
+	// given up and scheduled again
+	ReplicationTimeout time.Duration
+	// MaxReplicationStreams bounds the copies a DataNode is asked to send at once
+	MaxReplicationStreams int
+
+	// BlockPlacementPolicy names the policy choosing where replicas are stored
+	BlockPlacementPolicy string
+	// TopologyMappingFile maps DataNode hosts to racks; without one every
+	// DataNode is on DefaultRack
+	TopologyMappingFile string
+
+	// PermissionsEnabled turns permission checking on; when off every user
+	// may do anything, but ownership is still recorded
+	PermissionsEnabled bool
+	// Superuser and members of Supergroup bypass permission checks
+	Superuser  string
+	Supergroup string
+	// Umask is removed from the permission of new files and directories
+	Umask fs.Permission
+	// GroupMappingFile lists the groups of each user; without one users
+	// belong to no group
+	GroupMappingFile string
+
+	// ContentSummaryLimit is how many inodes a content summary counts before
+	// letting other operations at the namespace; 0 holds the lock throughout
+	ContentSummaryLimit int
+
+	// EditLogSegmentSize and EditLogRollInterval bound the size and age of
+	// the edit log segment being written before it is finalized
+	EditLogSegmentSize  int
+	EditLogRollInterval time.Duration
+	// RetainedCheckpoints is how many fsimages are kept, along with the edit
+	// log segments needed to replay any of them
+	RetainedCheckpoints int
+	// CheckpointTxns and CheckpointPeriod bound how many transactions, and
+	// how long, the namespace goes without being checkpointed
+	CheckpointTxns   int
+	CheckpointPeriod time.Duration
+}
+
+// DefaultConfig returns the configuration used when nothing is overridden
+func DefaultConfig() *Config {
+	return &Config{
+		DefaultReplication: 3,
+		MaxReplication:     512,
+		StaleNodeInterval:  90 * time.Second,
+		DeadNodeInterval:   10 * time.Minute,
+
+		ReplicationCheckInterval: 3 * time.Second,
+		ReplicationTimeout:       5 * time.Minute,
+		MaxReplicationStreams:    2,
+
+		BlockPlacementPolicy: "default",
+
+		PermissionsEnabled: true,
+		Superuser:          "hdfs",
+		Supergroup:         "supergroup",
+		Umask:              0022,
+
+		ContentSummaryLimit: 5000,
+
+		EditLogSegmentSize:  1 << 20,
+		EditLogRollInterval: 10 * time.Minute,
+		RetainedCheckpoints: 2,
+		CheckpointTxns:      10000,
+		CheckpointPeriod:    time.Hour,
+	}
+}
+
+// LoadConfig loads the configuration from the environment, falling back to
+// DefaultConfig for anything unset
+func LoadConfig() (*Config, error) {
+	cfg := DefaultConfig()
+
+	var err error
+	if cfg.DefaultReplication, err = getEnvInt("HDFS_REPLICATION", cfg.DefaultReplication); err != nil {
+		return nil, err
+	}
+	if cfg.MaxReplication, err = getEnvInt("HDFS_MAX_REPLICATION", cfg.MaxReplication); err != nil {
+		return nil, err
+	}
+
+	if cfg.StaleNodeInterval, err = getEnvDuration("HDFS_STALE_NODE_INTERVAL", cfg.StaleNodeInterval); err != nil {
+		return nil, err
+	}
+	if cfg.DeadNodeInterval, err = getEnvDuration("HDFS_DEAD_NODE_INTERVAL", cfg.DeadNodeInterval); err != nil {
+		return nil, err
+	}
+
+	if cfg.ReplicationCheckInterval, err = getEnvDuration("HDFS_REPLICATION_CHECK_INTERVAL", cfg.ReplicationCheckInterval); err != nil {
+		return nil, err
+	}
+	if cfg.ReplicationTimeout, err = getEnvDuration("HDFS_REPLICATION_TIMEOUT", cfg.ReplicationTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.MaxReplicationStreams, err = getEnvInt("HDFS_MAX_REPLICATION_STREAMS", cfg.MaxReplicationStreams); err != nil {
+		return nil, err
+	}
+
+	if value := os.Getenv("HDFS_BLOCK_PLACEMENT_POLICY"); value != "" {
+		cfg.BlockPlacementPolicy = value
+	}
+	cfg.TopologyMappingFile = os.Getenv("HDFS_TOPOLOGY_MAPPING_FILE")
+
+	if value := os.Getenv("HDFS_PERMISSIONS_ENABLED"); value != "" {
+		if cfg.PermissionsEnabled, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("invalid value for HDFS_PERMISSIONS_ENABLED: %v", err)
+		}
+	}
+	if value := os.Getenv("HDFS_SUPERUSER"); value != "" {
+		cfg.Superuser = value
+	}
+	if value := os.Getenv("HDFS_SUPERGROUP"); value != "" {
+		cfg.Supergroup = value
+	}
+	if value := os.Getenv("HDFS_UMASK"); value != "" {
+		umask, err := fs.ParsePermission(value)
+		if err != nil || umask > 0777 {
+			return nil, fmt.Errorf("invalid value for HDFS_UMASK: %q", value)
+		}
+		cfg.Umask = umask
+	}
+	cfg.GroupMappingFile = os.Getenv("HDFS_GROUP_MAPPING_FILE")
+
+	if cfg.ContentSummaryLimit, err = getEnvInt("HDFS_CONTENT_SUMMARY_LIMIT", cfg.ContentSummaryLimit); err != nil {
+		return nil, err
+	}
+
+	if cfg.EditLogSegmentSize, err = getEnvInt("HDFS_EDIT_LOG_SEGMENT_SIZE", cfg.EditLogSegmentSize); err != nil {
+		return nil, err
+	}
+	if cfg.EditLogRollInterval, err = getEnvDuration("HDFS_EDIT_LOG_ROLL_INTERVAL", cfg.EditLogRollInterval); err != nil {
+		return nil, err
+	}
+	if cfg.RetainedCheckpoints, err = getEnvInt("HDFS_RETAINED_CHECKPOINTS", cfg.RetainedCheckpoints); err != nil {
+		return nil, err
+	}
+	if cfg.CheckpointTxns, err = getEnvInt("HDFS_CHECKPOINT_TXNS", cfg.CheckpointTxns); err != nil {
+		return nil, err
+	}
+	if cfg.CheckpointPeriod, err = getEnvDuration("HDFS_CHECKPOINT_PERIOD", cfg.CheckpointPeriod); err != nil {
+		return nil, err
+	}
+
+	if cfg.DefaultReplication < 1 || cfg.DefaultReplication > cfg.MaxReplication {
+		return nil, fmt.Errorf("default replication %d must be between 1 and %d", cfg.DefaultReplication, cfg.MaxReplication)
+	}
+	if cfg.StaleNodeInterval <= 0 || cfg.DeadNodeInterval <= cfg.StaleNodeInterval {
+		return nil, fmt.Errorf("dead node interval %v must be longer than stale node interval %v", cfg.DeadNodeInterval, cfg.StaleNodeInterval)
+	}
+
+	if cfg.ReplicationCheckInterval <= 0 || cfg.ReplicationTimeout <= 0 {
+		return nil, fmt.Errorf("replication check interval and timeout must be positive")
+	}
+	if cfg.MaxReplicationStreams < 1 {
+		return nil, fmt.Errorf("max replication streams must be at least 1")
+	}
+	if cfg.ContentSummaryLimit < 0 {
+		return nil, fmt.Errorf("content summary limit must not be negative")
+	}
+	if cfg.EditLogSegmentSize <= 0 || cfg.EditLogRollInterval <= 0 {
+		return nil, fmt.Errorf("edit log segment size and roll interval must be positive")
+	}
+	if cfg.RetainedCheckpoints < 1 {
+		return nil, fmt.Errorf("at least 1 checkpoint must be retained")
+	}
+	if cfg.CheckpointTxns <= 0 || cfg.CheckpointPeriod <= 0 {
+		return nil, fmt.Errorf("checkpoint transactions and period must be positive")
+	}
+
+	return cfg, nil
+}
+
+// getEnvInt parses the environment variable key, or returns fallback if unset
+func getEnvInt(key string, fallback int) (int, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %v", key, err)
+	}
+	return parsed, nil
+}
+
+// getEnvDuration parses the environment variable key (e.g. "90s"), or returns
+// fallback if unset
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %v", key, err)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,41 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+)
+
+// ClusterController serves operator views of the DataNodes and their blocks
+type ClusterController struct {
+	DataNodeManager    *gRPC.DataNodeManager
+	ReplicationMonitor *gRPC.ReplicationMonitor
+}
+
+func NewClusterController(dataNodeManager *gRPC.DataNodeManager, replicationMonitor *gRPC.ReplicationMonitor) *ClusterController {
+	return &ClusterController{DataNodeManager: dataNodeManager, ReplicationMonitor: replicationMonitor}
+}
+
+// ClusterStatusHandler reports the state, capacity and load of every DataNode
+func (c *ClusterController) ClusterStatusHandler(w http.ResponseWriter, r *http.Request) {
+	report := c.DataNodeManager.Report()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// ReplicationStatusHandler reports the replication queues and the copies and
+// deletions in progress
+func (c *ClusterController) ReplicationStatusHandler(w http.ResponseWriter, r *http.Request) {
+	status := c.ReplicationMonitor.Status()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -2,38 +2,104 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net"
 	"net/http"
-	"strings"
+	"strconv"
 
 	utils "github.com/aarrasseayoub01/namenode/namenode/internal/fs"
-	svc "github.com/aarrasseayoub01/namenode/namenode/internal/service"
 )
 
-type FileSystemController struct {
-	Service *svc.FileSystemService
+// FileSystemService is the set of namespace operations the controller relies on.
+// Every operation runs as the user named first.
+type FileSystemService interface {
+	CreateFile(user, filePath string, fileSize int64, replication int, writer string) (*utils.Inode, error)
+	CompleteFile(user, filePath string, ackedBlocks []utils.BlockAssignment) (*utils.Inode, error)
+	WriteFile(user, filePath string, fileSize int64, replication int, data io.Reader) (*utils.Inode, error)
+	Rename(user, srcPath, dstPath string, overwrite bool) error
+	ReadFile(user, filePath string) (*utils.Inode, error)
+	OpenFile(user, filePath string) (*utils.Inode, error)
+	ReadFileData(inode *utils.Inode, w io.Writer) error
+	DeleteFile(user, filePath string) error
+	CreateDirectory(user, dirPath string) (*utils.Inode, error)
+	ReadDirectory(user, dirPath string) ([]*utils.Inode, error)
+	DeleteDirectory(user, dirPath string, recursive bool) error
+	SetPermission(user, path string, permission utils.Permission) error
+	SetOwner(user, path, owner, group string) error
+	GetAclStatus(user, path string) (*utils.AclStatus, error)
+	SetAcl(user, path string, entries []utils.AclEntry) error
+	ModifyAclEntries(user, path string, entries []utils.AclEntry) error
+	RemoveAclEntries(user, path string, entries []utils.AclEntry) error
+	RemoveDefaultAcl(user, path string) error
+	RemoveAcl(user, path string) error
+	SetQuota(user, dirPath string, namespaceQuota, spaceQuota int64) error
+	GetContentSummary(user, path string) (*utils.ContentSummary, error)
+	AllowSnapshot(user, dirPath string) error
+	DisallowSnapshot(user, dirPath string) error
+	CreateSnapshot(user, dirPath, name string) (string, error)
+	DeleteSnapshot(user, dirPath, name string) error
+	RenameSnapshot(user, dirPath, oldName, newName string) error
+	SnapshotDiff(user, dirPath, from, to string) ([]utils.DiffEntry, error)
+	SaveNamespace(user string) (int64, error)
+}
+
+// defaultUser is the user of requests that don't name one, as in WebHDFS
+const defaultUser = "dr.who"
+
+// requestUser returns the user a request runs as, named by its user.name
+// query parameter
+func requestUser(r *http.Request) string {
+	if user := r.URL.Query().Get("user.name"); user != "" {
+		return user
+	}
+	return defaultUser
 }
 
-func NewFileSystemController(rootDir *utils.Directory) *FileSystemController {
-	fileSystemService := svc.NewFileSystemService(rootDir)
+// errorStatus maps permission, quota and snapshot write errors to 403
+// Forbidden, missing paths to 404 Not Found, and other errors to status
+func errorStatus(err error, status int) int {
+	if errors.Is(err, utils.ErrPermissionDenied) || errors.Is(err, utils.ErrQuotaExceeded) ||
+		errors.Is(err, utils.ErrSnapshotReadOnly) {
+		return http.StatusForbidden
+	}
+	if errors.Is(err, utils.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return status
+}
 
+type FileSystemController struct {
+	Service FileSystemService
+}
+
+func NewFileSystemController(fileSystemService FileSystemService) *FileSystemController {
 	return &FileSystemController{Service: fileSystemService}
 }
 
 func (c *FileSystemController) CreateFileHandler(w http.ResponseWriter, r *http.Request) {
 	var request struct {
-		FilePath string `json:"filePath"`
-		FileSize int64  `json:"fileSize"`
+		FilePath    string `json:"filePath"`
+		FileSize    int64  `json:"fileSize"`
+		Replication int    `json:"replication"` // Optional, defaults to the cluster replication
 	}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	fileInode, err := c.Service.CreateFile(request.FilePath, request.FileSize)
+	// The client writes the blocks, so its rack gets the first replicas
+	writer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		writer = r.RemoteAddr
+	}
+
+	fileInode, err := c.Service.CreateFile(requestUser(r), request.FilePath, request.FileSize, request.Replication, writer)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -43,35 +109,70 @@ func (c *FileSystemController) CreateFileHandler(w http.ResponseWriter, r *http.
 	}
 }
 
-func (c *FileSystemController) ReadFileHandler(w http.ResponseWriter, r *http.Request) {
+// CompleteFileHandler commits a file once the client has written its blocks.
+// Each block lists the DataNodes of its pipeline that acknowledged it.
+func (c *FileSystemController) CompleteFileHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		FilePath string                  `json:"filePath"`
+		Blocks   []utils.BlockAssignment `json:"blocks"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	// file, err := os.Open("../../cmd/server/fsimage.gob")
-	// if err != nil {
-	// 	fmt.Println("Error opening file:", err)
-	// 	return
-	// }
-	// defer file.Close()
-
-	// // Create a decoder
-	// decoder := gob.NewDecoder(file)
-
-	// // Decode the data
-	// var data fs.Directory
-	// err = decoder.Decode(&data)
-	// if err != nil {
-	// 	fmt.Println("Error decoding GOB data:", err)
-	// 	return
-	// }
-
-	// // Use the data
-	// fmt.Println("Decoded data:", data)
-	// Parse the query from URL
-	query := r.URL.RawQuery
+	fileInode, err := c.Service.CompleteFile(requestUser(r), request.FilePath, request.Blocks)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusConflict))
+		return
+	}
 
-	// Read file
-	fileInode, err := c.Service.ReadFile(strings.Split(query, "=")[1])
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(fileInode); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WriteFileHandler creates a file from the raw request body, streaming its
+// blocks to the DataNodes before the file is made visible.
+func (c *FileSystemController) WriteFileHandler(w http.ResponseWriter, r *http.Request) {
+	filePath := r.URL.Query().Get("filePath")
+	if filePath == "" {
+		http.Error(w, "missing filePath parameter", http.StatusBadRequest)
+		return
+	}
+	if r.ContentLength < 0 {
+		http.Error(w, "Content-Length is required", http.StatusLengthRequired)
+		return
+	}
+
+	// The replication factor is optional and defaults to the cluster's
+	replication := 0
+	if value := r.URL.Query().Get("replication"); value != "" {
+		var err error
+		if replication, err = strconv.Atoi(value); err != nil {
+			http.Error(w, "invalid replication parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	fileInode, err := c.Service.WriteFile(requestUser(r), filePath, r.ContentLength, replication, r.Body)
 	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(fileInode); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (c *FileSystemController) ReadFileHandler(w http.ResponseWriter, r *http.Request) {
+	// Read file
+	fileInode, err := c.Service.ReadFile(requestUser(r), r.URL.Query().Get("filePath"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -83,6 +184,31 @@ func (c *FileSystemController) ReadFileHandler(w http.ResponseWriter, r *http.Re
 	}
 }
 
+// ReadFileDataHandler streams the content of a file, reassembled from its
+// blocks on the DataNodes.
+func (c *FileSystemController) ReadFileDataHandler(w http.ResponseWriter, r *http.Request) {
+	filePath := r.URL.Query().Get("filePath")
+	if filePath == "" {
+		http.Error(w, "missing filePath parameter", http.StatusBadRequest)
+		return
+	}
+
+	fileInode, err := c.Service.OpenFile(requestUser(r), filePath)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(fileInode.Size, 10))
+	w.WriteHeader(http.StatusOK)
+
+	// The status line is already sent, so a failure midway can only be logged
+	if err := c.Service.ReadFileData(fileInode, w); err != nil {
+		log.Printf("Error streaming file %s: %v", filePath, err)
+	}
+}
+
 func (c *FileSystemController) DeleteFileHandler(w http.ResponseWriter, r *http.Request) {
 	var request struct {
 		FilePath string `json:"filePath"`
@@ -92,9 +218,29 @@ func (c *FileSystemController) DeleteFileHandler(w http.ResponseWriter, r *http.
 		return
 	}
 
-	err := c.Service.DeleteFile(request.FilePath)
+	err := c.Service.DeleteFile(requestUser(r), request.FilePath)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RenameHandler moves a file or directory, optionally replacing the destination
+func (c *FileSystemController) RenameHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		SrcPath   string `json:"srcPath"`
+		DstPath   string `json:"dstPath"`
+		Overwrite bool   `json:"overwrite"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Service.Rename(requestUser(r), request.SrcPath, request.DstPath, request.Overwrite); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -110,9 +256,9 @@ func (c *FileSystemController) CreateDirectoryHandler(w http.ResponseWriter, r *
 		return
 	}
 
-	inode, err := c.Service.CreateDirectory(request.DirPath)
+	inode, err := c.Service.CreateDirectory(requestUser(r), request.DirPath)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -122,13 +268,10 @@ func (c *FileSystemController) CreateDirectoryHandler(w http.ResponseWriter, r *
 	}
 }
 func (c *FileSystemController) ReadDirectoryHandler(w http.ResponseWriter, r *http.Request) {
-	// Parse the query from URL
-	query := r.URL.RawQuery
-
 	// Read Directory
-	inodes, err := c.Service.ReadDirectory(strings.Split(query, "=")[1])
+	inodes, err := c.Service.ReadDirectory(requestUser(r), r.URL.Query().Get("dirPath"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -143,18 +286,280 @@ func (c *FileSystemController) ReadDirectoryHandler(w http.ResponseWriter, r *ht
 
 func (c *FileSystemController) DeleteDirectoryHandler(w http.ResponseWriter, r *http.Request) {
 	var request struct {
-		DirPath string `json:"dirPath"`
+		DirPath   string `json:"dirPath"`
+		Recursive bool   `json:"recursive"` // Also delete the directory's content
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err := c.Service.DeleteDirectory(requestUser(r), request.DirPath, request.Recursive)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// SetPermissionHandler changes the permission of a file or directory, given in
+// octal as in chmod
+func (c *FileSystemController) SetPermissionHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Path       string `json:"path"`
+		Permission string `json:"permission"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	permission, err := utils.ParsePermission(request.Permission)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Service.SetPermission(requestUser(r), request.Path, permission); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// SetOwnerHandler changes the owner and/or group of a file or directory
+func (c *FileSystemController) SetOwnerHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Path  string `json:"path"`
+		Owner string `json:"owner"` // Left unchanged if empty
+		Group string `json:"group"` // Left unchanged if empty
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := c.Service.SetOwner(requestUser(r), request.Path, request.Owner, request.Group); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetAclStatusHandler returns the owner, group, permission and full ACL of a
+// file or directory
+func (c *FileSystemController) GetAclStatusHandler(w http.ResponseWriter, r *http.Request) {
+	status, err := c.Service.GetAclStatus(requestUser(r), r.URL.Query().Get("path"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// SetAclHandler replaces the ACL of a file or directory with an ACL spec such
+// as "user:bob:rwx,default:group:analysts:r-x"
+func (c *FileSystemController) SetAclHandler(w http.ResponseWriter, r *http.Request) {
+	c.handleAclChange(w, r, true, c.Service.SetAcl)
+}
+
+// ModifyAclEntriesHandler adds or replaces the entries of an ACL spec
+func (c *FileSystemController) ModifyAclEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	c.handleAclChange(w, r, true, c.Service.ModifyAclEntries)
+}
+
+// RemoveAclEntriesHandler removes the entries of an ACL spec written without
+// permissions, such as "user:bob,default:group:analysts"
+func (c *FileSystemController) RemoveAclEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	c.handleAclChange(w, r, false, c.Service.RemoveAclEntries)
+}
+
+// RemoveDefaultAclHandler removes the default ACL of a directory
+func (c *FileSystemController) RemoveDefaultAclHandler(w http.ResponseWriter, r *http.Request) {
+	c.handleAclChange(w, r, false, func(user, path string, _ []utils.AclEntry) error {
+		return c.Service.RemoveDefaultAcl(user, path)
+	})
+}
+
+// RemoveAclHandler removes every extended entry of the ACL of a file or directory
+func (c *FileSystemController) RemoveAclHandler(w http.ResponseWriter, r *http.Request) {
+	c.handleAclChange(w, r, false, func(user, path string, _ []utils.AclEntry) error {
+		return c.Service.RemoveAcl(user, path)
+	})
+}
+
+// handleAclChange decodes a request naming a path and an optional ACL spec,
+// and applies change to them
+func (c *FileSystemController) handleAclChange(w http.ResponseWriter, r *http.Request, withPermission bool,
+	change func(user, path string, entries []utils.AclEntry) error) {
+	var request struct {
+		Path    string `json:"path"`
+		AclSpec string `json:"aclSpec"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entries, err := utils.ParseAclSpec(request.AclSpec, withPermission)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := change(requestUser(r), request.Path, entries); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// SetQuotaHandler sets the namespace and space quotas of a directory; a quota
+// of 0 removes the limit
+func (c *FileSystemController) SetQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Path           string `json:"path"`
+		NamespaceQuota int64  `json:"namespaceQuota"`
+		SpaceQuota     int64  `json:"spaceQuota"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	err := c.Service.DeleteDirectory(request.DirPath)
+	if err := c.Service.SetQuota(requestUser(r), request.Path, request.NamespaceQuota, request.SpaceQuota); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ContentSummaryHandler returns the number of files and directories, the bytes
+// stored and the quotas below a path
+func (c *FileSystemController) ContentSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	summary, err := c.Service.GetContentSummary(requestUser(r), r.URL.Query().Get("path"))
 	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// snapshotRequest names a snapshottable directory and one of its snapshots
+type snapshotRequest struct {
+	Path            string `json:"path"`
+	SnapshotName    string `json:"snapshotName"`
+	OldSnapshotName string `json:"oldSnapshotName"`
+}
+
+// AllowSnapshotHandler lets snapshots be taken of a directory
+func (c *FileSystemController) AllowSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	c.handleSnapshotChange(w, r, func(user string, request snapshotRequest) error {
+		return c.Service.AllowSnapshot(user, request.Path)
+	})
+}
+
+// DisallowSnapshotHandler stops snapshots being taken of a directory
+func (c *FileSystemController) DisallowSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	c.handleSnapshotChange(w, r, func(user string, request snapshotRequest) error {
+		return c.Service.DisallowSnapshot(user, request.Path)
+	})
+}
+
+// CreateSnapshotHandler takes a snapshot of a directory and returns its path
+func (c *FileSystemController) CreateSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	var request snapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	snapshotPath, err := c.Service.CreateSnapshot(requestUser(r), request.Path, request.SnapshotName)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]string{"path": snapshotPath}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// DeleteSnapshotHandler deletes a snapshot of a directory
+func (c *FileSystemController) DeleteSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	c.handleSnapshotChange(w, r, func(user string, request snapshotRequest) error {
+		return c.Service.DeleteSnapshot(user, request.Path, request.SnapshotName)
+	})
+}
+
+// RenameSnapshotHandler renames the snapshot oldSnapshotName of a directory
+// to snapshotName
+func (c *FileSystemController) RenameSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	c.handleSnapshotChange(w, r, func(user string, request snapshotRequest) error {
+		return c.Service.RenameSnapshot(user, request.Path, request.OldSnapshotName, request.SnapshotName)
+	})
+}
+
+// SnapshotDiffHandler reports the changes to a directory between the snapshots
+// oldSnapshotName and snapshotName; an empty name stands for the current state
+func (c *FileSystemController) SnapshotDiffHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	diff, err := c.Service.SnapshotDiff(requestUser(r), query.Get("path"), query.Get("oldSnapshotName"), query.Get("snapshotName"))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusNotFound))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(diff); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// handleSnapshotChange decodes a snapshotRequest and applies change to it
+func (c *FileSystemController) handleSnapshotChange(w http.ResponseWriter, r *http.Request, change func(user string, request snapshotRequest) error) {
+	var request snapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := change(requestUser(r), request); err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// SaveNamespaceHandler checkpoints the namespace to a new fsimage and returns
+// the ID of the last transaction it includes
+func (c *FileSystemController) SaveNamespaceHandler(w http.ResponseWriter, r *http.Request) {
+	txID, err := c.Service.SaveNamespace(requestUser(r))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatus(err, http.StatusInternalServerError))
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(map[string]int64{"txId": txID}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
 }
@@ -0,0 +1,327 @@
+package fs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AclEntryScope tells whether an ACL entry is checked on access to the inode,
+// or only copied to the children created in a directory
+type AclEntryScope string
+
+const (
+	AclScopeAccess  AclEntryScope = "access"
+	AclScopeDefault AclEntryScope = "default"
+)
+
+// AclEntryType tells whom an ACL entry applies to
+type AclEntryType string
+
+const (
+	AclUser  AclEntryType = "user"
+	AclGroup AclEntryType = "group"
+	AclMask  AclEntryType = "mask"
+	AclOther AclEntryType = "other"
+)
+
+// AclEntry grants access to a user or group beyond the permission bits. An
+// entry without a name applies to the owner or group of the inode.
+type AclEntry struct {
+	Scope      AclEntryScope `json:"scope"`
+	Type       AclEntryType  `json:"type"`
+	Name       string        `json:"name,omitempty"`
+	Permission FsAction      `json:"permission"`
+}
+
+// AclStatus describes the ownership, permission and ACL of a file or directory
+type AclStatus struct {
+	Owner      string     `json:"owner"`
+	Group      string     `json:"group"`
+	Permission Permission `json:"permission"`
+	// Entries is the full ACL, including the entries held in the permission bits
+	Entries []AclEntry `json:"entries"`
+}
+
+// String formats the entry as in an ACL spec, e.g. default:user:bob:r-x
+func (e AclEntry) String() string {
+	s := fmt.Sprintf("%s:%s:%s", e.Type, e.Name, e.Permission.Symbol())
+	if e.Scope == AclScopeDefault {
+		s = "default:" + s
+	}
+	return s
+}
+
+// Symbol formats the action like ls, e.g. r-x
+func (a FsAction) Symbol() string {
+	return Permission(a).String()[6:]
+}
+
+type aclKey struct {
+	scope     AclEntryScope
+	entryType AclEntryType
+	name      string
+}
+
+func (e AclEntry) key() aclKey {
+	return aclKey{e.Scope, e.Type, e.Name}
+}
+
+// ParseAclSpec parses a comma separated list of entries such as
+// "user:bob:rwx,default:group:analysts:r-x". Without withPermission the
+// entries only name whom they apply to, as when removing them.
+func ParseAclSpec(spec string, withPermission bool) ([]AclEntry, error) {
+	var entries []AclEntry
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		entry, err := parseAclEntry(field, withPermission)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func parseAclEntry(field string, withPermission bool) (AclEntry, error) {
+	entry := AclEntry{Scope: AclScopeAccess}
+	parts := strings.Split(field, ":")
+	if parts[0] == "default" {
+		entry.Scope = AclScopeDefault
+		parts = parts[1:]
+	}
+
+	want := 2
+	if withPermission {
+		want = 3
+	}
+	// The name may be left out of mask and other entries
+	if len(parts) == want-1 && (parts[0] == string(AclMask) || parts[0] == string(AclOther)) {
+		parts = append(parts[:1], append([]string{""}, parts[1:]...)...)
+	}
+	if len(parts) != want {
+		return AclEntry{}, fmt.Errorf("invalid ACL entry %q", field)
+	}
+
+	entry.Type = AclEntryType(parts[0])
+	entry.Name = parts[1]
+	switch entry.Type {
+	case AclUser, AclGroup:
+	case AclMask, AclOther:
+		if entry.Name != "" {
+			return AclEntry{}, fmt.Errorf("invalid ACL entry %q: %s entries have no name", field, entry.Type)
+		}
+	default:
+		return AclEntry{}, fmt.Errorf("invalid ACL entry %q: unknown type %s", field, entry.Type)
+	}
+
+	if withPermission {
+		permission, err := parseAction(parts[2])
+		if err != nil {
+			return AclEntry{}, fmt.Errorf("invalid ACL entry %q: %v", field, err)
+		}
+		entry.Permission = permission
+	}
+	return entry, nil
+}
+
+// parseAction parses an action written like ls, e.g. r-x
+func parseAction(value string) (FsAction, error) {
+	if len(value) != 3 {
+		return 0, fmt.Errorf("invalid permission %q", value)
+	}
+	var action FsAction
+	for i, bit := range []struct {
+		symbol byte
+		action FsAction
+	}{{'r', ActionRead}, {'w', ActionWrite}, {'x', ActionExecute}} {
+		switch value[i] {
+		case bit.symbol:
+			action |= bit.action
+		case '-':
+		default:
+			return 0, fmt.Errorf("invalid permission %q", value)
+		}
+	}
+	return action, nil
+}
+
+// FullAcl returns every entry of the ACL of an inode, including those held in
+// its permission bits. While an inode has an extended access ACL its group
+// bits hold the mask, which limits the access granted to named users and to
+// groups.
+func FullAcl(permission Permission, acl []AclEntry) []AclEntry {
+	entries := []AclEntry{
+		{Scope: AclScopeAccess, Type: AclUser, Permission: permission.Owner()},
+		{Scope: AclScopeAccess, Type: AclOther, Permission: permission.Other()},
+	}
+	access := AccessEntries(acl)
+	if len(access) == 0 {
+		entries = append(entries, AclEntry{Scope: AclScopeAccess, Type: AclGroup, Permission: permission.Group()})
+	} else {
+		entries = append(entries, access...)
+		entries = append(entries, AclEntry{Scope: AclScopeAccess, Type: AclMask, Permission: permission.Group()})
+	}
+	entries = append(entries, DefaultEntries(acl)...)
+	sortAcl(entries)
+	return entries
+}
+
+// ApplyAcl validates a full ACL and splits it into the permission bits and the
+// entries stored on the inode; the sticky bit of permission is kept. A mask
+// is calculated for each scope with named entries and no mask, as the union
+// of the entries it limits, and default entries missing from the default ACL
+// are copied from the access ACL.
+func ApplyAcl(permission Permission, entries []AclEntry) (Permission, []AclEntry, error) {
+	byKey := make(map[aclKey]AclEntry, len(entries))
+	for _, entry := range entries {
+		if _, duplicate := byKey[entry.key()]; duplicate {
+			return 0, nil, fmt.Errorf("invalid ACL: duplicate entry %s", entry)
+		}
+		if (entry.Type == AclMask || entry.Type == AclOther) && entry.Name != "" {
+			return 0, nil, fmt.Errorf("invalid ACL: %s entries have no name", entry.Type)
+		}
+		byKey[entry.key()] = entry
+	}
+
+	hasDefault := false
+	for _, entry := range entries {
+		if entry.Scope == AclScopeDefault {
+			hasDefault = true
+		}
+	}
+	for _, entryType := range []AclEntryType{AclUser, AclGroup, AclOther} {
+		access, exists := byKey[aclKey{AclScopeAccess, entryType, ""}]
+		if !exists {
+			return 0, nil, fmt.Errorf("invalid ACL: missing %s:: entry", entryType)
+		}
+		if _, exists := byKey[aclKey{AclScopeDefault, entryType, ""}]; hasDefault && !exists {
+			access.Scope = AclScopeDefault
+			byKey[access.key()] = access
+		}
+	}
+
+	scopes := []AclEntryScope{AclScopeAccess}
+	if hasDefault {
+		scopes = append(scopes, AclScopeDefault)
+	}
+	for _, scope := range scopes {
+		maskKey := aclKey{scope, AclMask, ""}
+		if _, exists := byKey[maskKey]; exists {
+			continue
+		}
+		named := false
+		var union FsAction
+		for key, entry := range byKey {
+			if key.scope != scope {
+				continue
+			}
+			if key.name != "" {
+				named = true
+			}
+			if key.entryType == AclGroup || key.name != "" {
+				union |= entry.Permission
+			}
+		}
+		if named {
+			byKey[maskKey] = AclEntry{Scope: scope, Type: AclMask, Permission: union}
+		}
+	}
+
+	owner := byKey[aclKey{AclScopeAccess, AclUser, ""}].Permission
+	group := byKey[aclKey{AclScopeAccess, AclGroup, ""}].Permission
+	other := byKey[aclKey{AclScopeAccess, AclOther, ""}].Permission
+	mask, extended := byKey[aclKey{AclScopeAccess, AclMask, ""}]
+	if extended {
+		group = mask.Permission
+	}
+	permission = permission&StickyBit | Permission(owner)<<6 | Permission(group)<<3 | Permission(other)
+
+	var acl []AclEntry
+	for key, entry := range byKey {
+		switch {
+		case key.scope == AclScopeDefault:
+			acl = append(acl, entry)
+		case !extended:
+		case key.entryType == AclGroup || key.entryType == AclUser && key.name != "":
+			acl = append(acl, entry)
+		}
+	}
+	sortAcl(acl)
+	return permission, acl, nil
+}
+
+// InheritAcl returns the permission and ACL of an inode created with mode in
+// a directory with the default ACL parentAcl. The default ACL takes the place
+// of the umask: mode only limits the owner, mask and other entries copied
+// from it. Directories also inherit the default ACL itself.
+func InheritAcl(mode Permission, parentAcl []AclEntry, isDir bool) (Permission, []AclEntry) {
+	defaults := DefaultEntries(parentAcl)
+	hasMask := false
+	for _, entry := range defaults {
+		if entry.Type == AclMask {
+			hasMask = true
+		}
+	}
+
+	var entries []AclEntry
+	for _, entry := range defaults {
+		inherited := entry
+		inherited.Scope = AclScopeAccess
+		switch {
+		case entry.Type == AclUser && entry.Name == "":
+			inherited.Permission &= mode.Owner()
+		case entry.Type == AclMask, entry.Type == AclGroup && entry.Name == "" && !hasMask:
+			inherited.Permission &= mode.Group()
+		case entry.Type == AclOther:
+			inherited.Permission &= mode.Other()
+		}
+		entries = append(entries, inherited)
+		if isDir {
+			entries = append(entries, entry)
+		}
+	}
+
+	// The default ACL was validated when it was set, so this cannot fail
+	permission, acl, _ := ApplyAcl(0, entries)
+	return permission, acl
+}
+
+// AccessEntries returns the entries of acl checked on access
+func AccessEntries(acl []AclEntry) []AclEntry {
+	return entriesInScope(acl, AclScopeAccess)
+}
+
+// DefaultEntries returns the entries of acl inherited by new children
+func DefaultEntries(acl []AclEntry) []AclEntry {
+	return entriesInScope(acl, AclScopeDefault)
+}
+
+func entriesInScope(acl []AclEntry, scope AclEntryScope) []AclEntry {
+	var entries []AclEntry
+	for _, entry := range acl {
+		if entry.Scope == scope {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// sortAcl orders entries as getfacl lists them: access before default, then
+// user, group, mask and other, with the unnamed entry of each type first
+func sortAcl(entries []AclEntry) {
+	typeOrder := map[AclEntryType]int{AclUser: 0, AclGroup: 1, AclMask: 2, AclOther: 3}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Scope != b.Scope {
+			return a.Scope == AclScopeAccess
+		}
+		if a.Type != b.Type {
+			return typeOrder[a.Type] < typeOrder[b.Type]
+		}
+		return a.Name < b.Name
+	})
+}
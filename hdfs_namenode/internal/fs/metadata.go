@@ -9,12 +9,31 @@ type Inode struct {
 	Size      int64
 	Blocks    []BlockAssignment
 	Timestamp time.Time
+	// Replication is the number of replicas kept of each block of a file
+	Replication int
+	// Owner, Group and Permission control who may access the inode
+	Owner      string
+	Group      string
+	Permission Permission
+	// Acl holds the extended ACL entries of the inode, if any
+	Acl []AclEntry `json:",omitempty"`
+
+	// shared is set once a snapshot refers to the file, see Snapshot
+	shared bool
 }
 
 type Directory struct {
 	Inode      *Inode
 	ChildFiles map[string]*Inode
 	ChildDirs  map[string]*Directory
+	// Quota bounds the content of the directory, if set
+	Quota *Quota
+	// Snapshottable directories may have snapshots taken, kept by name
+	Snapshottable bool
+	Snapshots     map[string]*Snapshot
+
+	// shared is set once a snapshot refers to the directory, see Snapshot
+	shared bool
 }
 
 type File struct {
@@ -0,0 +1,122 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrPermissionDenied is returned when a user may not access an inode
+var ErrPermissionDenied = errors.New("permission denied")
+
+// FsAction is a combination of read, write and execute access
+type FsAction uint16
+
+const (
+	ActionNone    FsAction = 0
+	ActionExecute FsAction = 1
+	ActionWrite   FsAction = 2
+	ActionRead    FsAction = 4
+	ActionAll              = ActionRead | ActionWrite | ActionExecute
+)
+
+// String names an action the way HDFS does, e.g. READ_EXECUTE
+func (a FsAction) String() string {
+	if a == ActionNone {
+		return "NONE"
+	}
+	if a == ActionAll {
+		return "ALL"
+	}
+	var names []string
+	for _, action := range []struct {
+		action FsAction
+		name   string
+	}{{ActionRead, "READ"}, {ActionWrite, "WRITE"}, {ActionExecute, "EXECUTE"}} {
+		if a&action.action != 0 {
+			names = append(names, action.name)
+		}
+	}
+	return strings.Join(names, "_")
+}
+
+// Permission holds the POSIX permission bits of an inode: read, write and
+// execute for its owner, its group and everyone else, plus the sticky bit
+type Permission uint16
+
+const (
+	// StickyBit on a directory lets only the owners of an entry, or of the
+	// directory, delete or rename it
+	StickyBit Permission = 01000
+
+	// DefaultFilePermission and DefaultDirPermission are masked by the umask
+	// when an inode is created
+	DefaultFilePermission Permission = 0666
+	DefaultDirPermission  Permission = 0777
+)
+
+// ParsePermission parses an octal permission such as "755" or "1777"
+func ParsePermission(value string) (Permission, error) {
+	parsed, err := strconv.ParseUint(value, 8, 16)
+	if err != nil || parsed > 01777 {
+		return 0, fmt.Errorf("invalid permission %q", value)
+	}
+	return Permission(parsed), nil
+}
+
+// Owner returns the actions granted to the owner of the inode
+func (p Permission) Owner() FsAction { return FsAction(p>>6) & ActionAll }
+
+// Group returns the actions granted to members of the inode's group
+func (p Permission) Group() FsAction { return FsAction(p>>3) & ActionAll }
+
+// Other returns the actions granted to everyone else
+func (p Permission) Other() FsAction { return FsAction(p) & ActionAll }
+
+// Sticky reports whether the sticky bit is set
+func (p Permission) Sticky() bool { return p&StickyBit != 0 }
+
+// String formats the permission like ls, e.g. rwxr-xr-x or rwxrwxrwt
+func (p Permission) String() string {
+	var b strings.Builder
+	for _, action := range []FsAction{p.Owner(), p.Group(), p.Other()} {
+		b.WriteByte("-r"[btoi(action&ActionRead != 0)])
+		b.WriteByte("-w"[btoi(action&ActionWrite != 0)])
+		b.WriteByte("-x"[btoi(action&ActionExecute != 0)])
+	}
+	s := []byte(b.String())
+	if p.Sticky() {
+		if s[8] == 'x' {
+			s[8] = 't'
+		} else {
+			s[8] = 'T'
+		}
+	}
+	return string(s)
+}
+
+// MarshalJSON encodes the permission as an octal string, as WebHDFS does
+func (p Permission) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.FormatUint(uint64(p), 8))), nil
+}
+
+func (p *Permission) UnmarshalJSON(data []byte) error {
+	value, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid permission %s", data)
+	}
+	parsed, err := ParsePermission(value)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,168 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrQuotaExceeded is returned when an operation would take a directory over
+// its quota
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Quota bounds the content of a directory subtree and tracks how much of it is
+// used. The usage is kept up to date as the subtree changes, so checking a
+// quota never walks the subtree.
+type Quota struct {
+	// NamespaceQuota is the maximum number of files and directories in the
+	// subtree, the directory itself included; 0 for no limit
+	NamespaceQuota int64
+	// SpaceQuota is the maximum number of bytes stored by the files of the
+	// subtree, counting every replica; 0 for no limit
+	SpaceQuota int64
+
+	NamespaceUsed int64
+	SpaceUsed     int64
+}
+
+// Usage counts the inodes and bytes, replicas included, of a subtree
+type Usage struct {
+	Namespace int64
+	Space     int64
+}
+
+// Add returns the sum of two usages
+func (u Usage) Add(other Usage) Usage {
+	return Usage{u.Namespace + other.Namespace, u.Space + other.Space}
+}
+
+// Negate returns the usage released when u is removed
+func (u Usage) Negate() Usage {
+	return Usage{-u.Namespace, -u.Space}
+}
+
+// SpaceConsumed returns the bytes stored for a file, counting every replica.
+// Files written before replication was recorded count once.
+func (i *Inode) SpaceConsumed() int64 {
+	return i.Size * int64(max(i.Replication, 1))
+}
+
+// FileUsage returns the usage of a single file
+func FileUsage(inode *Inode) Usage {
+	return Usage{Namespace: 1, Space: inode.SpaceConsumed()}
+}
+
+// SubtreeUsage returns the usage of dir and everything below it
+func SubtreeUsage(dir *Directory) Usage {
+	usage := Usage{Namespace: 1}
+	for _, inode := range dir.ChildFiles {
+		usage = usage.Add(FileUsage(inode))
+	}
+	for _, child := range dir.ChildDirs {
+		usage = usage.Add(SubtreeUsage(child))
+	}
+	return usage
+}
+
+// UpdateQuotaUsage recalculates the usage of every quota under dir, such as
+// after the namespace is loaded
+func UpdateQuotaUsage(dir *Directory) Usage {
+	usage := Usage{Namespace: 1}
+	for _, inode := range dir.ChildFiles {
+		usage = usage.Add(FileUsage(inode))
+	}
+	for _, child := range dir.ChildDirs {
+		usage = usage.Add(UpdateQuotaUsage(child))
+	}
+	if dir.Quota != nil {
+		dir.Quota.NamespaceUsed = usage.Namespace
+		dir.Quota.SpaceUsed = usage.Space
+	}
+	return usage
+}
+
+// AddUsage adds delta to the usage of the quotas of the directory at dirPath
+// and of every directory above it. Directories shared with a snapshot are
+// copied first.
+func AddUsage(root *Directory, dirPath string, delta Usage) {
+	dir := root
+	for _, name := range splitPath(dirPath) {
+		addQuotaUsage(dir, delta)
+		if dir = MutableDirectory(dir, name); dir == nil {
+			return
+		}
+	}
+	addQuotaUsage(dir, delta)
+}
+
+func addQuotaUsage(dir *Directory, delta Usage) {
+	if dir.Quota != nil {
+		dir.Quota.NamespaceUsed += delta.Namespace
+		dir.Quota.SpaceUsed += delta.Space
+	}
+}
+
+// VerifyQuota checks that adding delta below the directory at dirPath keeps it
+// and every directory above it within their quotas. pending is the usage not
+// tracked yet, such as files still being written, of each directory.
+func VerifyQuota(root *Directory, dirPath string, delta Usage, pending func(dirPath string) Usage) error {
+	current := "/"
+	for i, dir := range pathDirectories(root, dirPath) {
+		if i > 0 {
+			current = filepath.Join(current, dir.Inode.Name)
+		}
+		quota := dir.Quota
+		if quota == nil {
+			continue
+		}
+		extra := delta
+		if pending != nil {
+			extra = extra.Add(pending(current))
+		}
+		if delta.Namespace > 0 && quota.NamespaceQuota > 0 && quota.NamespaceUsed+extra.Namespace > quota.NamespaceQuota {
+			return fmt.Errorf("%w: the namespace quota of %s is %d, %d are used",
+				ErrQuotaExceeded, current, quota.NamespaceQuota, quota.NamespaceUsed)
+		}
+		if delta.Space > 0 && quota.SpaceQuota > 0 && quota.SpaceUsed+extra.Space > quota.SpaceQuota {
+			return fmt.Errorf("%w: the space quota of %s is %d bytes, %d are used",
+				ErrQuotaExceeded, current, quota.SpaceQuota, quota.SpaceUsed)
+		}
+	}
+	return nil
+}
+
+// pathDirectories returns root and each directory down to the one at dirPath,
+// stopping at the first that does not exist
+func pathDirectories(root *Directory, dirPath string) []*Directory {
+	dirs := []*Directory{root}
+	dir := root
+	for _, name := range strings.Split(strings.Trim(filepath.Clean(dirPath), "/"), "/") {
+		if name == "" {
+			continue
+		}
+		next, exists := dir.ChildDirs[name]
+		if !exists {
+			break
+		}
+		dir = next
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// ContentSummary describes the content of a file or directory subtree
+type ContentSummary struct {
+	// Length is the total size of the files
+	Length         int64 `json:"length"`
+	FileCount      int64 `json:"fileCount"`
+	DirectoryCount int64 `json:"directoryCount"`
+	// SpaceConsumed is the bytes stored for the files, counting every replica
+	SpaceConsumed int64 `json:"spaceConsumed"`
+	// NamespaceConsumed is the number of files and directories, as counted
+	// against namespace quotas
+	NamespaceConsumed int64 `json:"namespaceConsumed"`
+	// NamespaceQuota and SpaceQuota are those of the directory, 0 if it has none
+	NamespaceQuota int64 `json:"namespaceQuota"`
+	SpaceQuota     int64 `json:"spaceQuota"`
+}
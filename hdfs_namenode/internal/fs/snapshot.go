@@ -0,0 +1,463 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotDirName is the reserved name under which the snapshots of a
+// snapshottable directory are read, as in /data/.snapshot/<name>/file
+const SnapshotDirName = ".snapshot"
+
+// ErrSnapshotReadOnly is returned when an operation would change a snapshot
+var ErrSnapshotReadOnly = errors.New("snapshots are read-only")
+
+// Snapshot is a read-only, point-in-time view of a directory subtree. Its
+// files share their blocks with the live files they were taken from, which are
+// kept on the DataNodes as long as either needs them.
+//
+// Snapshots are copy-on-write: a snapshot shares the directories and files
+// below the snapshotted directory with the live namespace, which are marked
+// shared. A shared directory or file is copied the first time it changes, and
+// the copy takes its place in the live namespace, so only what changed since
+// a snapshot is ever duplicated. Changes to the live namespace must reach
+// what they change through MutableDirectory, MutableFile or MutableInode.
+type Snapshot struct {
+	Name      string
+	Timestamp time.Time
+	// Root is the copy of the snapshotted directory, named after the snapshot
+	Root *Directory
+}
+
+// IsSnapshotPath reports whether path goes through a .snapshot directory, or
+// would otherwise use the reserved name
+func IsSnapshotPath(path string) bool {
+	for _, name := range strings.Split(filepath.ToSlash(path), "/") {
+		if name == SnapshotDirName {
+			return true
+		}
+	}
+	return false
+}
+
+// childDirectory returns the directory that the first of parts names under
+// dir, and how many of parts name it: .snapshot/<name> resolves to the root of
+// a snapshot of dir in two parts
+func childDirectory(dir *Directory, parts []string) (*Directory, int) {
+	if parts[0] == SnapshotDirName {
+		if len(parts) < 2 {
+			return nil, 1
+		}
+		snapshot, exists := dir.Snapshots[parts[1]]
+		if !exists {
+			return nil, 2
+		}
+		return snapshot.Root, 2
+	}
+	return dir.ChildDirs[parts[0]], 1
+}
+
+// WalkDirectories calls visit for the directory at each step from root to
+// dirPath, snapshot roots included. It stops at the first missing directory,
+// or at the first error returned by visit.
+func WalkDirectories(root *Directory, dirPath string, visit func(path string, dir *Directory) error) error {
+	current := "/"
+	if err := visit(current, root); err != nil {
+		return err
+	}
+	parts := splitPath(dirPath)
+	dir := root
+	for len(parts) > 0 {
+		next, consumed := childDirectory(dir, parts)
+		if next == nil {
+			return nil
+		}
+		current = filepath.Join(append([]string{current}, parts[:consumed]...)...)
+		parts = parts[consumed:]
+		dir = next
+		if err := visit(current, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitPath(path string) []string {
+	var parts []string
+	for _, name := range strings.Split(strings.Trim(filepath.Clean(path), "/"), "/") {
+		if name != "" {
+			parts = append(parts, name)
+		}
+	}
+	return parts
+}
+
+// CreateSnapshot records a snapshot of dir under name. The snapshot shares
+// everything below dir with the live namespace, so taking one only copies the
+// metadata of dir itself.
+func CreateSnapshot(dir *Directory, name string, timestamp time.Time) (*Snapshot, error) {
+	if !dir.Snapshottable {
+		return nil, fmt.Errorf("directory is not snapshottable")
+	}
+	if err := checkSnapshotName(name); err != nil {
+		return nil, err
+	}
+	if _, exists := dir.Snapshots[name]; exists {
+		return nil, fmt.Errorf("snapshot %s already exists", name)
+	}
+
+	root := &Directory{
+		Inode:      copyInode(dir.Inode),
+		ChildFiles: maps.Clone(dir.ChildFiles),
+		ChildDirs:  maps.Clone(dir.ChildDirs),
+	}
+	shareChildren(root)
+	root.Inode.Name = name
+	root.Inode.Timestamp = timestamp
+	snapshot := &Snapshot{Name: name, Timestamp: timestamp, Root: root}
+	if dir.Snapshots == nil {
+		dir.Snapshots = make(map[string]*Snapshot)
+	}
+	dir.Snapshots[name] = snapshot
+	return snapshot, nil
+}
+
+// DeleteSnapshot removes the snapshot name of dir and returns it
+func DeleteSnapshot(dir *Directory, name string) (*Snapshot, error) {
+	snapshot, exists := dir.Snapshots[name]
+	if !exists {
+		return nil, fmt.Errorf("snapshot %s %w", name, ErrNotFound)
+	}
+	delete(dir.Snapshots, name)
+	return snapshot, nil
+}
+
+// RenameSnapshot renames the snapshot oldName of dir to newName
+func RenameSnapshot(dir *Directory, oldName, newName string) error {
+	snapshot, exists := dir.Snapshots[oldName]
+	if !exists {
+		return fmt.Errorf("snapshot %s %w", oldName, ErrNotFound)
+	}
+	if err := checkSnapshotName(newName); err != nil {
+		return err
+	}
+	if oldName == newName {
+		return nil
+	}
+	if _, exists := dir.Snapshots[newName]; exists {
+		return fmt.Errorf("snapshot %s already exists", newName)
+	}
+	delete(dir.Snapshots, oldName)
+	snapshot.Name = newName
+	snapshot.Root.Inode.Name = newName
+	dir.Snapshots[newName] = snapshot
+	return nil
+}
+
+func checkSnapshotName(name string) error {
+	if name == "" || name == "." || name == ".." || name == SnapshotDirName || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid snapshot name %q", name)
+	}
+	return nil
+}
+
+// HasSnapshots reports whether dir or any directory below it has snapshots
+func HasSnapshots(dir *Directory) bool {
+	if len(dir.Snapshots) > 0 {
+		return true
+	}
+	for _, child := range dir.ChildDirs {
+		if HasSnapshots(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// MutableDirectory returns the live directory at path for a change, or nil if
+// there is none. Directories on the way shared with a snapshot are copied
+// first. Paths into snapshots are not followed.
+func MutableDirectory(root *Directory, path string) *Directory {
+	dir := root
+	for _, name := range splitPath(path) {
+		child, exists := dir.ChildDirs[name]
+		if !exists {
+			return nil
+		}
+		if child.shared {
+			child = unshare(child)
+			dir.ChildDirs[name] = child
+		}
+		dir = child
+	}
+	return dir
+}
+
+// MutableFile returns the file name of dir for a change, copying it first if
+// it is shared with a snapshot, or nil if there is none. dir must have been
+// returned by MutableDirectory.
+func MutableFile(dir *Directory, name string) *Inode {
+	inode, exists := dir.ChildFiles[name]
+	if !exists {
+		return nil
+	}
+	if inode.shared {
+		inode = copyInode(inode)
+		dir.ChildFiles[name] = inode
+	}
+	return inode
+}
+
+// MutableInode returns the inode of the live file or directory at path for a
+// change, as Lookup does, or nil if there is none
+func MutableInode(root *Directory, path string) *Inode {
+	path = filepath.Clean(path)
+	if path == "/" {
+		return root.Inode
+	}
+	parent := MutableDirectory(root, filepath.Dir(path))
+	if parent == nil {
+		return nil
+	}
+	name := filepath.Base(path)
+	if inode := MutableFile(parent, name); inode != nil {
+		return inode
+	}
+	if dir := MutableDirectory(parent, name); dir != nil {
+		return dir.Inode
+	}
+	return nil
+}
+
+// ShareDirectory marks dir as shared with a snapshot, such as when the
+// namespace is loaded along with its snapshots
+func ShareDirectory(dir *Directory) {
+	dir.shared = true
+}
+
+// ShareFile marks a file as shared with a snapshot
+func ShareFile(inode *Inode) {
+	inode.shared = true
+}
+
+// unshare returns a copy of a shared directory for the live namespace. The
+// copy shares the children of the directory with it in turn.
+func unshare(dir *Directory) *Directory {
+	copied := &Directory{
+		Inode:         copyInode(dir.Inode),
+		ChildFiles:    maps.Clone(dir.ChildFiles),
+		ChildDirs:     maps.Clone(dir.ChildDirs),
+		Snapshottable: dir.Snapshottable,
+		Snapshots:     maps.Clone(dir.Snapshots),
+	}
+	// The directory keeps the quota it had when the snapshot was taken
+	if dir.Quota != nil {
+		quota := *dir.Quota
+		copied.Quota = &quota
+	}
+	shareChildren(copied)
+	return copied
+}
+
+func shareChildren(dir *Directory) {
+	for _, inode := range dir.ChildFiles {
+		inode.shared = true
+	}
+	for _, child := range dir.ChildDirs {
+		child.shared = true
+	}
+}
+
+// CopyNamespace returns a copy of the namespace under root that later changes
+// to it leave untouched, quotas and snapshots included. Directories and files
+// shared by snapshots and the live namespace are copied once, and stay shared
+// in the copy.
+func CopyNamespace(root *Directory) *Directory {
+	c := &namespaceCopier{dirs: make(map[*Directory]*Directory), files: make(map[*Inode]*Inode)}
+	return c.directory(root)
+}
+
+// namespaceCopier copies a namespace, remembering the copy of each directory
+// and file
+type namespaceCopier struct {
+	dirs  map[*Directory]*Directory
+	files map[*Inode]*Inode
+}
+
+func (c *namespaceCopier) directory(dir *Directory) *Directory {
+	if copied, exists := c.dirs[dir]; exists {
+		return copied
+	}
+	copied := &Directory{
+		Inode:         copyInode(dir.Inode),
+		ChildFiles:    make(map[string]*Inode, len(dir.ChildFiles)),
+		ChildDirs:     make(map[string]*Directory, len(dir.ChildDirs)),
+		Snapshottable: dir.Snapshottable,
+	}
+	c.dirs[dir] = copied
+	if dir.Quota != nil {
+		quota := *dir.Quota
+		copied.Quota = &quota
+	}
+	for name, inode := range dir.ChildFiles {
+		copied.ChildFiles[name] = c.file(inode)
+	}
+	for name, child := range dir.ChildDirs {
+		copied.ChildDirs[name] = c.directory(child)
+	}
+	if dir.Snapshots != nil {
+		copied.Snapshots = make(map[string]*Snapshot, len(dir.Snapshots))
+		for name, snapshot := range dir.Snapshots {
+			copied.Snapshots[name] = &Snapshot{Name: snapshot.Name, Timestamp: snapshot.Timestamp, Root: c.directory(snapshot.Root)}
+		}
+	}
+	return copied
+}
+
+func (c *namespaceCopier) file(inode *Inode) *Inode {
+	if copied, exists := c.files[inode]; exists {
+		return copied
+	}
+	copied := copyInode(inode)
+	c.files[inode] = copied
+	return copied
+}
+
+// copyInode copies inode along with its ACL and block list, so a copy is not
+// changed by an append to the file or its replicas being updated
+func copyInode(inode *Inode) *Inode {
+	copied := *inode
+	copied.shared = false
+	copied.Acl = append([]AclEntry(nil), inode.Acl...)
+	copied.Blocks = slices.Clone(inode.Blocks)
+	for i := range copied.Blocks {
+		copied.Blocks[i].DataNodeAddresses = slices.Clone(inode.Blocks[i].DataNodeAddresses)
+	}
+	return &copied
+}
+
+// DiffType tells how an entry differs between two snapshots, using the
+// symbols of hdfs snapshotDiff
+type DiffType string
+
+const (
+	DiffCreate DiffType = "+"
+	DiffDelete DiffType = "-"
+	DiffModify DiffType = "M"
+	DiffRename DiffType = "R"
+)
+
+// DiffEntry is an entry of a snapshot diff report. Paths are relative to the
+// snapshotted directory; Target is the new path of a renamed entry.
+type DiffEntry struct {
+	Type   DiffType `json:"type"`
+	Path   string   `json:"path"`
+	Target string   `json:"target,omitempty"`
+}
+
+// SnapshotDiff lists the changes from the tree from to the tree to. Entries
+// are matched by inode ID, so a moved entry is reported as renamed rather than
+// deleted and created again. A directory is modified when its own metadata or
+// its list of children changed.
+func SnapshotDiff(from, to *Directory) []DiffEntry {
+	type located struct {
+		path  string
+		inode *Inode
+		dir   *Directory
+	}
+	index := func(root *Directory) map[int64]located {
+		entries := make(map[int64]located)
+		var walk func(path string, dir *Directory)
+		walk = func(path string, dir *Directory) {
+			entries[dir.Inode.ID] = located{path, dir.Inode, dir}
+			for name, inode := range dir.ChildFiles {
+				entries[inode.ID] = located{filepath.Join(path, name), inode, nil}
+			}
+			for name, child := range dir.ChildDirs {
+				walk(filepath.Join(path, name), child)
+			}
+		}
+		walk(".", root)
+		return entries
+	}
+	before, after := index(from), index(to)
+
+	var diff []DiffEntry
+	for id, old := range before {
+		current, exists := after[id]
+		switch {
+		case !exists:
+			diff = append(diff, DiffEntry{Type: DiffDelete, Path: old.path})
+		case old.path != current.path:
+			diff = append(diff, DiffEntry{Type: DiffRename, Path: old.path, Target: current.path})
+		case modified(old.inode, current.inode) || childrenChanged(old.dir, current.dir):
+			diff = append(diff, DiffEntry{Type: DiffModify, Path: old.path})
+		}
+	}
+	for id, current := range after {
+		if _, exists := before[id]; !exists {
+			diff = append(diff, DiffEntry{Type: DiffCreate, Path: current.path})
+		}
+	}
+
+	sort.Slice(diff, func(i, j int) bool {
+		if diff[i].Path != diff[j].Path {
+			return diff[i].Path < diff[j].Path
+		}
+		return diff[i].Type < diff[j].Type
+	})
+	return diff
+}
+
+// modified reports whether the metadata of an inode changed, ignoring its name
+// (the root of a snapshot is named after the snapshot)
+func modified(old, current *Inode) bool {
+	return old.Size != current.Size || old.Replication != current.Replication ||
+		old.Owner != current.Owner || old.Group != current.Group ||
+		old.Permission != current.Permission || fmt.Sprint(old.Acl) != fmt.Sprint(current.Acl)
+}
+
+func childrenChanged(old, current *Directory) bool {
+	if old == nil || current == nil {
+		return false
+	}
+	if len(old.ChildFiles) != len(current.ChildFiles) || len(old.ChildDirs) != len(current.ChildDirs) {
+		return true
+	}
+	for name, inode := range old.ChildFiles {
+		if other, exists := current.ChildFiles[name]; !exists || other.ID != inode.ID {
+			return true
+		}
+	}
+	for name, child := range old.ChildDirs {
+		if other, exists := current.ChildDirs[name]; !exists || other.Inode.ID != child.Inode.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// WalkBlocks calls visit for every block of the files under dir
+func WalkBlocks(dir *Directory, visit func(BlockAssignment)) {
+	WalkFiles(dir, func(inode *Inode) {
+		for _, block := range inode.Blocks {
+			visit(block)
+		}
+	})
+}
+
+// WalkSnapshots calls visit for every snapshot of dir and the directories below it
+func WalkSnapshots(dir *Directory, visit func(*Snapshot)) {
+	for _, snapshot := range dir.Snapshots {
+		visit(snapshot)
+	}
+	for _, child := range dir.ChildDirs {
+		WalkSnapshots(child, visit)
+	}
+}
@@ -1,39 +1,153 @@
 package fs
 
 import (
-	"os"
+	"errors"
+	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
 )
 
-func FindDirectory(root *Directory, path string) *Directory {
-	// Handle special case where the path is the root directory
-	if path == "/" || path == "\\" || path == "" {
-		return root
-	}
-
-	// Clean up the path and split it into components
-	cleanedPath := filepath.Clean(path)[1:]
-	parts := strings.Split(cleanedPath, string(os.PathSeparator))
+// ErrNotFound is returned when a path names no file or directory, and is
+// worded to follow what is missing, e.g. "file does not exist"
+var ErrNotFound = errors.New("does not exist")
 
-	// Start from the root directory
+// FindDirectory returns the directory at path, or nil if there is none. Paths
+// through .snapshot/<name> resolve into the snapshots of a directory.
+func FindDirectory(root *Directory, path string) *Directory {
+	parts := splitPath(path)
 	currentDir := root
 
 	// Traverse the directory tree
-	for _, part := range parts {
-		if nextDir, ok := currentDir.ChildDirs[part]; ok {
-			// Move to the next directory in the path
-			currentDir = nextDir
-		} else {
+	for len(parts) > 0 {
+		nextDir, consumed := childDirectory(currentDir, parts)
+		if nextDir == nil {
 			// Directory not found in the path
 			return nil
 		}
+		currentDir = nextDir
+		parts = parts[consumed:]
 	}
 
 	return currentDir
 }
 
+// Lookup returns the inode of the file or directory at path, or nil if there
+// is none
+func Lookup(root *Directory, path string) *Inode {
+	path = filepath.Clean(path)
+	if path == "/" {
+		return root.Inode
+	}
+	parent := FindDirectory(root, filepath.Dir(path))
+	if parent == nil {
+		// The root of a snapshot, at <dir>/.snapshot/<name>
+		if filepath.Base(filepath.Dir(path)) == SnapshotDirName {
+			if dir := FindDirectory(root, path); dir != nil {
+				return dir.Inode
+			}
+		}
+		return nil
+	}
+	name := filepath.Base(path)
+	if inode, exists := parent.ChildFiles[name]; exists {
+		return inode
+	}
+	if dir, exists := parent.ChildDirs[name]; exists {
+		return dir.Inode
+	}
+	return nil
+}
+
+// WalkFiles calls visit for every file under dir, including its subdirectories
+func WalkFiles(dir *Directory, visit func(*Inode)) {
+	for _, inode := range dir.ChildFiles {
+		visit(inode)
+	}
+	for _, child := range dir.ChildDirs {
+		WalkFiles(child, visit)
+	}
+}
+
 func GenerateInodeID() int64 {
 	return time.Now().UnixNano()
 }
+
+// Rename moves the file or directory at srcPath to dstPath, renaming its inode.
+// An existing destination of the same kind is replaced if overwrite is set, as
+// long as it is a file or an empty directory. It returns the replaced file, if
+// any, so the caller can release its blocks.
+func Rename(root *Directory, srcPath, dstPath string, overwrite bool) (*Inode, error) {
+	move, err := PrepareRename(root, srcPath, dstPath, overwrite)
+	if err != nil {
+		return nil, err
+	}
+	return move(), nil
+}
+
+// PrepareRename checks that Rename can move srcPath to dstPath, without
+// changing anything, and returns the function that moves it and returns the
+// replaced file. The move can't fail, so callers can persist it in between.
+func PrepareRename(root *Directory, srcPath, dstPath string, overwrite bool) (func() *Inode, error) {
+	srcPath, dstPath = filepath.Clean(srcPath), filepath.Clean(dstPath)
+	if !filepath.IsAbs(srcPath) || !filepath.IsAbs(dstPath) {
+		return nil, fmt.Errorf("paths must be absolute")
+	}
+	if srcPath == "/" || dstPath == "/" {
+		return nil, fmt.Errorf("cannot rename the root directory")
+	}
+	if strings.HasPrefix(dstPath, srcPath+"/") {
+		return nil, fmt.Errorf("cannot move %s into itself", srcPath)
+	}
+
+	srcParent := FindDirectory(root, filepath.Dir(srcPath))
+	if srcParent == nil {
+		return nil, fmt.Errorf("source %w", ErrNotFound)
+	}
+	srcName := filepath.Base(srcPath)
+	_, isFile := srcParent.ChildFiles[srcName]
+	_, isDir := srcParent.ChildDirs[srcName]
+	if !isFile && !isDir {
+		return nil, fmt.Errorf("source %w", ErrNotFound)
+	}
+
+	dstParent := FindDirectory(root, filepath.Dir(dstPath))
+	if dstParent == nil {
+		return nil, fmt.Errorf("destination parent directory %w", ErrNotFound)
+	}
+	if srcPath == dstPath {
+		return func() *Inode { return nil }, nil
+	}
+
+	dstName := filepath.Base(dstPath)
+	dstFile, dstIsFile := dstParent.ChildFiles[dstName]
+	dstDir, dstIsDir := dstParent.ChildDirs[dstName]
+	if dstIsFile || dstIsDir {
+		switch {
+		case !overwrite:
+			return nil, fmt.Errorf("destination already exists")
+		case isFile && dstIsDir, isDir && dstIsFile:
+			return nil, fmt.Errorf("cannot replace a file with a directory or a directory with a file")
+		case dstIsDir && (len(dstDir.ChildFiles) > 0 || len(dstDir.ChildDirs) > 0):
+			return nil, fmt.Errorf("destination directory is not empty")
+		}
+	}
+
+	return func() *Inode {
+		// What a snapshot shares is copied before it is moved or renamed
+		srcParent := MutableDirectory(root, filepath.Dir(srcPath))
+		dstParent := MutableDirectory(root, filepath.Dir(dstPath))
+		if isFile {
+			moved := MutableFile(srcParent, srcName)
+			delete(srcParent.ChildFiles, srcName)
+			moved.Name = dstName
+			dstParent.ChildFiles[dstName] = moved
+			return dstFile
+		}
+		moved := MutableDirectory(srcParent, srcName)
+		delete(srcParent.ChildDirs, srcName)
+		moved.Inode.Name = dstName
+		dstParent.ChildDirs[dstName] = moved
+		return nil
+	}, nil
+}
@@ -0,0 +1,268 @@
+package gRPC
+
+import (
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// BlockInfo is what the NameNode knows about a block of the namespace
+type BlockInfo struct {
+	BlockID string
+	// Replication is the number of replicas the block should have
+	Replication int
+	// Size is the length of the block as last reported by a DataNode
+	Size int64
+	// UnderConstruction blocks belong to a file still being written
+	UnderConstruction bool
+	// Locations are the DataNodes that reported a replica of the block
+	Locations []string
+}
+
+// blockEntry is the mutable state kept for each block
+type blockEntry struct {
+	replication       int
+	size              int64
+	underConstruction bool
+	locations         map[string]bool
+}
+
+func (e *blockEntry) info(blockID string) *BlockInfo {
+	return &BlockInfo{
+		BlockID:           blockID,
+		Replication:       e.replication,
+		Size:              e.size,
+		UnderConstruction: e.underConstruction,
+		Locations:         sortedKeys(e.locations),
+	}
+}
+
+// BlockMap maps every block of the namespace to the DataNodes that reported
+// storing a replica of it. Blocks are added when the namespace allocates or
+// loads them, and their locations come from the DataNodes' block reports;
+// reported blocks the namespace doesn't know are orphans.
+type BlockMap struct {
+	mu     sync.RWMutex
+	blocks map[string]*blockEntry
+	// Blocks reported by each DataNode, so its replicas can be dropped at once
+	nodeBlocks map[string]map[string]bool
+}
+
+var blockMapInstance *BlockMap
+var blockMapOnce sync.Once
+
+// GetBlockMap returns the singleton instance of BlockMap
+func GetBlockMap() *BlockMap {
+	blockMapOnce.Do(func() {
+		blockMapInstance = NewBlockMap()
+	})
+	return blockMapInstance
+}
+
+func NewBlockMap() *BlockMap {
+	return &BlockMap{
+		blocks:     make(map[string]*blockEntry),
+		nodeBlocks: make(map[string]map[string]bool),
+	}
+}
+
+// AddBlock registers a block of the namespace that should have replication
+// replicas. Locations already reported for it are kept.
+func (b *BlockMap) AddBlock(blockID string, replication int) {
+	b.addBlock(blockID, replication, false)
+}
+
+// AllocateBlock registers a block of a file being written. It is not checked
+// for missing replicas until CompleteBlock is called.
+func (b *BlockMap) AllocateBlock(blockID string, replication int) {
+	b.addBlock(blockID, replication, true)
+}
+
+// CompleteBlock marks a block whose file has been completed
+func (b *BlockMap) CompleteBlock(blockID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if entry, exists := b.blocks[blockID]; exists {
+		entry.underConstruction = false
+	}
+}
+
+func (b *BlockMap) addBlock(blockID string, replication int, underConstruction bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if entry, exists := b.blocks[blockID]; exists {
+		entry.replication = replication
+		entry.underConstruction = underConstruction
+		return
+	}
+	b.blocks[blockID] = &blockEntry{
+		replication:       replication,
+		underConstruction: underConstruction,
+		locations:         make(map[string]bool),
+	}
+}
+
+// RemoveBlock forgets a block that left the namespace and returns the
+// DataNodes that still hold a replica of it
+func (b *BlockMap) RemoveBlock(blockID string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry, exists := b.blocks[blockID]
+	if !exists {
+		return nil
+	}
+	delete(b.blocks, blockID)
+
+	locations := sortedKeys(entry.locations)
+	for _, address := range locations {
+		delete(b.nodeBlocks[address], blockID)
+	}
+	return locations
+}
+
+// GetBlock returns a snapshot of a block, or nil if it is not part of the namespace
+func (b *BlockMap) GetBlock(blockID string) *BlockInfo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	entry, exists := b.blocks[blockID]
+	if !exists {
+		return nil
+	}
+	return entry.info(blockID)
+}
+
+// Locations returns the DataNodes that reported a replica of a block
+func (b *BlockMap) Locations(blockID string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	entry, exists := b.blocks[blockID]
+	if !exists {
+		return nil
+	}
+	return sortedKeys(entry.locations)
+}
+
+// Blocks returns a snapshot of every block of the namespace
+func (b *BlockMap) Blocks() []BlockInfo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	blocks := make([]BlockInfo, 0, len(b.blocks))
+	for blockID, entry := range b.blocks {
+		blocks = append(blocks, *entry.info(blockID))
+	}
+	return blocks
+}
+
+// ProcessBlockReport replaces the replicas known on the DataNode at address
+// with the blocks of a full report. It returns the reported blocks that are
+// not part of the namespace.
+func (b *BlockMap) ProcessBlockReport(address string, reported []*protobuf.ReportedBlock) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Replicas missing from the report are gone
+	for blockID := range b.nodeBlocks[address] {
+		if entry, exists := b.blocks[blockID]; exists {
+			delete(entry.locations, address)
+		}
+	}
+	b.nodeBlocks[address] = make(map[string]bool, len(reported))
+
+	var orphans []string
+	for _, block := range reported {
+		if !b.addReplica(address, block) {
+			orphans = append(orphans, block.GetBlockId())
+		}
+	}
+
+	log.Printf("Block report from %s: %d blocks, %d orphaned", address, len(reported), len(orphans))
+	return orphans
+}
+
+// ProcessIncrementalReport records the blocks stored on and deleted from the
+// DataNode at address since its last report. It returns the received blocks
+// that are not part of the namespace.
+func (b *BlockMap) ProcessIncrementalReport(address string, received []*protobuf.ReportedBlock, deleted []string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, blockID := range deleted {
+		b.removeReplica(address, blockID)
+	}
+
+	var orphans []string
+	for _, block := range received {
+		if !b.addReplica(address, block) {
+			orphans = append(orphans, block.GetBlockId())
+		}
+	}
+	return orphans
+}
+
+// RemoveDataNode drops every replica held by a DataNode, e.g. once it is dead
+func (b *BlockMap) RemoveDataNode(address string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for blockID := range b.nodeBlocks[address] {
+		if entry, exists := b.blocks[blockID]; exists {
+			delete(entry.locations, address)
+		}
+	}
+	delete(b.nodeBlocks, address)
+}
+
+// TrackDataNodes drops the replicas of DataNodes as they die
+func (b *BlockMap) TrackDataNodes(dataNodeManager *DataNodeManager) {
+	events := dataNodeManager.Subscribe()
+	go func() {
+		for event := range events {
+			if event.To == NodeDead {
+				log.Printf("Dropping the replicas of dead DataNode %s", event.Address)
+				b.RemoveDataNode(event.Address)
+			}
+		}
+	}()
+}
+
+// addReplica records a replica on address, returning false if the block is
+// not part of the namespace. Must be called with b.mu held.
+func (b *BlockMap) addReplica(address string, block *protobuf.ReportedBlock) bool {
+	blockID := block.GetBlockId()
+	entry, exists := b.blocks[blockID]
+	if !exists {
+		return false
+	}
+	entry.locations[address] = true
+	entry.size = block.GetSize()
+	if b.nodeBlocks[address] == nil {
+		b.nodeBlocks[address] = make(map[string]bool)
+	}
+	b.nodeBlocks[address][blockID] = true
+	return true
+}
+
+// removeReplica forgets a replica on address. Must be called with b.mu held.
+func (b *BlockMap) removeReplica(address, blockID string) {
+	if entry, exists := b.blocks[blockID]; exists {
+		delete(entry.locations, address)
+	}
+	delete(b.nodeBlocks[address], blockID)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
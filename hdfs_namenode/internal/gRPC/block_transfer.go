@@ -0,0 +1,120 @@
+package gRPC
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// WriteBlock streams a block read from data to the DataNode in packets.
+// The DataNode forwards the block to targets, in order, and the response
+// carries the acks of the whole pipeline.
+func (c *NameNodeClient) WriteBlock(blockID string, targets []string, data io.Reader) (*protobuf.WriteBlockResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), blockTransferTimeout)
+	defer cancel()
+
+	stream, err := c.client.WriteBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &protobuf.WriteBlockRequest{
+		Payload: &protobuf.WriteBlockRequest_Header{Header: &protobuf.WriteBlockHeader{BlockId: blockID, Targets: targets}},
+	}
+	if err := stream.Send(header); err != nil {
+		return nil, closeAndRecvError(stream, err)
+	}
+
+	buf := make([]byte, protobuf.PacketSize)
+	seqno, offset := int64(0), int64(0)
+	for {
+		n, err := io.ReadFull(data, buf)
+		last := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if err != nil && !last {
+			return nil, fmt.Errorf("failed to read block %s: %w", blockID, err)
+		}
+
+		packet := &protobuf.BlockPacket{
+			Seqno:      seqno,
+			Offset:     offset,
+			Data:       buf[:n],
+			Checksum:   protobuf.PacketChecksum(buf[:n]),
+			LastPacket: last,
+		}
+		if err := stream.Send(&protobuf.WriteBlockRequest{Payload: &protobuf.WriteBlockRequest_Packet{Packet: packet}}); err != nil {
+			return nil, closeAndRecvError(stream, err)
+		}
+
+		if last {
+			break
+		}
+		seqno++
+		offset += int64(n)
+	}
+
+	response, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+	if !response.GetSuccess() {
+		return nil, fmt.Errorf("DataNode did not acknowledge block %s", blockID)
+	}
+	return response, nil
+}
+
+// closeAndRecvError surfaces the DataNode's status when Send fails, since the
+// error returned by Send itself is only io.EOF
+func closeAndRecvError(stream protobuf.DataNodeService_WriteBlockClient, sendErr error) error {
+	if sendErr != io.EOF {
+		return sendErr
+	}
+	_, err := stream.CloseAndRecv()
+	if err == nil {
+		return sendErr
+	}
+	return err
+}
+
+// ReadBlock streams a block from the DataNode into w starting at offset,
+// verifying every packet's checksum, and returns the number of bytes written
+func (c *NameNodeClient) ReadBlock(blockID string, offset int64, w io.Writer) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), blockTransferTimeout)
+	defer cancel()
+
+	stream, err := c.client.ReadBlock(ctx, &protobuf.ReadBlockRequest{BlockId: blockID, Offset: offset})
+	if err != nil {
+		return 0, err
+	}
+
+	var written int64
+	for seqno := int64(0); ; seqno++ {
+		response, err := stream.Recv()
+		if err == io.EOF {
+			return written, fmt.Errorf("stream for block %s ended before the last packet", blockID)
+		}
+		if err != nil {
+			return written, err
+		}
+
+		packet := response.GetPacket()
+		if packet.GetSeqno() != seqno || packet.GetOffset() != offset+written {
+			return written, fmt.Errorf("out of order packet %d for block %s", packet.GetSeqno(), blockID)
+		}
+		if protobuf.PacketChecksum(packet.GetData()) != packet.GetChecksum() {
+			return written, fmt.Errorf("checksum mismatch in packet %d of block %s", seqno, blockID)
+		}
+
+		n, err := w.Write(packet.GetData())
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+
+		if packet.GetLastPacket() {
+			return written, nil
+		}
+	}
+}
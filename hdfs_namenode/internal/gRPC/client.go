@@ -2,13 +2,19 @@ package gRPC
 
 import (
 	"context"
-	"log"
 	"time"
 
 	"github.com/aarrasseayoub01/namenode/protobuf"
 	"google.golang.org/grpc"
 )
 
+const (
+	// dialTimeout bounds how long we wait for a DataNode to accept a connection
+	dialTimeout = 5 * time.Second
+	// blockTransferTimeout bounds the transfer of a single block
+	blockTransferTimeout = 2 * time.Minute
+)
+
 // NameNodeClient is a client for interacting with the DataNode gRPC service
 type NameNodeClient struct {
 	conn   *grpc.ClientConn
@@ -17,7 +23,10 @@ type NameNodeClient struct {
 
 // NewNameNodeClient creates a new client for the DataNode service
 func NewNameNodeClient(dataNodeAddress string) (*NameNodeClient, error) {
-	conn, err := grpc.Dial(dataNodeAddress, grpc.WithInsecure(), grpc.WithBlock())
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, dataNodeAddress, grpc.WithInsecure(), grpc.WithBlock())
 	if err != nil {
 		return nil, err
 	}
@@ -25,23 +34,6 @@ func NewNameNodeClient(dataNodeAddress string) (*NameNodeClient, error) {
 	return &NameNodeClient{conn: conn, client: client}, nil
 }
 
-// StoreBlock sends a StoreBlock request to a DataNode
-func (c *NameNodeClient) StoreBlock(blockID string, blockData []byte) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-	defer cancel()
-
-	// Example: Sending a StoreBlockRequest to the DataNode
-	response, err := c.client.StoreBlock(ctx, &protobuf.StoreBlockRequest{
-		BlockId:   blockID,
-		BlockData: blockData,
-	})
-	if err != nil {
-		return err
-	}
-	log.Printf("StoreBlock response from DataNode: %v", response.GetSuccess())
-	return nil
-}
-
 // Close closes the client connection
 func (c *NameNodeClient) Close() {
 	c.conn.Close()
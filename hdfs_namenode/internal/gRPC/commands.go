@@ -0,0 +1,256 @@
+package gRPC
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// commandRetryInterval is how long a delivered command may go unacknowledged
+// before it is delivered again
+const commandRetryInterval = 2 * time.Minute
+
+// CommandAckHandler is called when a DataNode acknowledges a command, or with
+// a failed ack when the command can no longer run because the DataNode died
+type CommandAckHandler func(address string, command *protobuf.DataNodeCommand, ack *protobuf.CommandAck)
+
+// commandQueue holds the commands of a single DataNode
+type commandQueue struct {
+	pending  []*protobuf.DataNodeCommand
+	inFlight map[int64]*inFlightCommand
+	// deleting holds the blocks of unacknowledged DELETE_BLOCKS commands
+	deleting map[string]bool
+}
+
+// inFlightCommand is a command delivered to a DataNode but not yet acknowledged
+type inFlightCommand struct {
+	command *protobuf.DataNodeCommand
+	sentAt  time.Time
+}
+
+// queueFor returns the command queue of address, creating it if needed.
+// Must be called with m.mu held.
+func (m *DataNodeManager) queueFor(address string) *commandQueue {
+	queue, exists := m.commands[address]
+	if !exists {
+		queue = &commandQueue{inFlight: make(map[int64]*inFlightCommand), deleting: make(map[string]bool)}
+		m.commands[address] = queue
+	}
+	return queue
+}
+
+// QueueCommand schedules a command for the DataNode at address; it is
+// delivered with the response to the DataNode's next heartbeat. The assigned
+// command ID is returned.
+func (m *DataNodeManager) QueueCommand(address string, command *protobuf.DataNodeCommand) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextCommandID++
+	command.CommandId = m.nextCommandID
+	queue := m.queueFor(address)
+	queue.pending = append(queue.pending, command)
+
+	return command.CommandId
+}
+
+// QueueBlockDeletion asks the DataNode at address to delete blocks, leaving
+// out those it has already been asked to delete, and returns the blocks queued
+func (m *DataNodeManager) QueueBlockDeletion(address string, blockIDs []string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.queueBlockDeletion(address, blockIDs)
+}
+
+// queueBlockDeletion queues a DELETE_BLOCKS command for the blocks not already
+// being deleted and records them in the invalidation log. Must be called with
+// m.mu held.
+func (m *DataNodeManager) queueBlockDeletion(address string, blockIDs []string) []string {
+	queue := m.queueFor(address)
+	var toDelete []string
+	for _, blockID := range blockIDs {
+		if !queue.deleting[blockID] {
+			queue.deleting[blockID] = true
+			toDelete = append(toDelete, blockID)
+		}
+	}
+	if len(toDelete) == 0 {
+		return nil
+	}
+
+	m.nextCommandID++
+	queue.pending = append(queue.pending, &protobuf.DataNodeCommand{
+		CommandId: m.nextCommandID,
+		Type:      protobuf.DataNodeCommand_DELETE_BLOCKS,
+		BlockIds:  toDelete,
+	})
+	// A deletion that could not be saved is still pending, and saved with
+	// the next change
+	if m.invalidations != nil {
+		if err := m.invalidations.Add(address, toDelete); err != nil {
+			log.Printf("Failed to record blocks %v to delete on %s: %v", toDelete, address, err)
+		}
+	}
+	return toDelete
+}
+
+// SetInvalidationLog makes the manager record the blocks DataNodes are asked
+// to delete in l, and ask again for those still pending when they register
+func (m *DataNodeManager) SetInvalidationLog(l *InvalidationLog) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.invalidations = l
+}
+
+// PendingBlockDeletions returns the number of replicas DataNodes have yet to
+// confirm deleting
+func (m *DataNodeManager) PendingBlockDeletions() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.invalidations == nil {
+		return 0
+	}
+	return m.invalidations.Count()
+}
+
+// TakeCommands returns the commands to deliver to the DataNode at address:
+// every pending command plus those delivered more than commandRetryInterval
+// ago without being acknowledged
+func (m *DataNodeManager) TakeCommands(address string, now time.Time) []*protobuf.DataNodeCommand {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	queue, exists := m.commands[address]
+	if !exists {
+		return nil
+	}
+
+	commands := queue.pending
+	queue.pending = nil
+	for _, command := range commands {
+		queue.inFlight[command.GetCommandId()] = &inFlightCommand{command: command, sentAt: now}
+	}
+	for _, inFlight := range queue.inFlight {
+		if now.Sub(inFlight.sentAt) >= commandRetryInterval {
+			inFlight.sentAt = now
+			commands = append(commands, inFlight.command)
+		}
+	}
+
+	return commands
+}
+
+// AckCommands records the acknowledgements sent by the DataNode at address
+func (m *DataNodeManager) AckCommands(address string, acks []*protobuf.CommandAck) {
+	type ackedCommand struct {
+		command *protobuf.DataNodeCommand
+		ack     *protobuf.CommandAck
+	}
+
+	m.mu.Lock()
+	var acked []ackedCommand
+	if queue, exists := m.commands[address]; exists {
+		for _, ack := range acks {
+			inFlight, ok := queue.inFlight[ack.GetCommandId()]
+			if !ok {
+				continue
+			}
+			delete(queue.inFlight, ack.GetCommandId())
+			if inFlight.command.GetType() == protobuf.DataNodeCommand_DELETE_BLOCKS {
+				for _, blockID := range inFlight.command.GetBlockIds() {
+					delete(queue.deleting, blockID)
+				}
+				// Failed deletions are asked for again when the DataNode
+				// next registers
+				if deleted := deletedBlocks(inFlight.command, ack); len(deleted) > 0 && m.invalidations != nil {
+					if err := m.invalidations.Remove(address, deleted); err != nil {
+						log.Printf("Failed to record blocks %v deleted on %s: %v", deleted, address, err)
+					}
+				}
+			}
+			acked = append(acked, ackedCommand{command: inFlight.command, ack: ack})
+		}
+	}
+	handlers := m.ackHandlers
+	m.mu.Unlock()
+
+	// Handlers run without the lock so they can queue follow-up commands
+	for _, a := range acked {
+		if !a.ack.GetSuccess() {
+			log.Printf("DataNode %s failed command %d (%s): %s", address, a.command.GetCommandId(), a.command.GetType(), a.ack.GetError())
+		}
+		for _, handler := range handlers {
+			handler(address, a.command, a.ack)
+		}
+	}
+}
+
+// deletedBlocks returns the blocks of a DELETE_BLOCKS command its ack
+// confirms deleted: all of them when it succeeded, or those not listed as
+// failed when only some failed
+func deletedBlocks(command *protobuf.DataNodeCommand, ack *protobuf.CommandAck) []string {
+	if ack.GetSuccess() {
+		return command.GetBlockIds()
+	}
+	if len(ack.GetFailedBlockIds()) == 0 {
+		return nil
+	}
+	var deleted []string
+	for _, blockID := range command.GetBlockIds() {
+		if !slices.Contains(ack.GetFailedBlockIds(), blockID) {
+			deleted = append(deleted, blockID)
+		}
+	}
+	return deleted
+}
+
+// OnCommandAck registers a handler called for every acknowledged command
+func (m *DataNodeManager) OnCommandAck(handler CommandAckHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ackHandlers = append(m.ackHandlers, handler)
+}
+
+// PendingCommandCount returns the number of commands not yet acknowledged by
+// the DataNode at address
+func (m *DataNodeManager) PendingCommandCount(address string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	queue, exists := m.commands[address]
+	if !exists {
+		return 0
+	}
+	return len(queue.pending) + len(queue.inFlight)
+}
+
+// failCommands drops the commands of a DataNode that died and reports them to
+// the ack handlers as failed, so their work can be rescheduled elsewhere.
+// Must be called with m.mu held; handlers run on their own goroutine.
+func (m *DataNodeManager) failCommands(address string) {
+	queue, exists := m.commands[address]
+	if !exists {
+		return
+	}
+	delete(m.commands, address)
+
+	commands := queue.pending
+	for _, inFlight := range queue.inFlight {
+		commands = append(commands, inFlight.command)
+	}
+	if len(commands) == 0 {
+		return
+	}
+
+	handlers := m.ackHandlers
+	go func() {
+		for _, command := range commands {
+			ack := &protobuf.CommandAck{CommandId: command.GetCommandId(), Success: false, Error: "DataNode is dead"}
+			for _, handler := range handlers {
+				handler(address, command, ack)
+			}
+		}
+	}()
+}
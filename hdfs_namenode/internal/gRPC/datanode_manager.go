@@ -0,0 +1,393 @@
+package gRPC
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NodeState is the liveness of a DataNode as seen by the NameNode
+type NodeState string
+
+const (
+	// NodeLive DataNodes heartbeat regularly and receive new blocks
+	NodeLive NodeState = "LIVE"
+	// NodeStale DataNodes missed heartbeats for a while; they keep their
+	// replicas but are not given new blocks
+	NodeStale NodeState = "STALE"
+	// NodeDead DataNodes have been silent long enough that their replicas
+	// are considered lost
+	NodeDead NodeState = "DEAD"
+)
+
+type DataNode struct {
+	Address       string
+	ID            string
+	LastHeartbeat time.Time
+	State         NodeState
+	// Rack is the DataNode's network location, e.g. "/dc1/rack1"
+	Rack string
+	// Stats are the figures reported in the latest heartbeat
+	Stats DataNodeStats
+}
+
+// DataNodeStats are the capacity and load figures a DataNode heartbeats
+type DataNodeStats struct {
+	CapacityBytes   int64
+	DfsUsedBytes    int64
+	RemainingBytes  int64
+	BlockCount      int64
+	ActiveTransfers int32
+	VolumeFailures  int32
+}
+
+// HasSpaceFor reports whether the DataNode can store size more bytes.
+// DataNodes that have not reported their capacity yet are given the benefit
+// of the doubt.
+func (d *DataNode) HasSpaceFor(size int64) bool {
+	if d.Stats.CapacityBytes == 0 {
+		return true
+	}
+	return d.Stats.RemainingBytes >= size
+}
+
+// NodeEvent describes a DataNode changing state. From is empty when the
+// DataNode has just registered.
+type NodeEvent struct {
+	Address string
+	ID      string
+	From    NodeState
+	To      NodeState
+	Time    time.Time
+}
+
+const (
+	// DefaultStaleInterval is how long a DataNode may go without heartbeating
+	// before it is marked stale
+	DefaultStaleInterval = 90 * time.Second
+	// DefaultDeadInterval is how long a DataNode may go without heartbeating
+	// before it is marked dead
+	DefaultDeadInterval = 10 * time.Minute
+
+	// livenessCheckInterval is how often the liveness monitor runs
+	livenessCheckInterval = 5 * time.Second
+)
+
+type DataNodeManager struct {
+	mu        sync.RWMutex
+	dataNodes map[string]*DataNode
+
+	staleInterval time.Duration
+	deadInterval  time.Duration
+	subscribers   []*eventQueue
+	// topology resolves the rack of each DataNode as it registers
+	topology TopologyResolver
+
+	// commands waiting to be delivered to, or acknowledged by, each DataNode
+	commands map[string]*commandQueue
+	// nextCommandID starts from the clock so command IDs are not reused
+	// after a restart, as DataNodes ignore commands they are still running
+	nextCommandID int64
+	ackHandlers   []CommandAckHandler
+	// invalidations survive restarts so no deletion is forgotten
+	invalidations *InvalidationLog
+}
+
+var instance *DataNodeManager
+var once sync.Once
+
+// GetInstance returns the singleton instance of DataNodeManager
+func GetInstance() *DataNodeManager {
+	once.Do(func() {
+		instance = NewDataNodeManager()
+	})
+	return instance
+}
+
+// NewDataNodeManager creates a DataNodeManager using the default timeouts
+func NewDataNodeManager() *DataNodeManager {
+	return &DataNodeManager{
+		dataNodes:     make(map[string]*DataNode),
+		commands:      make(map[string]*commandQueue),
+		nextCommandID: time.Now().UnixNano(),
+		staleInterval: DefaultStaleInterval,
+		deadInterval:  DefaultDeadInterval,
+	}
+}
+
+// SetTimeouts configures after how long without a heartbeat a DataNode is
+// marked stale and then dead
+func (m *DataNodeManager) SetTimeouts(staleInterval, deadInterval time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.staleInterval = staleInterval
+	m.deadInterval = deadInterval
+}
+
+// SetTopologyResolver configures how the racks of DataNodes and clients are
+// resolved. DataNodes already registered are moved to their new racks.
+func (m *DataNodeManager) SetTopologyResolver(topology TopologyResolver) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.topology = topology
+	for address, dataNode := range m.dataNodes {
+		dataNode.Rack = m.resolveRack(address)
+	}
+}
+
+// ResolveRack returns the rack of a DataNode or client address
+func (m *DataNodeManager) ResolveRack(address string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.resolveRack(address)
+}
+
+// resolveRack returns the rack of address, or DefaultRack if no topology is
+// configured or the resolver returns an invalid rack. Must be called with
+// m.mu held.
+func (m *DataNodeManager) resolveRack(address string) string {
+	if m.topology == nil {
+		return DefaultRack
+	}
+	rack, err := normalizeRack(m.topology.ResolveRack(address))
+	if err != nil {
+		log.Printf("Could not resolve the rack of %s: %v", address, err)
+		return DefaultRack
+	}
+	return rack
+}
+
+func (m *DataNodeManager) RegisterDataNode(address, id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var from NodeState
+	if existing, ok := m.dataNodes[address]; ok {
+		from = existing.State
+	}
+	rack := m.resolveRack(address)
+	m.dataNodes[address] = &DataNode{Address: address, ID: id, LastHeartbeat: now, State: NodeLive, Rack: rack}
+	log.Printf("DataNode %s is on rack %s", address, rack)
+	if m.invalidations != nil {
+		if queued := m.queueBlockDeletion(address, m.invalidations.Pending(address)); len(queued) > 0 {
+			log.Printf("Asking DataNode %s again to delete blocks %v", address, queued)
+		}
+	}
+	m.publish(NodeEvent{Address: address, ID: id, From: from, To: NodeLive, Time: now})
+}
+
+// Heartbeat records a heartbeat and the latest statistics from the DataNode
+// at address, bringing it back to life if it was stale or dead. It returns
+// false for unknown DataNodes.
+func (m *DataNodeManager) Heartbeat(address string, stats DataNodeStats) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dataNode, exists := m.dataNodes[address]
+	if !exists {
+		return false
+	}
+
+	dataNode.Stats = stats
+	dataNode.LastHeartbeat = time.Now()
+	m.transition(dataNode, NodeLive, dataNode.LastHeartbeat)
+	return true
+}
+
+// IsRegistered reports whether the DataNode at address has registered
+func (m *DataNodeManager) IsRegistered(address string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, exists := m.dataNodes[address]
+	return exists
+}
+
+// GetDataNodes returns a snapshot of every registered DataNode, whatever its state
+func (m *DataNodeManager) GetDataNodes() map[string]*DataNode {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	// Return a copy of the map to avoid concurrent modifications
+	dataNodesCopy := make(map[string]*DataNode)
+	for k, v := range m.dataNodes {
+		dataNode := *v
+		dataNodesCopy[k] = &dataNode
+	}
+	return dataNodesCopy
+}
+
+// GetLiveDataNodes returns a snapshot of the DataNodes that can receive new blocks
+func (m *DataNodeManager) GetLiveDataNodes() map[string]*DataNode {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	dataNodesCopy := make(map[string]*DataNode)
+	for k, v := range m.dataNodes {
+		if v.State == NodeLive {
+			dataNode := *v
+			dataNodesCopy[k] = &dataNode
+		}
+	}
+	return dataNodesCopy
+}
+
+// ClusterReport summarises the state and capacity of the cluster
+type ClusterReport struct {
+	LiveNodes  int
+	StaleNodes int
+	DeadNodes  int
+	// Racks counts the racks with live or stale DataNodes
+	Racks           int
+	CapacityBytes   int64
+	DfsUsedBytes    int64
+	RemainingBytes  int64
+	BlockCount      int64
+	ActiveTransfers int64
+	VolumeFailures  int64
+	// PendingBlockDeletions counts the replicas DataNodes have yet to delete
+	PendingBlockDeletions int
+	DataNodes             []DataNode
+}
+
+// Report builds a ClusterReport. Capacity totals only count live and stale
+// DataNodes, since the storage of dead ones is unavailable.
+func (m *DataNodeManager) Report() *ClusterReport {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	report := &ClusterReport{DataNodes: make([]DataNode, 0, len(m.dataNodes))}
+	racks := make(map[string]bool)
+	for _, dataNode := range m.dataNodes {
+		report.DataNodes = append(report.DataNodes, *dataNode)
+		switch dataNode.State {
+		case NodeLive:
+			report.LiveNodes++
+		case NodeStale:
+			report.StaleNodes++
+		case NodeDead:
+			report.DeadNodes++
+			continue
+		}
+		racks[rackOf(dataNode)] = true
+		report.CapacityBytes += dataNode.Stats.CapacityBytes
+		report.DfsUsedBytes += dataNode.Stats.DfsUsedBytes
+		report.RemainingBytes += dataNode.Stats.RemainingBytes
+		report.BlockCount += dataNode.Stats.BlockCount
+		report.ActiveTransfers += int64(dataNode.Stats.ActiveTransfers)
+		report.VolumeFailures += int64(dataNode.Stats.VolumeFailures)
+	}
+	report.Racks = len(racks)
+	if m.invalidations != nil {
+		report.PendingBlockDeletions = m.invalidations.Count()
+	}
+	sort.Slice(report.DataNodes, func(i, j int) bool {
+		return report.DataNodes[i].Address < report.DataNodes[j].Address
+	})
+
+	return report
+}
+
+// CheckLiveness marks DataNodes stale or dead according to the time elapsed
+// since their last heartbeat, as of now
+func (m *DataNodeManager) CheckLiveness(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, dataNode := range m.dataNodes {
+		silence := now.Sub(dataNode.LastHeartbeat)
+		switch {
+		case silence >= m.deadInterval:
+			m.transition(dataNode, NodeDead, now)
+		case silence >= m.staleInterval:
+			m.transition(dataNode, NodeStale, now)
+		}
+	}
+}
+
+// StartLivenessMonitor periodically checks DataNode liveness until stop is closed
+func (m *DataNodeManager) StartLivenessMonitor(stop <-chan struct{}) {
+	ticker := time.NewTicker(livenessCheckInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				m.CheckLiveness(now)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Subscribe returns a channel receiving every DataNode state transition, in
+// order. Events a slow subscriber has yet to receive are queued for it.
+func (m *DataNodeManager) Subscribe() <-chan NodeEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	queue := newEventQueue()
+	m.subscribers = append(m.subscribers, queue)
+	return queue.events
+}
+
+// transition moves dataNode to state, publishing an event if it changed.
+// Must be called with m.mu held.
+func (m *DataNodeManager) transition(dataNode *DataNode, state NodeState, now time.Time) {
+	if dataNode.State == state {
+		return
+	}
+	event := NodeEvent{Address: dataNode.Address, ID: dataNode.ID, From: dataNode.State, To: state, Time: now}
+	dataNode.State = state
+	log.Printf("DataNode %s is now %s (was %s)", dataNode.Address, state, event.From)
+	if state == NodeDead {
+		m.failCommands(dataNode.Address)
+	}
+	m.publish(event)
+}
+
+// publish queues event for every subscriber without blocking.
+// Must be called with m.mu held.
+func (m *DataNodeManager) publish(event NodeEvent) {
+	for _, queue := range m.subscribers {
+		queue.push(event)
+	}
+}
+
+// eventQueue delivers the events published for a subscriber in order,
+// holding as many as it falls behind by
+type eventQueue struct {
+	mu      sync.Mutex
+	pending []NodeEvent
+	// ready wakes the delivering goroutine once events are pending
+	ready  chan struct{}
+	events chan NodeEvent
+}
+
+func newEventQueue() *eventQueue {
+	q := &eventQueue{ready: make(chan struct{}, 1), events: make(chan NodeEvent)}
+	go q.deliver()
+	return q
+}
+
+func (q *eventQueue) push(event NodeEvent) {
+	q.mu.Lock()
+	q.pending = append(q.pending, event)
+	q.mu.Unlock()
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends the pending events to the subscriber as it receives them
+func (q *eventQueue) deliver() {
+	for range q.ready {
+		q.mu.Lock()
+		pending := q.pending
+		q.pending = nil
+		q.mu.Unlock()
+		for _, event := range pending {
+			q.events <- event
+		}
+	}
+}
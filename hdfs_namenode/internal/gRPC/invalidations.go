@@ -0,0 +1,259 @@
+package gRPC
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// compactionSlack is how many more blocks the journal of an invalidation log
+// may name than are pending before it is rewritten
+const compactionSlack = 1024
+
+// InvalidationLog remembers the replicas each DataNode has been asked to
+// delete until it confirms the deletion, so deletions interrupted by a
+// NameNode restart are queued again and the space they hold is reclaimed.
+//
+// Every change is appended to a journal, one JSON record per line, and
+// fsync'd. Once the journal grows well past the blocks still pending it is
+// rewritten with just those.
+type InvalidationLog struct {
+	mu   sync.Mutex
+	path string
+	// journal is open for appending, or nil after a failed write, in which
+	// case the next change rewrites it
+	journal *os.File
+	// journaled counts the blocks named by the records in the journal
+	journaled int
+	// Blocks awaiting deletion, keyed by DataNode address
+	pending map[string]map[string]bool
+}
+
+// invalidationRecord is a line of the journal
+type invalidationRecord struct {
+	// Op is "add" or "remove"
+	Op       string   `json:"op"`
+	DataNode string   `json:"datanode"`
+	Blocks   []string `json:"blocks"`
+}
+
+// LoadInvalidationLog opens the invalidation log saved at path, starting an
+// empty one if the file does not exist. A record torn by a crash while it was
+// appended ends the journal; any other bad record fails the load.
+func LoadInvalidationLog(path string) (*InvalidationLog, error) {
+	l := &InvalidationLog{path: path, pending: make(map[string]map[string]bool)}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		var record invalidationRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, fmt.Errorf("invalid invalidation log %s: record %d: %v", path, i+1, err)
+		}
+		switch record.Op {
+		case "add":
+			l.add(record.DataNode, record.Blocks)
+		case "remove":
+			l.remove(record.DataNode, record.Blocks)
+		default:
+			return nil, fmt.Errorf("invalid invalidation log %s: record %d has unknown op %q", path, i+1, record.Op)
+		}
+	}
+
+	// Starting from a compacted journal drops any torn record
+	if err := l.compact(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Add records blocks the DataNode at address has been asked to delete. They
+// are pending even if the change could not be saved, and saved with the next
+// one.
+func (l *InvalidationLog) Add(address string, blockIDs []string) error {
+	if len(blockIDs) == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.add(address, blockIDs)
+	return l.append(invalidationRecord{Op: "add", DataNode: address, Blocks: blockIDs})
+}
+
+// Remove forgets blocks the DataNode at address confirmed deleting
+func (l *InvalidationLog) Remove(address string, blockIDs []string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, exists := l.pending[address]; !exists {
+		return nil
+	}
+	l.remove(address, blockIDs)
+	return l.append(invalidationRecord{Op: "remove", DataNode: address, Blocks: blockIDs})
+}
+
+// Pending returns the blocks the DataNode at address has yet to delete
+func (l *InvalidationLog) Pending(address string) []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return sortedKeys(l.pending[address])
+}
+
+// Count returns the number of replicas awaiting deletion on every DataNode
+func (l *InvalidationLog) Count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.count()
+}
+
+func (l *InvalidationLog) add(address string, blockIDs []string) {
+	if l.pending[address] == nil {
+		l.pending[address] = make(map[string]bool)
+	}
+	for _, blockID := range blockIDs {
+		l.pending[address][blockID] = true
+	}
+}
+
+func (l *InvalidationLog) remove(address string, blockIDs []string) {
+	blocks, exists := l.pending[address]
+	if !exists {
+		return
+	}
+	for _, blockID := range blockIDs {
+		delete(blocks, blockID)
+	}
+	if len(blocks) == 0 {
+		delete(l.pending, address)
+	}
+}
+
+func (l *InvalidationLog) count() int {
+	count := 0
+	for _, blocks := range l.pending {
+		count += len(blocks)
+	}
+	return count
+}
+
+// append adds a record to the journal and fsyncs it, unless the journal is
+// due to be rewritten, which saves the record along with the others. Must be
+// called with l.mu held.
+func (l *InvalidationLog) append(record invalidationRecord) error {
+	if l.journal == nil || l.journaled+len(record.Blocks) > 2*l.count()+compactionSlack {
+		return l.compact()
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := l.journal.Write(append(line, '\n')); err != nil {
+		return l.failed(err)
+	}
+	if err := l.journal.Sync(); err != nil {
+		return l.failed(err)
+	}
+	l.journaled += len(record.Blocks)
+	return nil
+}
+
+// failed closes the journal after a failed write, which may have left part
+// of a record in it, so the next change rewrites it. Must be called with
+// l.mu held.
+func (l *InvalidationLog) failed(err error) error {
+	l.journal.Close()
+	l.journal = nil
+	return fmt.Errorf("writing invalidation log %s: %w", l.path, err)
+}
+
+// compact rewrites the journal with one record per DataNode holding its
+// pending blocks. The records are written to a temporary file, which is
+// fsync'd and renamed over the journal, so a crash leaves either journal
+// whole. Must be called with l.mu held.
+func (l *InvalidationLog) compact() error {
+	if l.journal != nil {
+		l.journal.Close()
+		l.journal = nil
+	}
+
+	var data []byte
+	journaled := 0
+	addresses := make([]string, 0, len(l.pending))
+	for address := range l.pending {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		record := invalidationRecord{Op: "add", DataNode: address, Blocks: sortedKeys(l.pending[address])}
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+		journaled += len(record.Blocks)
+	}
+
+	file, err := writeJournal(l.path, data)
+	if err != nil {
+		return fmt.Errorf("writing invalidation log %s: %w", l.path, err)
+	}
+	// The renamed file is the journal the next records are appended to
+	l.journal = file
+	l.journaled = journaled
+	return nil
+}
+
+// writeJournal replaces the journal at path with data, and returns it open for
+// appending
+func writeJournal(path string, data []byte) (*os.File, error) {
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+// syncDir fsyncs dir, so files created or renamed in it survive a crash
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
@@ -0,0 +1,206 @@
+package gRPC
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// BlockPlacementPolicy decides which DataNodes store the replicas of a block.
+// Policies must be deterministic for a given set of DataNodes so placement
+// can be tested and reasoned about.
+type BlockPlacementPolicy interface {
+	// ChooseTargets returns up to n distinct DataNodes, in pipeline order, to
+	// store a replica of a block of blockSize bytes written from writerRack,
+	// which is empty if unknown. DataNodes in exclude, e.g. those already
+	// holding the block, are never chosen.
+	ChooseTargets(n int, writerRack string, blockSize int64, dataNodes map[string]*DataNode, exclude map[string]bool) []string
+	// ChooseExcessReplicas returns which n of the DataNodes holding replicas
+	// of an over-replicated block should delete theirs
+	ChooseExcessReplicas(n int, replicas []*DataNode) []string
+}
+
+// PlacementPolicyFactory creates a BlockPlacementPolicy
+type PlacementPolicyFactory func() BlockPlacementPolicy
+
+const (
+	// DefaultPlacementPolicy balances load and capacity
+	DefaultPlacementPolicy = "default"
+	// RoundRobinPlacementPolicy spreads replicas over every DataNode in turn
+	RoundRobinPlacementPolicy = "round-robin"
+	// LeastUsedPlacementPolicy fills the DataNodes storing the least data first
+	LeastUsedPlacementPolicy = "least-used"
+	// RackAwarePlacementPolicy spreads replicas over at least two racks
+	RackAwarePlacementPolicy = "rack-aware"
+)
+
+var (
+	placementPoliciesMu sync.RWMutex
+	placementPolicies   = map[string]PlacementPolicyFactory{
+		DefaultPlacementPolicy:    func() BlockPlacementPolicy { return &defaultPlacementPolicy{} },
+		RoundRobinPlacementPolicy: func() BlockPlacementPolicy { return &roundRobinPlacementPolicy{} },
+		LeastUsedPlacementPolicy:  func() BlockPlacementPolicy { return &leastUsedPlacementPolicy{} },
+		RackAwarePlacementPolicy:  func() BlockPlacementPolicy { return &rackAwarePlacementPolicy{} },
+	}
+)
+
+// RegisterPlacementPolicy makes a policy available under name, replacing any
+// policy registered under the same name
+func RegisterPlacementPolicy(name string, factory PlacementPolicyFactory) {
+	placementPoliciesMu.Lock()
+	defer placementPoliciesMu.Unlock()
+	placementPolicies[name] = factory
+}
+
+// NewPlacementPolicy creates the policy registered under name
+func NewPlacementPolicy(name string) (BlockPlacementPolicy, error) {
+	placementPoliciesMu.RLock()
+	defer placementPoliciesMu.RUnlock()
+
+	factory, exists := placementPolicies[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown block placement policy %q, expected one of %v", name, placementPolicyNames())
+	}
+	return factory(), nil
+}
+
+// placementPolicyNames lists the registered policies. Must be called with
+// placementPoliciesMu held.
+func placementPolicyNames() []string {
+	names := make([]string, 0, len(placementPolicies))
+	for name := range placementPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// eligibleTargets returns the live DataNodes outside exclude with room for a
+// block of blockSize bytes, sorted by address
+func eligibleTargets(blockSize int64, dataNodes map[string]*DataNode, exclude map[string]bool) []*DataNode {
+	candidates := make([]*DataNode, 0, len(dataNodes))
+	for address, dataNode := range dataNodes {
+		if dataNode.State == NodeLive && !exclude[address] && dataNode.HasSpaceFor(blockSize) {
+			candidates = append(candidates, dataNode)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Address < candidates[j].Address
+	})
+	return candidates
+}
+
+// firstAddresses returns the addresses of the first n DataNodes
+func firstAddresses(dataNodes []*DataNode, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	if n > len(dataNodes) {
+		n = len(dataNodes)
+	}
+	addresses := make([]string, 0, n)
+	for _, dataNode := range dataNodes[:n] {
+		addresses = append(addresses, dataNode.Address)
+	}
+	return addresses
+}
+
+// usedFraction is the share of a DataNode's capacity in use, or 0 if it has
+// not reported its capacity yet
+func usedFraction(dataNode *DataNode) float64 {
+	if dataNode.Stats.CapacityBytes == 0 {
+		return 0
+	}
+	return 1 - float64(dataNode.Stats.RemainingBytes)/float64(dataNode.Stats.CapacityBytes)
+}
+
+// leastBusyFirst sorts DataNodes by their active transfers, then by the share
+// of their capacity in use
+func leastBusyFirst(dataNodes []*DataNode) []*DataNode {
+	sort.SliceStable(dataNodes, func(i, j int) bool {
+		a, b := dataNodes[i], dataNodes[j]
+		if a.Stats.ActiveTransfers != b.Stats.ActiveTransfers {
+			return a.Stats.ActiveTransfers < b.Stats.ActiveTransfers
+		}
+		return usedFraction(a) < usedFraction(b)
+	})
+	return dataNodes
+}
+
+// fullestFirst returns the n DataNodes with the least remaining space, which
+// is where deleting a replica helps most
+func fullestFirst(n int, replicas []*DataNode) []string {
+	sorted := append([]*DataNode(nil), replicas...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Stats.RemainingBytes != sorted[j].Stats.RemainingBytes {
+			return sorted[i].Stats.RemainingBytes < sorted[j].Stats.RemainingBytes
+		}
+		return sorted[i].Address < sorted[j].Address
+	})
+	return firstAddresses(sorted, n)
+}
+
+// defaultPlacementPolicy prefers the least busy live DataNodes, then those
+// with the largest share of their capacity free
+type defaultPlacementPolicy struct{}
+
+func (p *defaultPlacementPolicy) ChooseTargets(n int, writerRack string, blockSize int64, dataNodes map[string]*DataNode, exclude map[string]bool) []string {
+	return firstAddresses(leastBusyFirst(eligibleTargets(blockSize, dataNodes, exclude)), n)
+}
+
+func (p *defaultPlacementPolicy) ChooseExcessReplicas(n int, replicas []*DataNode) []string {
+	return fullestFirst(n, replicas)
+}
+
+// roundRobinPlacementPolicy places the replicas of successive blocks on
+// successive DataNodes in address order, so every live DataNode takes its
+// turn regardless of load
+type roundRobinPlacementPolicy struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (p *roundRobinPlacementPolicy) ChooseTargets(n int, writerRack string, blockSize int64, dataNodes map[string]*DataNode, exclude map[string]bool) []string {
+	candidates := eligibleTargets(blockSize, dataNodes, exclude)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	start := p.next % len(candidates)
+	p.next++
+	p.mu.Unlock()
+
+	rotated := make([]*DataNode, 0, len(candidates))
+	rotated = append(rotated, candidates[start:]...)
+	rotated = append(rotated, candidates[:start]...)
+	return firstAddresses(rotated, n)
+}
+
+func (p *roundRobinPlacementPolicy) ChooseExcessReplicas(n int, replicas []*DataNode) []string {
+	return fullestFirst(n, replicas)
+}
+
+// leastUsedPlacementPolicy fills the DataNodes storing the fewest bytes first,
+// which evens out usage after new DataNodes join
+type leastUsedPlacementPolicy struct{}
+
+func (p *leastUsedPlacementPolicy) ChooseTargets(n int, writerRack string, blockSize int64, dataNodes map[string]*DataNode, exclude map[string]bool) []string {
+	candidates := eligibleTargets(blockSize, dataNodes, exclude)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Stats.DfsUsedBytes < candidates[j].Stats.DfsUsedBytes
+	})
+	return firstAddresses(candidates, n)
+}
+
+func (p *leastUsedPlacementPolicy) ChooseExcessReplicas(n int, replicas []*DataNode) []string {
+	// Delete from the DataNodes storing the most data
+	sorted := append([]*DataNode(nil), replicas...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Stats.DfsUsedBytes != sorted[j].Stats.DfsUsedBytes {
+			return sorted[i].Stats.DfsUsedBytes > sorted[j].Stats.DfsUsedBytes
+		}
+		return sorted[i].Address < sorted[j].Address
+	})
+	return firstAddresses(sorted, n)
+}
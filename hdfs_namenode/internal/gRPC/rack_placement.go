@@ -0,0 +1,107 @@
+package gRPC
+
+// rackAwarePlacementPolicy places the first replica of a new block on the
+// writer's rack and makes sure the replicas of every block span at least two
+// racks, so a block survives the loss of a whole rack. The remaining replicas
+// go to the least busy DataNodes, no rack holding more than its share.
+type rackAwarePlacementPolicy struct{}
+
+func (p *rackAwarePlacementPolicy) ChooseTargets(n int, writerRack string, blockSize int64, dataNodes map[string]*DataNode, exclude map[string]bool) []string {
+	if n <= 0 {
+		return nil
+	}
+	candidates := leastBusyFirst(eligibleTargets(blockSize, dataNodes, exclude))
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// Replicas on each rack, counting those the block already has
+	perRack := make(map[string]int)
+	existing := 0
+	for address := range exclude {
+		if dataNode, exists := dataNodes[address]; exists {
+			perRack[rackOf(dataNode)]++
+			existing++
+		}
+	}
+	racks := make(map[string]bool, len(perRack))
+	for rack := range perRack {
+		racks[rack] = true
+	}
+	for _, candidate := range candidates {
+		racks[rackOf(candidate)] = true
+	}
+
+	targets := make([]string, 0, n)
+	taken := make(map[string]bool, n)
+	// pick takes the least busy candidate on a rack accepted by accept
+	pick := func(accept func(rack string) bool) bool {
+		if len(targets) == n {
+			return false
+		}
+		for _, candidate := range candidates {
+			rack := rackOf(candidate)
+			if !taken[candidate.Address] && accept(rack) {
+				taken[candidate.Address] = true
+				perRack[rack]++
+				targets = append(targets, candidate.Address)
+				return true
+			}
+		}
+		return false
+	}
+
+	if existing == 0 && writerRack != "" {
+		pick(func(rack string) bool { return rack == writerRack })
+	}
+	if len(perRack) == 0 {
+		pick(func(string) bool { return true })
+	}
+	if len(perRack) == 1 {
+		for onlyRack := range perRack {
+			pick(func(rack string) bool { return rack != onlyRack })
+		}
+	}
+
+	maxPerRack := (existing+n-1)/len(racks) + 2
+	for pick(func(rack string) bool { return perRack[rack] < maxPerRack }) {
+	}
+	// Clusters with too few racks take whatever DataNodes are left
+	for pick(func(string) bool { return true }) {
+	}
+	return targets
+}
+
+// ChooseExcessReplicas deletes replicas from racks holding more than one, so
+// the block keeps as many racks as it can, fullest DataNodes first
+func (p *rackAwarePlacementPolicy) ChooseExcessReplicas(n int, replicas []*DataNode) []string {
+	perRack := make(map[string]int)
+	for _, replica := range replicas {
+		perRack[rackOf(replica)]++
+	}
+
+	excess := make([]string, 0, n)
+	remaining := append([]*DataNode(nil), replicas...)
+	for len(excess) < n && len(remaining) > 0 {
+		candidates := make([]*DataNode, 0, len(remaining))
+		for _, replica := range remaining {
+			if perRack[rackOf(replica)] > 1 {
+				candidates = append(candidates, replica)
+			}
+		}
+		if len(candidates) == 0 {
+			candidates = remaining
+		}
+
+		address := fullestFirst(1, candidates)[0]
+		excess = append(excess, address)
+		for i, replica := range remaining {
+			if replica.Address == address {
+				perRack[rackOf(replica)]--
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+		}
+	}
+	return excess
+}
@@ -0,0 +1,439 @@
+package gRPC
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// ReplicationPriority orders under-replicated blocks, most urgent first
+type ReplicationPriority int
+
+const (
+	// PriorityHighest blocks have at most one replica left
+	PriorityHighest ReplicationPriority = iota
+	// PriorityVeryUnderReplicated blocks have less than a third of their replicas
+	PriorityVeryUnderReplicated
+	// PriorityUnderReplicated blocks are missing some replicas
+	PriorityUnderReplicated
+
+	numReplicationPriorities
+)
+
+const (
+	// DefaultReplicationTimeout is how long a copy may take by default
+	DefaultReplicationTimeout = 5 * time.Minute
+	// DefaultMaxReplicationStreams is how many copies a DataNode sends at once by default
+	DefaultMaxReplicationStreams = 2
+)
+
+// pendingReplication is a copy sent to DataNodes and not yet reported by
+// all of its targets
+type pendingReplication struct {
+	commandID   int64
+	source      string
+	targets     map[string]bool
+	scheduledAt time.Time
+}
+
+// ReplicationMonitor keeps every block at its replication factor. Each scan
+// sorts under-replicated blocks into priority queues and asks a DataNode
+// holding a replica to copy it to new DataNodes, and asks DataNodes holding
+// excess replicas to delete them. Commands go through the DataNodeManager and
+// their outcome is confirmed by block reports.
+type ReplicationMonitor struct {
+	blockMap        *BlockMap
+	dataNodeManager *DataNodeManager
+	placementPolicy BlockPlacementPolicy
+
+	mu         sync.Mutex
+	timeout    time.Duration
+	maxStreams int
+	// Copies in progress, keyed by block ID
+	pending map[string]*pendingReplication
+	// Excess replicas being deleted, keyed by block ID then DataNode address
+	deleting map[string]map[string]time.Time
+	// Under-replicated blocks found by the last scan, by priority
+	queues         [numReplicationPriorities][]string
+	missingBlocks  int
+	overReplicated int
+	lastScan       time.Time
+
+	// Totals since the NameNode started
+	scheduledReplications int64
+	failedReplications    int64
+	timedOutReplications  int64
+	scheduledDeletions    int64
+}
+
+// ReplicationStatus reports the work of the replication monitor
+type ReplicationStatus struct {
+	// Under-replicated blocks waiting for a copy, by priority
+	HighestPriority     int
+	VeryUnderReplicated int
+	UnderReplicated     int
+	// MissingBlocks have no replica left on a live or stale DataNode
+	MissingBlocks  int
+	OverReplicated int
+
+	PendingReplications int
+	PendingDeletions    int
+
+	// Totals since the NameNode started
+	ScheduledReplications int64
+	FailedReplications    int64
+	TimedOutReplications  int64
+	ScheduledDeletions    int64
+
+	LastScan time.Time
+	Pending  []PendingReplicationInfo
+}
+
+// PendingReplicationInfo describes a copy in progress
+type PendingReplicationInfo struct {
+	BlockID     string
+	Source      string
+	Targets     []string
+	ScheduledAt time.Time
+}
+
+func NewReplicationMonitor(blockMap *BlockMap, dataNodeManager *DataNodeManager) *ReplicationMonitor {
+	m := &ReplicationMonitor{
+		blockMap:        blockMap,
+		dataNodeManager: dataNodeManager,
+		placementPolicy: &defaultPlacementPolicy{},
+		timeout:         DefaultReplicationTimeout,
+		maxStreams:      DefaultMaxReplicationStreams,
+		pending:         make(map[string]*pendingReplication),
+		deleting:        make(map[string]map[string]time.Time),
+	}
+	dataNodeManager.OnCommandAck(m.handleAck)
+	return m
+}
+
+// SetLimits configures after how long a copy is given up and how many
+// copies a DataNode is asked to send at once
+func (m *ReplicationMonitor) SetLimits(timeout time.Duration, maxStreams int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timeout = timeout
+	m.maxStreams = maxStreams
+}
+
+// SetPlacementPolicy configures how the DataNodes receiving copies and
+// deleting excess replicas are chosen
+func (m *ReplicationMonitor) SetPlacementPolicy(policy BlockPlacementPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.placementPolicy = policy
+}
+
+// Start scans the blocks every interval until stop is closed
+func (m *ReplicationMonitor) Start(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				m.Scan(now)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Scan checks every block for missing and excess replicas as of now and
+// schedules the work needed to fix them
+func (m *ReplicationMonitor) Scan(now time.Time) {
+	blocks := m.blockMap.Blocks()
+	dataNodes := m.dataNodeManager.GetDataNodes()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expirePending(blocks, now)
+
+	var queues [numReplicationPriorities][]string
+	missingBlocks, overReplicated := 0, 0
+	blocksByID := make(map[string]*BlockInfo, len(blocks))
+
+	for i := range blocks {
+		block := &blocks[i]
+		if block.UnderConstruction {
+			continue
+		}
+		blocksByID[block.BlockID] = block
+
+		replicas := usableReplicas(block, dataNodes)
+		if replicas == 0 {
+			missingBlocks++
+			continue
+		}
+
+		scheduled := 0
+		if pending, exists := m.pending[block.BlockID]; exists {
+			scheduled = len(pending.targets)
+		}
+		deleting := len(m.deleting[block.BlockID])
+
+		switch {
+		case replicas+scheduled < block.Replication:
+			priority := PriorityUnderReplicated
+			if replicas <= 1 {
+				priority = PriorityHighest
+			} else if replicas*3 < block.Replication {
+				priority = PriorityVeryUnderReplicated
+			}
+			queues[priority] = append(queues[priority], block.BlockID)
+		case scheduled == 0 && replicas-deleting > block.Replication:
+			overReplicated++
+			// As in HDFS, excess replicas are only deleted once every
+			// replica is on a live DataNode: stale replicas can't be
+			// deleted, and may turn out to be lost
+			if !hasStaleReplica(block, dataNodes) {
+				m.trimReplicas(block, dataNodes, replicas-deleting-block.Replication, now)
+			}
+		}
+	}
+
+	// Copy the most urgent blocks first, within the streams each source allows
+	for priority := range queues {
+		sort.Strings(queues[priority])
+		for _, blockID := range queues[priority] {
+			m.scheduleReplication(blocksByID[blockID], dataNodes, now)
+		}
+	}
+
+	m.queues = queues
+	m.missingBlocks = missingBlocks
+	m.overReplicated = overReplicated
+	m.lastScan = now
+}
+
+// Status returns the state of the replication queues as of the last scan
+func (m *ReplicationMonitor) Status() *ReplicationStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := ReplicationStatus{
+		HighestPriority:       len(m.queues[PriorityHighest]),
+		VeryUnderReplicated:   len(m.queues[PriorityVeryUnderReplicated]),
+		UnderReplicated:       len(m.queues[PriorityUnderReplicated]),
+		MissingBlocks:         m.missingBlocks,
+		OverReplicated:        m.overReplicated,
+		PendingReplications:   len(m.pending),
+		ScheduledReplications: m.scheduledReplications,
+		FailedReplications:    m.failedReplications,
+		TimedOutReplications:  m.timedOutReplications,
+		ScheduledDeletions:    m.scheduledDeletions,
+		LastScan:              m.lastScan,
+	}
+	for _, replicas := range m.deleting {
+		status.PendingDeletions += len(replicas)
+	}
+	status.Pending = make([]PendingReplicationInfo, 0, len(m.pending))
+	for blockID, pending := range m.pending {
+		status.Pending = append(status.Pending, PendingReplicationInfo{
+			BlockID:     blockID,
+			Source:      pending.source,
+			Targets:     sortedKeys(pending.targets),
+			ScheduledAt: pending.scheduledAt,
+		})
+	}
+	sort.Slice(status.Pending, func(i, j int) bool {
+		return status.Pending[i].BlockID < status.Pending[j].BlockID
+	})
+	return &status
+}
+
+// Queue returns the blocks waiting for a copy at priority as of the last scan
+func (m *ReplicationMonitor) Queue(priority ReplicationPriority) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.queues[priority]...)
+}
+
+// usableReplicas counts the replicas of block on DataNodes that are not
+// dead. Stale DataNodes still count: they may only be slow, and copying
+// their blocks right away would cause needless over-replication.
+func usableReplicas(block *BlockInfo, dataNodes map[string]*DataNode) int {
+	replicas := 0
+	for _, address := range block.Locations {
+		if dataNode, exists := dataNodes[address]; exists && dataNode.State != NodeDead {
+			replicas++
+		}
+	}
+	return replicas
+}
+
+// hasStaleReplica reports whether a replica of block is on a stale DataNode
+func hasStaleReplica(block *BlockInfo, dataNodes map[string]*DataNode) bool {
+	for _, address := range block.Locations {
+		if dataNode, exists := dataNodes[address]; exists && dataNode.State == NodeStale {
+			return true
+		}
+	}
+	return false
+}
+
+// expirePending forgets copies and deletions that completed, i.e. were
+// confirmed by block reports, or that took longer than the timeout.
+// Must be called with m.mu held.
+func (m *ReplicationMonitor) expirePending(blocks []BlockInfo, now time.Time) {
+	locations := make(map[string]map[string]bool, len(blocks))
+	for _, block := range blocks {
+		set := make(map[string]bool, len(block.Locations))
+		for _, address := range block.Locations {
+			set[address] = true
+		}
+		locations[block.BlockID] = set
+	}
+
+	for blockID, pending := range m.pending {
+		for target := range pending.targets {
+			if locations[blockID][target] {
+				delete(pending.targets, target)
+			}
+		}
+		switch {
+		case locations[blockID] == nil || len(pending.targets) == 0:
+			delete(m.pending, blockID)
+		case now.Sub(pending.scheduledAt) >= m.timeout:
+			log.Printf("Replication of block %s to %v timed out", blockID, sortedKeys(pending.targets))
+			m.timedOutReplications++
+			delete(m.pending, blockID)
+		}
+	}
+
+	for blockID, replicas := range m.deleting {
+		for address, scheduledAt := range replicas {
+			if !locations[blockID][address] || now.Sub(scheduledAt) >= m.timeout {
+				delete(replicas, address)
+			}
+		}
+		if len(replicas) == 0 {
+			delete(m.deleting, blockID)
+		}
+	}
+}
+
+// scheduleReplication asks a DataNode holding block to copy it to enough new
+// DataNodes to restore its replication. Must be called with m.mu held.
+func (m *ReplicationMonitor) scheduleReplication(block *BlockInfo, dataNodes map[string]*DataNode, now time.Time) {
+	// A single copy per block is in flight at a time
+	if _, exists := m.pending[block.BlockID]; exists {
+		return
+	}
+	source := m.chooseSource(block, dataNodes)
+	if source == "" {
+		return
+	}
+
+	exclude := make(map[string]bool, len(block.Locations))
+	for _, address := range block.Locations {
+		exclude[address] = true
+	}
+
+	needed := block.Replication - usableReplicas(block, dataNodes)
+	// The source writes the new replicas
+	targets := m.placementPolicy.ChooseTargets(needed, rackOf(dataNodes[source]), block.Size, dataNodes, exclude)
+	if len(targets) == 0 {
+		return
+	}
+
+	commandID := m.dataNodeManager.QueueCommand(source, &protobuf.DataNodeCommand{
+		Type:     protobuf.DataNodeCommand_REPLICATE_BLOCK,
+		BlockIds: []string{block.BlockID},
+		Targets:  targets,
+	})
+	pending := &pendingReplication{commandID: commandID, source: source, targets: make(map[string]bool), scheduledAt: now}
+	for _, target := range targets {
+		pending.targets[target] = true
+	}
+	m.pending[block.BlockID] = pending
+	m.scheduledReplications++
+	log.Printf("Scheduled replication of block %s from %s to %v", block.BlockID, source, targets)
+}
+
+// chooseSource picks the DataNode to copy block from: a live replica if
+// possible, with the fewest copies already in progress. Must be called with
+// m.mu held.
+func (m *ReplicationMonitor) chooseSource(block *BlockInfo, dataNodes map[string]*DataNode) string {
+	streams := make(map[string]int)
+	for _, pending := range m.pending {
+		streams[pending.source]++
+	}
+
+	best := ""
+	for _, address := range block.Locations {
+		dataNode, exists := dataNodes[address]
+		if !exists || dataNode.State == NodeDead || streams[address] >= m.maxStreams {
+			continue
+		}
+		if best == "" {
+			best = address
+			continue
+		}
+		current := dataNodes[best]
+		// Prefer live over stale replicas, then the least busy DataNode
+		if (dataNode.State == NodeLive) != (current.State == NodeLive) {
+			if dataNode.State == NodeLive {
+				best = address
+			}
+			continue
+		}
+		if streams[address] < streams[best] {
+			best = address
+		}
+	}
+	return best
+}
+
+// trimReplicas asks the DataNodes the placement policy picks among those
+// holding the replicas of block to delete theirs. Must be called with m.mu held.
+func (m *ReplicationMonitor) trimReplicas(block *BlockInfo, dataNodes map[string]*DataNode, excess int, now time.Time) {
+	candidates := make([]*DataNode, 0, len(block.Locations))
+	for _, address := range block.Locations {
+		dataNode, exists := dataNodes[address]
+		if !exists || dataNode.State != NodeLive {
+			continue
+		}
+		if _, deleting := m.deleting[block.BlockID][address]; deleting {
+			continue
+		}
+		candidates = append(candidates, dataNode)
+	}
+
+	for _, address := range m.placementPolicy.ChooseExcessReplicas(excess, candidates) {
+		m.dataNodeManager.QueueBlockDeletion(address, []string{block.BlockID})
+		if m.deleting[block.BlockID] == nil {
+			m.deleting[block.BlockID] = make(map[string]time.Time)
+		}
+		m.deleting[block.BlockID][address] = now
+		m.scheduledDeletions++
+		log.Printf("Scheduled deletion of excess replica of block %s on %s", block.BlockID, address)
+	}
+}
+
+// handleAck forgets copies a DataNode failed to make, so the next scan
+// schedules them again
+func (m *ReplicationMonitor) handleAck(address string, command *protobuf.DataNodeCommand, ack *protobuf.CommandAck) {
+	if command.GetType() != protobuf.DataNodeCommand_REPLICATE_BLOCK || ack.GetSuccess() {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, blockID := range command.GetBlockIds() {
+		if pending, exists := m.pending[blockID]; exists && pending.commandID == command.GetCommandId() {
+			delete(m.pending, blockID)
+			m.failedReplications++
+		}
+	}
+}
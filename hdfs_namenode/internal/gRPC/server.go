@@ -2,67 +2,63 @@ package gRPC
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log"
-	"sync"
+	"time"
 
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
 	"github.com/aarrasseayoub01/namenode/protobuf"
 )
 
-type DataNode struct {
-	Address string
-	ID      string
-	// Add other relevant fields such as status, last heartbeat time, etc.
+// FileSystem is the part of the namespace service exposed to gRPC clients
+type FileSystem interface {
+	OpenFile(user, filePath string) (*fs.Inode, error)
+	ReadFileData(inode *fs.Inode, w io.Writer) error
+	Rename(user, srcPath, dstPath string, overwrite bool) error
+	GetContentSummary(user, path string) (*fs.ContentSummary, error)
 }
 
 // NameNodeServer implements the protobuf-defined gRPC server interface
 type NameNodeServer struct {
 	protobuf.UnimplementedNameNodeServiceServer
+	fileSystem FileSystem
 }
 
-// Package datanode_manager or a similar name
-
-type DataNodeManager struct {
-	mu        sync.RWMutex
-	dataNodes map[string]*DataNode
+func NewNameNodeServer(fileSystem FileSystem) *NameNodeServer {
+	return &NameNodeServer{fileSystem: fileSystem}
 }
 
-var instance *DataNodeManager
-var once sync.Once
-
-// GetInstance returns the singleton instance of DataNodeManager
-func GetInstance() *DataNodeManager {
-	once.Do(func() {
-		instance = &DataNodeManager{
-			dataNodes: make(map[string]*DataNode),
-		}
-	})
-	return instance
-}
+// defaultUser is the user of requests that don't name one, as in the REST API
+const defaultUser = "dr.who"
 
-func NewNameNodeServer() *NameNodeServer {
-	return &NameNodeServer{}
-}
-func (m *DataNodeManager) RegisterDataNode(address, id string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.dataNodes[address] = &DataNode{Address: address, ID: id}
+// requestUser returns the user a request runs as
+func requestUser(user string) string {
+	if user == "" {
+		return defaultUser
+	}
+	return user
 }
 
-func (m *DataNodeManager) GetDataNodes() map[string]*DataNode {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	// Return a copy of the map to avoid concurrent modifications
-	dataNodesCopy := make(map[string]*DataNode)
-	for k, v := range m.dataNodes {
-		dataNodesCopy[k] = v
+// statusError maps missing paths to NotFound and permission and snapshot
+// write errors to PermissionDenied, and other errors to code
+func statusError(err error, code codes.Code) error {
+	if _, ok := status.FromError(err); ok {
+		return err
 	}
-	return dataNodesCopy
+	switch {
+	case errors.Is(err, fs.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, fs.ErrPermissionDenied) || errors.Is(err, fs.ErrSnapshotReadOnly):
+		code = codes.PermissionDenied
+	}
+	return status.Error(code, err.Error())
 }
 
-// Other necessary methods...
-
 func (s *NameNodeServer) RegisterDataNode(ctx context.Context, req *protobuf.RegisterDataNodeRequest) (*protobuf.RegisterDataNodeResponse, error) {
 	address := req.GetDatanodeAddress()
 	log.Printf("Registering DataNode with address: %s", address)
@@ -78,15 +74,131 @@ func (s *NameNodeServer) SendHeartbeat(ctx context.Context, req *protobuf.Heartb
 	address := req.GetDatanodeAddress()
 
 	dataNodeManager := GetInstance()
-	dataNodes := dataNodeManager.GetDataNodes()
-
-	// Check if the DataNode exists in the manager
-	if dataNode, exists := dataNodes[address]; exists {
-		log.Printf("Heartbeat received from known DataNode: %s", dataNode.Address)
-	} else {
-		// Optionally handle the case where a heartbeat is received from an unknown DataNode
-		log.Printf("Heartbeat received from unknown DataNode: %s", address)
+
+	stats := DataNodeStats{
+		CapacityBytes:   req.GetCapacityBytes(),
+		DfsUsedBytes:    req.GetDfsUsedBytes(),
+		RemainingBytes:  req.GetRemainingBytes(),
+		BlockCount:      req.GetBlockCount(),
+		ActiveTransfers: req.GetActiveTransfers(),
+		VolumeFailures:  req.GetVolumeFailures(),
+	}
+
+	// Record the heartbeat if the DataNode exists in the manager
+	if !dataNodeManager.Heartbeat(address, stats) {
+		// The NameNode has no record of this DataNode, e.g. after a restart,
+		// so ask it to register again
+		log.Printf("Heartbeat received from unknown DataNode: %s, asking it to re-register", address)
+		return &protobuf.HeartbeatResponse{
+			Success:  true,
+			Commands: []*protobuf.DataNodeCommand{{Type: protobuf.DataNodeCommand_REREGISTER}},
+		}, nil
+	}
+	log.Printf("Heartbeat received from known DataNode: %s", address)
+
+	dataNodeManager.AckCommands(address, req.GetCommandAcks())
+	commands := dataNodeManager.TakeCommands(address, time.Now())
+	for _, command := range commands {
+		log.Printf("Sending command %d (%s) to DataNode %s", command.GetCommandId(), command.GetType(), address)
+	}
+
+	return &protobuf.HeartbeatResponse{Success: true, Commands: commands}, nil
+}
+
+func (s *NameNodeServer) BlockReport(ctx context.Context, req *protobuf.BlockReportRequest) (*protobuf.BlockReportResponse, error) {
+	address := req.GetDatanodeAddress()
+	if !GetInstance().IsRegistered(address) {
+		log.Printf("Block report received from unknown DataNode: %s", address)
+		return &protobuf.BlockReportResponse{Success: false}, nil
+	}
+
+	orphans := GetBlockMap().ProcessBlockReport(address, req.GetBlocks())
+	invalidateBlocks(address, orphans)
+
+	return &protobuf.BlockReportResponse{Success: true}, nil
+}
+
+func (s *NameNodeServer) BlockReceivedAndDeleted(ctx context.Context, req *protobuf.IncrementalBlockReportRequest) (*protobuf.BlockReportResponse, error) {
+	address := req.GetDatanodeAddress()
+	if !GetInstance().IsRegistered(address) {
+		log.Printf("Incremental block report received from unknown DataNode: %s", address)
+		return &protobuf.BlockReportResponse{Success: false}, nil
 	}
 
-	return &protobuf.HeartbeatResponse{Success: true}, nil
+	log.Printf("Incremental block report from %s: %d received, %d deleted", address, len(req.GetReceived()), len(req.GetDeleted()))
+	orphans := GetBlockMap().ProcessIncrementalReport(address, req.GetReceived(), req.GetDeleted())
+	invalidateBlocks(address, orphans)
+
+	return &protobuf.BlockReportResponse{Success: true}, nil
+}
+
+// invalidateBlocks asks the DataNode at address to delete blocks that are no
+// longer part of the namespace
+func invalidateBlocks(address string, blockIDs []string) {
+	if queued := GetInstance().QueueBlockDeletion(address, blockIDs); len(queued) > 0 {
+		log.Printf("Asking DataNode %s to delete blocks %v", address, queued)
+	}
+}
+
+func (s *NameNodeServer) Rename(ctx context.Context, req *protobuf.RenameRequest) (*protobuf.RenameResponse, error) {
+	log.Printf("Renaming %s to %s", req.GetSrcPath(), req.GetDstPath())
+
+	if err := s.fileSystem.Rename(requestUser(req.GetUser()), req.GetSrcPath(), req.GetDstPath(), req.GetOverwrite()); err != nil {
+		return nil, statusError(err, codes.FailedPrecondition)
+	}
+	return &protobuf.RenameResponse{Success: true}, nil
+}
+
+func (s *NameNodeServer) GetContentSummary(ctx context.Context, req *protobuf.ContentSummaryRequest) (*protobuf.ContentSummaryResponse, error) {
+	summary, err := s.fileSystem.GetContentSummary(requestUser(req.GetUser()), req.GetPath())
+	if err != nil {
+		return nil, statusError(err, codes.FailedPrecondition)
+	}
+	return &protobuf.ContentSummaryResponse{
+		Length:            summary.Length,
+		FileCount:         summary.FileCount,
+		DirectoryCount:    summary.DirectoryCount,
+		SpaceConsumed:     summary.SpaceConsumed,
+		NamespaceConsumed: summary.NamespaceConsumed,
+		NamespaceQuota:    summary.NamespaceQuota,
+		SpaceQuota:        summary.SpaceQuota,
+	}, nil
+}
+
+// readFileChunkSize caps the size of each message streamed by ReadFile
+const readFileChunkSize = 1024 * 1024
+
+// fileStreamWriter adapts a ReadFile stream to an io.Writer
+type fileStreamWriter struct {
+	stream protobuf.NameNodeService_ReadFileServer
+}
+
+func (w *fileStreamWriter) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		end := written + readFileChunkSize
+		if end > len(p) {
+			end = len(p)
+		}
+		if err := w.stream.Send(&protobuf.ReadFileResponse{Data: p[written:end]}); err != nil {
+			return written, err
+		}
+		written = end
+	}
+	return written, nil
+}
+
+func (s *NameNodeServer) ReadFile(req *protobuf.ReadFileRequest, stream protobuf.NameNodeService_ReadFileServer) error {
+	filePath := req.GetFilePath()
+	log.Printf("Streaming file %s", filePath)
+
+	inode, err := s.fileSystem.OpenFile(requestUser(req.GetUser()), filePath)
+	if err != nil {
+		return statusError(err, codes.NotFound)
+	}
+	if err := s.fileSystem.ReadFileData(inode, &fileStreamWriter{stream: stream}); err != nil {
+		// Other errors mean no replica of some block could be read
+		return statusError(err, codes.Unavailable)
+	}
+	return nil
 }
@@ -0,0 +1,112 @@
+package gRPC
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// DefaultRack is the network location of DataNodes the topology does not know
+const DefaultRack = "/default-rack"
+
+// TopologyResolver maps a DataNode or client address to its rack, a network
+// location path such as "/dc1/rack1"
+type TopologyResolver interface {
+	ResolveRack(address string) string
+}
+
+// TopologyResolverFunc adapts a function to a TopologyResolver
+type TopologyResolverFunc func(address string) string
+
+func (f TopologyResolverFunc) ResolveRack(address string) string {
+	return f(address)
+}
+
+// StaticTopology resolves racks from a fixed mapping of hosts or addresses
+type StaticTopology struct {
+	racks map[string]string
+}
+
+// NewStaticTopology creates a StaticTopology from a mapping of host names, IP
+// addresses or host:port addresses to racks
+func NewStaticTopology(racks map[string]string) (*StaticTopology, error) {
+	topology := &StaticTopology{racks: make(map[string]string, len(racks))}
+	for host, rack := range racks {
+		normalized, err := normalizeRack(rack)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rack for %s: %v", host, err)
+		}
+		topology.racks[host] = normalized
+	}
+	return topology, nil
+}
+
+// LoadTopologyFile reads a StaticTopology from a mapping file. Each line holds
+// a host, IP address or host:port address followed by its rack; blank lines
+// and lines starting with '#' are ignored.
+func LoadTopologyFile(path string) (*StaticTopology, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	racks := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a host and a rack", path, lineNumber)
+		}
+		racks[fields[0]] = fields[1]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	topology, err := NewStaticTopology(racks)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return topology, nil
+}
+
+// ResolveRack returns the rack mapped to address, or to its host, falling
+// back to DefaultRack
+func (t *StaticTopology) ResolveRack(address string) string {
+	if rack, exists := t.racks[address]; exists {
+		return rack
+	}
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		if rack, exists := t.racks[host]; exists {
+			return rack
+		}
+	}
+	return DefaultRack
+}
+
+// normalizeRack turns a rack into an absolute path without a trailing slash
+func normalizeRack(rack string) (string, error) {
+	rack = strings.TrimRight(strings.TrimSpace(rack), "/")
+	if rack == "" {
+		return "", fmt.Errorf("rack is empty")
+	}
+	if !strings.HasPrefix(rack, "/") {
+		rack = "/" + rack
+	}
+	return rack, nil
+}
+
+// rackOf returns the rack of a DataNode, treating unresolved ones as DefaultRack
+func rackOf(dataNode *DataNode) string {
+	if dataNode.Rack == "" {
+		return DefaultRack
+	}
+	return dataNode.Rack
+}
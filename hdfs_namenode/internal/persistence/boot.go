@@ -1,7 +1,7 @@
 package persistence
 
 import (
-	"fmt"
+	"errors"
 	"log"
 	"os"
 	"time"
@@ -11,18 +11,50 @@ import (
 
 var rootDirectory *fs.Directory
 
+// InitializeFileSystem loads the namespace from the most recent fsimage, if
+// there is one, and replays the edit log on top of it. An image that fails to
+// load is set aside, and the one before it loaded instead, with the edits
+// since then, unless this NameNode doesn't read its layout.
 func InitializeFileSystem() *fs.Directory {
-	fsImageExists := checkFsImageExists("fsimage.gob")
+	// The namespace is replaced under any checkpoint being taken
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
 
-	if fsImageExists {
-		var err error
-		rootDirectory, err = loadFsImage("fsimage.gob")
+	if checkFsImageExists(legacyFsImageFileName) {
+		log.Fatalf("Failed to load filesystem image %s: %v", legacyFsImageFileName, errUnsupportedFsImageLayout)
+	}
+	images, err := listFsImages()
+	if err != nil {
+		log.Fatalf("Failed to list filesystem images: %v", err)
+	}
+	var paths []string
+	for _, txID := range images {
+		paths = append(paths, fsImageFileName(txID))
+	}
+
+	var imageTxID int64
+	fsImageExists := false
+	rootDirectory = nil
+	for i := len(paths) - 1; i >= 0 && rootDirectory == nil; i-- {
+		if !checkFsImageExists(paths[i]) {
+			continue
+		}
+		fsImageExists = true
+		rootDirectory, imageTxID, err = loadFsImage(paths[i])
+		if errors.Is(err, errNewerFsImageLayout) || errors.Is(err, errUnsupportedFsImageLayout) {
+			log.Fatalf("Failed to load filesystem image %s: %v", paths[i], err)
+		}
 		if err != nil {
-			log.Fatalf("Failed to load filesystem image: %v", err)
+			log.Printf("Failed to load filesystem image %s: %v", paths[i], err)
+			if err := os.Rename(paths[i], paths[i]+corruptSuffix); err != nil {
+				log.Fatalf("Failed to set aside filesystem image %s: %v", paths[i], err)
+			}
+		}
+	}
+	if rootDirectory == nil {
+		if fsImageExists {
+			log.Fatalf("No filesystem image could be loaded")
 		}
-		loadEditLog()
-		replayEditLog(rootDirectory)
-	} else {
 		rootDirectory = &fs.Directory{
 			Inode: &fs.Inode{
 				ID:        1, // root directory ID, usually 1
@@ -36,32 +68,13 @@ func InitializeFileSystem() *fs.Directory {
 			ChildDirs:  make(map[string]*fs.Directory),
 		}
 	}
-	return rootDirectory
-}
 
-func checkpointFileSystem() {
-
-	err := saveFsImage(rootDirectory, "fsimage.gob")
-	if err != nil {
-		log.Printf("Error during filesystem checkpoint: %v", err)
-	}
-}
-
-func triggerCheckpoint() error {
-
-	editLogMutex.Lock()
-	defer editLogMutex.Unlock()
-
-	// Save the current state of the filesystem
-	err := saveFsImage(rootDirectory, "fsimage.gob")
+	entries, err := loadEditLog(imageTxID)
 	if err != nil {
-		return fmt.Errorf("failed to save FsImage: %w", err)
+		log.Fatalf("Failed to load edit log: %v", err)
 	}
-
-	// Clear the edit log
-	editLog = []EditLogEntry{}
-
-	return nil
+	replayEditLog(rootDirectory, entries)
+	return rootDirectory
 }
 
 func checkFsImageExists(path string) bool {
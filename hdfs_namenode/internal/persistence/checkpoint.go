@@ -1,33 +1,135 @@
 package persistence
 
 import (
+	"fmt"
+	"log"
+	"sync"
 	"time"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
 )
 
+// Checkpoints are taken in the background, so requests only pay for appending
+// to the edit log: the namespace is copied as of the last transaction, with
+// writers kept out only for the copy, and the edit log rolled there; the copy
+// is then saved to an fsimage while requests go on.
+
 var (
-	lastCheckpointTime   time.Time
-	editLogSizeThreshold int
-	checkpointInterval   time.Duration
+	checkpointTxns     = 10000
+	checkpointPeriod   = time.Hour
+	lastCheckpointTime = time.Now()
+
+	// checkpointMutex lets a single checkpoint be taken at a time
+	checkpointMutex sync.Mutex
+	// checkpointRequests wakes the checkpointer once checkpointTxns
+	// transactions were logged since the last checkpoint
+	checkpointRequests = make(chan struct{}, 1)
+	// namespaceLock keeps writers out of the namespace while it is copied
+	namespaceLock sync.Locker = &sync.Mutex{}
 )
 
-func init() {
-	lastCheckpointTime = time.Now()
-	editLogSizeThreshold = 3
-	checkpointInterval = 1 * time.Minute
+// SetCheckpointPolicy configures how many transactions, or how long, the
+// namespace goes without a checkpoint at most
+func SetCheckpointPolicy(txns int, period time.Duration) {
+	editLogMutex.Lock()
+	defer editLogMutex.Unlock()
+	checkpointTxns = txns
+	checkpointPeriod = period
+}
+
+// SetNamespaceLock sets the lock writers hold while they change the namespace
+// and log the change
+func SetNamespaceLock(lock sync.Locker) {
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	namespaceLock = lock
+}
+
+// StartCheckpointer takes checkpoints in the background until stop is closed,
+// whenever the checkpoint policy calls for one
+func StartCheckpointer(stop <-chan struct{}) {
+	editLogMutex.Lock()
+	interval := min(checkpointPeriod, time.Minute)
+	editLogMutex.Unlock()
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			if shouldTriggerCheckpoint() {
+				checkpointFileSystem()
+			}
+			select {
+			case <-ticker.C:
+			case <-checkpointRequests:
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// SaveNamespace takes a checkpoint right away and returns the ID of the last
+// transaction it includes
+func SaveNamespace() (int64, error) {
+	return triggerCheckpoint()
 }
 
 func shouldTriggerCheckpoint() bool {
 	editLogMutex.Lock()
 	defer editLogMutex.Unlock()
+	return editsSinceCheckpoint >= checkpointTxns ||
+		editsSinceCheckpoint > 0 && time.Since(lastCheckpointTime) >= checkpointPeriod
+}
 
-	editLogSize := len(editLog)
-	timeSinceLastCheckpoint := time.Since(lastCheckpointTime)
+// requestCheckpoint wakes the checkpointer, unless it was already woken. Must
+// be called with editLogMutex held.
+func requestCheckpoint() {
+	select {
+	case checkpointRequests <- struct{}{}:
+	default:
+	}
+}
 
-	// Check if the edit log size exceeds the threshold or if enough time has elapsed
-	if editLogSize >= editLogSizeThreshold || timeSinceLastCheckpoint >= checkpointInterval {
-		lastCheckpointTime = time.Now() // Reset the checkpoint time
-		return true
+func checkpointFileSystem() {
+	if _, err := triggerCheckpoint(); err != nil {
+		log.Printf("Error during filesystem checkpoint: %v", err)
 	}
+}
 
-	return false
+// triggerCheckpoint copies the namespace and rolls the edit log at the last
+// transaction, saves the copy to an fsimage named after it, then purges the
+// checkpoints and segments no longer retained
+func triggerCheckpoint() (int64, error) {
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+
+	if rootDirectory == nil {
+		return 0, fmt.Errorf("the namespace is not loaded")
+	}
+	namespaceLock.Lock()
+	root := fs.CopyNamespace(rootDirectory)
+	editLogMutex.Lock()
+	txID := lastTxID
+	err := rollEditLog()
+	if err == nil {
+		editsSinceCheckpoint = 0
+		lastCheckpointTime = time.Now()
+	}
+	editLogMutex.Unlock()
+	namespaceLock.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to roll the edit log: %w", err)
+	}
+
+	if err := saveFsImage(root, txID, fsImageFileName(txID)); err != nil {
+		return 0, fmt.Errorf("failed to save FsImage: %w", err)
+	}
+
+	editLogMutex.Lock()
+	defer editLogMutex.Unlock()
+	if err := purgeCheckpoints(); err != nil {
+		return 0, fmt.Errorf("failed to purge old checkpoints: %w", err)
+	}
+	return txID, nil
 }
@@ -0,0 +1,92 @@
+package persistence
+
+import (
+	"fmt"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// The payload of an edit log record is an EditLogOp, documented in
+// protobuf/editlog.proto. Entry actions are the names of its op types.
+
+// encodeEditLogOp returns the op recording entry
+func encodeEditLogOp(entry EditLogEntry) (*protobuf.EditLogOp, error) {
+	opType, known := protobuf.EditLogOp_Type_value[entry.Action]
+	if !known || opType == int32(protobuf.EditLogOp_UNKNOWN) {
+		return nil, fmt.Errorf("unknown edit log action %q", entry.Action)
+	}
+	op := &protobuf.EditLogOp{
+		Type:            protobuf.EditLogOp_Type(opType),
+		Timestamp:       unixNano(entry.Timestamp),
+		Path:            entry.Path,
+		Destination:     entry.Destination,
+		Overwrite:       entry.Overwrite,
+		Owner:           entry.Owner,
+		Group:           entry.Group,
+		Permission:      uint32(entry.Permission),
+		Acl:             encodeAcl(entry.Acl),
+		NamespaceQuota:  entry.NamespaceQuota,
+		SpaceQuota:      entry.SpaceQuota,
+		SnapshotName:    entry.SnapshotName,
+		SnapshotNewName: entry.SnapshotNewName,
+	}
+	if inode := entry.Inode; inode != nil {
+		op.Inode = &protobuf.EditLogInode{
+			Id:          inode.ID,
+			Name:        inode.Name,
+			Owner:       inode.Owner,
+			Group:       inode.Group,
+			IsDir:       inode.IsDir,
+			Size:        inode.Size,
+			Timestamp:   unixNano(inode.Timestamp),
+			Replication: int32(inode.Replication),
+			Permission:  uint32(inode.Permission),
+			Acl:         encodeAcl(inode.Acl),
+		}
+		for _, block := range inode.Blocks {
+			op.Inode.Blocks = append(op.Inode.Blocks, &protobuf.EditLogBlock{BlockId: block.BlockID, Locations: block.DataNodeAddresses})
+		}
+	}
+	return op, nil
+}
+
+// decodeEditLogOp returns the entry recorded by op
+func decodeEditLogOp(op *protobuf.EditLogOp) (EditLogEntry, error) {
+	if op.GetType() == protobuf.EditLogOp_UNKNOWN {
+		return EditLogEntry{}, fmt.Errorf("edit log op has unknown type %d", op.GetType())
+	}
+	entry := EditLogEntry{
+		Timestamp:       fromUnixNano(op.GetTimestamp()),
+		Action:          op.GetType().String(),
+		Path:            op.GetPath(),
+		Destination:     op.GetDestination(),
+		Overwrite:       op.GetOverwrite(),
+		Owner:           op.GetOwner(),
+		Group:           op.GetGroup(),
+		Permission:      fs.Permission(op.GetPermission()),
+		Acl:             decodeAcl(op.GetAcl()),
+		NamespaceQuota:  op.GetNamespaceQuota(),
+		SpaceQuota:      op.GetSpaceQuota(),
+		SnapshotName:    op.GetSnapshotName(),
+		SnapshotNewName: op.GetSnapshotNewName(),
+	}
+	if encoded := op.GetInode(); encoded != nil {
+		entry.Inode = &fs.Inode{
+			ID:          encoded.GetId(),
+			Name:        encoded.GetName(),
+			Owner:       encoded.GetOwner(),
+			Group:       encoded.GetGroup(),
+			IsDir:       encoded.GetIsDir(),
+			Size:        encoded.GetSize(),
+			Timestamp:   fromUnixNano(encoded.GetTimestamp()),
+			Replication: int(encoded.GetReplication()),
+			Permission:  fs.Permission(encoded.GetPermission()),
+			Acl:         decodeAcl(encoded.GetAcl()),
+		}
+		for _, block := range encoded.GetBlocks() {
+			entry.Inode.Blocks = append(entry.Inode.Blocks, fs.BlockAssignment{BlockID: block.GetBlockId(), DataNodeAddresses: block.GetLocations()})
+		}
+	}
+	return entry, nil
+}
@@ -1,57 +1,250 @@
 package persistence
 
 import (
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"hash/crc32"
+	"io"
+	"log"
 	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
+	"google.golang.org/protobuf/proto"
+
 	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/protobuf"
 )
 
 type EditLogEntry struct {
+	// TxID is the transaction ID of the entry, increasing by one with every
+	// entry written; it is stored in the record framing the entry
+	TxID      int64 `json:"-"`
 	Timestamp time.Time
 	Action    string
 	Inode     *fs.Inode
 	Path      string
-	Blocks    *fs.AllocateFileBlocksResponse
+	// Destination and Overwrite are set for RENAME entries, which move Path
+	Destination string `json:",omitempty"`
+	Overwrite   bool   `json:",omitempty"`
+	// Owner, Group and Permission are set for SET_OWNER and SET_PERMISSION
+	// entries; an empty owner or group is left unchanged
+	Owner      string        `json:",omitempty"`
+	Group      string        `json:",omitempty"`
+	Permission fs.Permission `json:",omitempty"`
+	// Acl is the extended ACL set by SET_ACL entries, along with Permission
+	Acl []fs.AclEntry `json:",omitempty"`
+	// NamespaceQuota and SpaceQuota are set by SET_QUOTA entries; 0 clears them
+	NamespaceQuota int64 `json:",omitempty"`
+	SpaceQuota     int64 `json:",omitempty"`
+	// SnapshotName names the snapshot of the directory at Path that
+	// CREATE_SNAPSHOT, DELETE_SNAPSHOT and RENAME_SNAPSHOT entries change;
+	// SnapshotNewName is set for RENAME_SNAPSHOT
+	SnapshotName    string `json:",omitempty"`
+	SnapshotNewName string `json:",omitempty"`
 }
 
-const editLogFileName = "editlog.json"
+// The edit log is a write-ahead log of the namespace changes, split into
+// segments (see segments.go). Each segment file starts with the magic "HEDL"
+// and a uint32 format version, followed by one record per entry:
+//
+//	length   uint32  length of the payload
+//	checksum uint32  CRC-32C of the payload
+//	payload          uint64 transaction ID, then the entry as an EditLogOp
+//
+// All integers are big-endian, and EditLogOp is the protobuf message of
+// protobuf/editlog.proto. Every record is fsync'd before the change it
+// records is made.
+const (
+	// legacyEditLogFileName is the JSON edit log written before transaction
+	// IDs, which is moved into the edit log on startup
+	legacyEditLogFileName = "editlog.json"
+
+	editLogMagic      = "HEDL"
+	editLogVersion    = 1
+	editLogHeaderSize = 8
+	recordHeaderSize  = 8
+	// maxRecordSize bounds the payload of a record, so a corrupt length isn't
+	// taken for a huge record
+	maxRecordSize = 64 << 20
+)
 
 var (
-	editLog      []EditLogEntry
-	editLogMutex sync.Mutex
+	// lastTxID is the ID of the last transaction written to the edit log
+	lastTxID int64
+	// editsSinceCheckpoint counts the transactions not in the fsimage yet
+	editsSinceCheckpoint int
+	// editLogFailure is set once a failed write could not be undone, after
+	// which no transaction is written until the NameNode restarts
+	editLogFailure error
+	editLogMutex   sync.Mutex
+
+	crc32c = crc32.MakeTable(crc32.Castagnoli)
 )
 
-func RecordEditLog(action string, path string, inode *fs.Inode) {
-	// editLogMutex.Lock()
-	// defer editLogMutex.Unlock()
-	entry := EditLogEntry{
+// RecordEditLog records action on the file or directory at path; inode is
+// the one created by CREATE_FILE and CREATE_DIRECTORY
+func RecordEditLog(action string, path string, inode *fs.Inode) error {
+	return recordEditLogEntry(EditLogEntry{
 		Timestamp: time.Now(),
 		Action:    action,
 		Path:      path,
 		Inode:     inode,
+	})
+}
+
+// RecordRename records the move of srcPath to dstPath as a single entry
+func RecordRename(srcPath, dstPath string, overwrite bool) error {
+	return recordEditLogEntry(EditLogEntry{
+		Timestamp:   time.Now(),
+		Action:      "RENAME",
+		Path:        srcPath,
+		Destination: dstPath,
+		Overwrite:   overwrite,
+	})
+}
+
+// RecordSetPermission records a change of the permission of path
+func RecordSetPermission(path string, permission fs.Permission) error {
+	return recordEditLogEntry(EditLogEntry{
+		Timestamp:  time.Now(),
+		Action:     "SET_PERMISSION",
+		Path:       path,
+		Permission: permission,
+	})
+}
+
+// RecordSetOwner records a change of the owner or group of path
+func RecordSetOwner(path, owner, group string) error {
+	return recordEditLogEntry(EditLogEntry{
+		Timestamp: time.Now(),
+		Action:    "SET_OWNER",
+		Path:      path,
+		Owner:     owner,
+		Group:     group,
+	})
+}
+
+// RecordSetAcl records a change of the ACL of path, and of the permission bits
+// that hold part of it
+func RecordSetAcl(path string, permission fs.Permission, acl []fs.AclEntry) error {
+	return recordEditLogEntry(EditLogEntry{
+		Timestamp:  time.Now(),
+		Action:     "SET_ACL",
+		Path:       path,
+		Permission: permission,
+		Acl:        acl,
+	})
+}
+
+// RecordSetQuota records a change of the quotas of the directory at path
+func RecordSetQuota(path string, namespaceQuota, spaceQuota int64) error {
+	return recordEditLogEntry(EditLogEntry{
+		Timestamp:      time.Now(),
+		Action:         "SET_QUOTA",
+		Path:           path,
+		NamespaceQuota: namespaceQuota,
+		SpaceQuota:     spaceQuota,
+	})
+}
+
+// RecordCreateSnapshot records a snapshot taken of the directory at path. The
+// entry's timestamp is the snapshot's, so replaying it takes the same one.
+func RecordCreateSnapshot(path, name string, timestamp time.Time) error {
+	return recordEditLogEntry(EditLogEntry{
+		Timestamp:    timestamp,
+		Action:       "CREATE_SNAPSHOT",
+		Path:         path,
+		SnapshotName: name,
+	})
+}
+
+// RecordDeleteSnapshot records the deletion of a snapshot of the directory at path
+func RecordDeleteSnapshot(path, name string) error {
+	return recordEditLogEntry(EditLogEntry{
+		Timestamp:    time.Now(),
+		Action:       "DELETE_SNAPSHOT",
+		Path:         path,
+		SnapshotName: name,
+	})
+}
+
+// RecordRenameSnapshot records the renaming of a snapshot of the directory at path
+func RecordRenameSnapshot(path, oldName, newName string) error {
+	return recordEditLogEntry(EditLogEntry{
+		Timestamp:       time.Now(),
+		Action:          "RENAME_SNAPSHOT",
+		Path:            path,
+		SnapshotName:    oldName,
+		SnapshotNewName: newName,
+	})
+}
+
+// recordEditLogEntry gives the entry the next transaction ID and makes it
+// durable before returning. The change must not be made if it fails.
+func recordEditLogEntry(entry EditLogEntry) error {
+	editLogMutex.Lock()
+	defer editLogMutex.Unlock()
+
+	if editLogFailure != nil {
+		return fmt.Errorf("edit log is unavailable: %w", editLogFailure)
+	}
+	if editLogFile == nil {
+		if err := openSegment(lastTxID + 1); err != nil {
+			return fmt.Errorf("opening the edit log: %w", err)
+		}
 	}
-	editLog = append(editLog, entry)
-	if shouldTriggerCheckpoint() {
-		triggerCheckpoint()
+	entry.TxID = lastTxID + 1
+	written, err := appendEditLogRecord(editLogFile, entry)
+	if err != nil {
+		// Whatever part of the record was written is cut off, so the
+		// transaction ID can be given to the next entry
+		if err := truncateSegment(); err != nil {
+			editLogFailure = err
+			log.Printf("Failed to truncate the edit log after a failed write: %v", err)
+		}
+		return fmt.Errorf("writing transaction %d to the edit log: %w", entry.TxID, err)
 	}
-	saveEditLog()
+	lastTxID = entry.TxID
+	editsSinceCheckpoint++
+	segmentSize += int64(written)
+	if segmentSize >= segmentSizeThreshold || time.Since(segmentOpened) >= segmentRollInterval {
+		// The entry is durable already, and the next one opens a new
+		// segment if the roll left none
+		if err := rollEditLog(); err != nil {
+			log.Printf("Failed to roll the edit log: %v", err)
+		}
+	}
+	if editsSinceCheckpoint >= checkpointTxns {
+		requestCheckpoint()
+	}
+	return nil
 }
 
-// Usage: recordEditLog("CREATE", inode)
+// truncateSegment cuts the in-progress segment back to the records written to
+// it. Must be called with editLogMutex held.
+func truncateSegment() error {
+	if err := editLogFile.Truncate(segmentSize); err != nil {
+		return err
+	}
+	if _, err := editLogFile.Seek(segmentSize, io.SeekStart); err != nil {
+		return err
+	}
+	return editLogFile.Sync()
+}
 
-func replayEditLog(root *fs.Directory) {
-	for _, entry := range editLog {
+// replayEditLog applies entries to the namespace loaded from the fsimage
+func replayEditLog(root *fs.Directory, entries []EditLogEntry) {
+	for _, entry := range entries {
 		// Split the path to get parent directory and target name
 		dirPath, targetName := filepath.Split(entry.Path)
 
-		// Find the target directory based on dirPath
-		targetDir := fs.FindDirectory(root, dirPath)
+		// Find the target directory based on dirPath, copying what
+		// snapshots share on the way
+		targetDir := fs.MutableDirectory(root, dirPath)
 
 		switch entry.Action {
 		case "CREATE_FILE":
@@ -70,53 +263,184 @@ func replayEditLog(root *fs.Directory) {
 		case "DELETE_DIRECTORY":
 			delete(targetDir.ChildDirs, targetName)
 
+		case "SET_PERMISSION":
+			if inode := fs.MutableInode(root, entry.Path); inode != nil {
+				inode.Permission = entry.Permission
+			}
+
+		case "SET_ACL":
+			if inode := fs.MutableInode(root, entry.Path); inode != nil {
+				inode.Permission = entry.Permission
+				inode.Acl = entry.Acl
+			}
+
+		case "SET_QUOTA":
+			// The usage is recalculated once the namespace is loaded
+			if dir := fs.MutableDirectory(root, entry.Path); dir != nil {
+				dir.Quota = nil
+				if entry.NamespaceQuota > 0 || entry.SpaceQuota > 0 {
+					dir.Quota = &fs.Quota{NamespaceQuota: entry.NamespaceQuota, SpaceQuota: entry.SpaceQuota}
+				}
+			}
+
+		case "SET_OWNER":
+			if inode := fs.MutableInode(root, entry.Path); inode != nil {
+				if entry.Owner != "" {
+					inode.Owner = entry.Owner
+				}
+				if entry.Group != "" {
+					inode.Group = entry.Group
+				}
+			}
+
+		case "ALLOW_SNAPSHOT", "DISALLOW_SNAPSHOT":
+			if dir := fs.MutableDirectory(root, entry.Path); dir != nil {
+				dir.Snapshottable = entry.Action == "ALLOW_SNAPSHOT"
+			}
+
+		case "CREATE_SNAPSHOT", "DELETE_SNAPSHOT", "RENAME_SNAPSHOT":
+			if err := replaySnapshotEntry(root, entry); err != nil {
+				fmt.Printf("Error replaying %s of %s: %v\n", entry.Action, entry.Path, err)
+			}
+
+		case "RENAME":
+			if _, err := fs.Rename(root, entry.Path, entry.Destination, entry.Overwrite); err != nil {
+				fmt.Printf("Error replaying rename of %s to %s: %v\n", entry.Path, entry.Destination, err)
+			}
+
 		}
 	}
 }
 
-func loadEditLog() {
-	// Check if the editlog file exists
-	if _, err := os.Stat(editLogFileName); os.IsNotExist(err) {
-		// Editlog file does not exist, create an empty editlog
-		editLog = []EditLogEntry{}
-		return
+func replaySnapshotEntry(root *fs.Directory, entry EditLogEntry) error {
+	dir := fs.MutableDirectory(root, entry.Path)
+	if dir == nil {
+		return fmt.Errorf("directory does not exist")
+	}
+	var err error
+	switch entry.Action {
+	case "CREATE_SNAPSHOT":
+		_, err = fs.CreateSnapshot(dir, entry.SnapshotName, entry.Timestamp)
+	case "DELETE_SNAPSHOT":
+		_, err = fs.DeleteSnapshot(dir, entry.SnapshotName)
+	case "RENAME_SNAPSHOT":
+		err = fs.RenameSnapshot(dir, entry.SnapshotName, entry.SnapshotNewName)
 	}
+	return err
+}
 
-	// Read the editlog file
-	data, err := ioutil.ReadFile(editLogFileName)
+// appendEditLogRecord writes entry to the end of file as a single record and
+// fsyncs it, returning the size of the record
+func appendEditLogRecord(file *os.File, entry EditLogEntry) (int, error) {
+	op, err := encodeEditLogOp(entry)
 	if err != nil {
-		fmt.Printf("Error reading editlog file: %v\n", err)
-		return
+		return 0, err
 	}
-
-	// Deserialize the editlog
-	err = json.Unmarshal(data, &editLog)
+	encoded, err := proto.Marshal(op)
 	if err != nil {
-		fmt.Printf("Error decoding editlog data: %v\n", err)
-		return
+		return 0, err
 	}
+	record := make([]byte, recordHeaderSize+8+len(encoded))
+	payload := record[recordHeaderSize:]
+	binary.BigEndian.PutUint64(payload, uint64(entry.TxID))
+	copy(payload[8:], encoded)
+	binary.BigEndian.PutUint32(record, uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:], crc32.Checksum(payload, crc32c))
+
+	if _, err := file.Write(record); err != nil {
+		return 0, err
+	}
+	return len(record), file.Sync()
 }
 
-func saveEditLog() {
-	// Serialize the editlog
-	data, err := json.MarshalIndent(editLog, "", "  ")
-	if err != nil {
-		fmt.Printf("Error encoding editlog data: %v\n", err)
-		return
+// decodeEditLog decodes the entries of an edit log file, returning them with
+// the length of the valid prefix of data. It stops at a torn tail: a record
+// that is incomplete or fails its checksum with no valid record after it. A
+// bad record followed by valid ones is corruption and fails the decode.
+func decodeEditLog(data []byte) ([]EditLogEntry, int, error) {
+	if len(data) < editLogHeaderSize {
+		return nil, 0, nil
+	}
+	if string(data[:4]) != editLogMagic {
+		return nil, 0, errors.New("edit log has an invalid header")
+	}
+	if version := binary.BigEndian.Uint32(data[4:]); version != editLogVersion {
+		return nil, 0, fmt.Errorf("edit log has unsupported version %d", version)
 	}
 
-	// Write the editlog to the file
-	err = ioutil.WriteFile(editLogFileName, data, 0644)
-	if err != nil {
-		fmt.Printf("Error writing editlog file: %v\n", err)
-		return
+	var entries []EditLogEntry
+	var lastTxID int64
+	offset := editLogHeaderSize
+	for offset < len(data) {
+		payload, ok := decodeEditLogRecord(data, offset)
+		if !ok {
+			if validRecordAfter(data, offset, lastTxID) {
+				return nil, 0, fmt.Errorf("edit log record at offset %d is corrupt but valid records follow it", offset)
+			}
+			break
+		}
+		op := &protobuf.EditLogOp{}
+		if err := proto.Unmarshal(payload[8:], op); err != nil {
+			return nil, 0, fmt.Errorf("decoding edit log record at offset %d: %w", offset, err)
+		}
+		entry, err := decodeEditLogOp(op)
+		if err != nil {
+			return nil, 0, fmt.Errorf("decoding edit log record at offset %d: %w", offset, err)
+		}
+		entry.TxID = int64(binary.BigEndian.Uint64(payload))
+		if len(entries) > 0 && entry.TxID <= lastTxID {
+			return nil, 0, fmt.Errorf("edit log record at offset %d holds transaction %d after %d", offset, entry.TxID, lastTxID)
+		}
+		entries = append(entries, entry)
+		lastTxID = entry.TxID
+		offset += recordHeaderSize + len(payload)
+	}
+	return entries, offset, nil
+}
+
+// decodeEditLogRecord returns the payload of the record at offset of data, if
+// it is complete and matches its checksum
+func decodeEditLogRecord(data []byte, offset int) ([]byte, bool) {
+	if len(data)-offset < recordHeaderSize {
+		return nil, false
 	}
+	length := int(binary.BigEndian.Uint32(data[offset:]))
+	checksum := binary.BigEndian.Uint32(data[offset+4:])
+	if length < 8 || length > maxRecordSize || len(data)-offset-recordHeaderSize < length {
+		return nil, false
+	}
+	payload := data[offset+recordHeaderSize : offset+recordHeaderSize+length]
+	if crc32.Checksum(payload, crc32c) != checksum {
+		return nil, false
+	}
+	return payload, true
+}
 
-	// If the editlog is empty, delete the editlog file
-	if len(editLog) == 0 {
-		err := os.Remove(editLogFileName)
-		if err != nil && !os.IsNotExist(err) {
-			fmt.Printf("Error deleting editlog file: %v\n", err)
+// validRecordAfter reports whether a valid record of a transaction after
+// lastTxID starts anywhere in data past offset, which tells a record corrupted
+// in the middle of an edit log from one torn by a crash at its end
+func validRecordAfter(data []byte, offset int, lastTxID int64) bool {
+	for start := offset + 1; len(data)-start >= recordHeaderSize; start++ {
+		if payload, ok := decodeEditLogRecord(data, start); ok && int64(binary.BigEndian.Uint64(payload)) > lastTxID {
+			return true
 		}
 	}
+	return false
+}
+
+// loadLegacyEditLog reads the JSON edit log written before transaction IDs,
+// if there is one
+func loadLegacyEditLog() ([]EditLogEntry, error) {
+	data, err := os.ReadFile(legacyEditLogFileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []EditLogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", legacyEditLogFileName, err)
+	}
+	return entries, nil
 }
@@ -1,33 +1,154 @@
 package persistence
 
 import (
-	"encoding/gob"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
 )
 
-func saveFsImage(dir *fs.Directory, path string) error {
-	file, err := os.Create(path)
+// legacyFsImageFileName is the single gob encoded fsimage written before
+// images were named after the last transaction they include, which is no
+// longer read
+const legacyFsImageFileName = "fsimage.gob"
+
+const (
+	// digestSuffix names the file holding the MD5 digest of an fsimage
+	digestSuffix = ".md5"
+	// checkpointSuffix names the temporary file an fsimage is written to
+	checkpointSuffix = ".ckpt"
+	// corruptSuffix is added to the name of an fsimage that failed to load,
+	// so it is no longer taken for a checkpoint
+	corruptSuffix = ".corrupt"
+)
+
+func fsImageFileName(lastTxID int64) string {
+	return fmt.Sprintf("fsimage_%019d", lastTxID)
+}
+
+// listFsImages returns the last transaction IDs of the fsimages, oldest first
+func listFsImages() ([]int64, error) {
+	names, err := filepath.Glob("fsimage_*")
+	if err != nil {
+		return nil, err
+	}
+	var images []int64
+	for _, name := range names {
+		if txID, err := strconv.ParseInt(strings.TrimPrefix(name, "fsimage_"), 10, 64); err == nil {
+			images = append(images, txID)
+		}
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i] < images[j] })
+	return images, nil
+}
+
+// saveFsImage writes an fsimage so that a crash at any point leaves either the
+// complete new image or none at all: it is written to a temporary file, which
+// is fsync'd and renamed into place once its digest file is written
+func saveFsImage(dir *fs.Directory, lastTxID int64, path string) error {
+	tmpPath := path + checkpointSuffix
+	file, err := os.Create(tmpPath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	defer os.Remove(tmpPath)
 
-	encoder := gob.NewEncoder(file)
-	err = encoder.Encode(dir)
-	return err
+	digest := md5.New()
+	if err := encodeFsImage(io.MultiWriter(file, digest), dir, lastTxID); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := saveDigest(path, hex.EncodeToString(digest.Sum(nil))); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	return syncDir()
 }
 
-func loadFsImage(path string) (*fs.Directory, error) {
-	file, err := os.Open(path)
+// saveDigest atomically writes the digest file of the image at path, in the
+// format of md5sum
+func saveDigest(path, digest string) error {
+	digestPath := path + digestSuffix
+	tmpPath := digestPath + checkpointSuffix
+	file, err := os.Create(tmpPath)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer file.Close()
+	defer os.Remove(tmpPath)
 
-	var dir fs.Directory
-	decoder := gob.NewDecoder(file)
-	err = decoder.Decode(&dir)
-	return &dir, err
+	if _, err := fmt.Fprintf(file, "%s *%s\n", digest, filepath.Base(path)); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, digestPath)
+}
+
+// loadFsImage returns the namespace of an fsimage and the ID of the last
+// transaction it includes, after checking the image against its digest file.
+// Images saved before digests have none.
+func loadFsImage(path string) (*fs.Directory, int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := verifyDigest(path, data); err != nil {
+		return nil, 0, err
+	}
+	return decodeFsImage(data)
+}
+
+// verifyDigest checks data, read from the image at path, against the digest
+// file of the image if there is one
+func verifyDigest(path string, data []byte) error {
+	content, err := os.ReadFile(path + digestSuffix)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return fmt.Errorf("digest file of %s is empty", path)
+	}
+	sum := md5.Sum(data)
+	if actual := hex.EncodeToString(sum[:]); actual != fields[0] {
+		return fmt.Errorf("%s has digest %s, expected %s", path, actual, fields[0])
+	}
+	return nil
+}
+
+// removeFsImage deletes an fsimage along with its digest file
+func removeFsImage(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Remove(path + digestSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
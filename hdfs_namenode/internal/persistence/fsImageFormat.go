@@ -0,0 +1,491 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// The fsimage layout is documented in protobuf/fsimage.proto. Each layout
+// version the NameNode reads has its loader in fsImageLoaders; images of an
+// older layout are upgraded on load, and the next checkpoint writes them in
+// the current one.
+const (
+	fsImageMagic = "HDFSIMG1"
+	// fsImageLayoutVersion is the layout of the images written, and the most
+	// recent the loader reads
+	fsImageLayoutVersion = 2
+
+	stringsSection     = "strings"
+	blocksSection      = "blocks"
+	inodesSection      = "inodes"
+	directoriesSection = "directories"
+	snapshotsSection   = "snapshots"
+)
+
+var (
+	// errNewerFsImageLayout is returned for images of a layout more recent
+	// than this NameNode reads, and errUnsupportedFsImageLayout for images of
+	// an older layout it no longer reads. Neither must be set aside as corrupt.
+	errNewerFsImageLayout       = errors.New("fsimage was written by a newer NameNode")
+	errUnsupportedFsImageLayout = errors.New("fsimage layout version is no longer supported")
+)
+
+// fsImageLoader builds the namespace held by the sections of an fsimage of
+// one layout version, read by reader after header
+type fsImageLoader func(header *protobuf.FsImageHeader, reader *delimitedReader) (*fs.Directory, error)
+
+// fsImageLoaders are the loaders of the layout versions the NameNode reads
+var fsImageLoaders = map[int32]fsImageLoader{
+	1: loadFsImageV1,
+	// Layout 2 stores what snapshots share with the live namespace once,
+	// which the loader of layout 1 reads as it is
+	2: loadFsImageV1,
+}
+
+// encodeFsImage writes the namespace under root, as of the transaction
+// lastTxID, to w in the current layout
+func encodeFsImage(w io.Writer, root *fs.Directory, lastTxID int64) error {
+	encoder := &fsImageEncoder{
+		strings: map[string]uint32{"": 0},
+		dirAt:   make(map[*fs.Directory]uint32),
+		fileAt:  make(map[*fs.Inode]uint32),
+		table:   &protobuf.FsImageStringTable{Strings: []string{""}},
+		blockAt: make(map[string]uint32),
+		blocks:  &protobuf.FsImageBlockSection{},
+		inodes:  &protobuf.FsImageInodeSection{},
+		dirs:    &protobuf.FsImageDirectorySection{},
+		snaps:   &protobuf.FsImageSnapshotSection{},
+	}
+	encoder.addTree(root)
+
+	header := &protobuf.FsImageHeader{
+		LayoutVersion: fsImageLayoutVersion,
+		LastTxId:      lastTxID,
+		Sections:      []string{stringsSection, blocksSection, inodesSection, directoriesSection, snapshotsSection},
+	}
+	buffered := bufio.NewWriter(w)
+	if _, err := buffered.WriteString(fsImageMagic); err != nil {
+		return err
+	}
+	for _, message := range []proto.Message{header, encoder.table, encoder.blocks, encoder.inodes, encoder.dirs, encoder.snaps} {
+		if err := writeDelimited(buffered, message); err != nil {
+			return err
+		}
+	}
+	return buffered.Flush()
+}
+
+func writeDelimited(w io.Writer, message proto.Message) error {
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(binary.AppendUvarint(nil, uint64(len(data)))); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// fsImageEncoder builds the sections of an fsimage from a namespace
+type fsImageEncoder struct {
+	strings map[string]uint32
+	table   *protobuf.FsImageStringTable
+	blockAt map[string]uint32
+	blocks  *protobuf.FsImageBlockSection
+	// dirAt and fileAt hold the position of the directories and files added,
+	// which snapshots and the live namespace may share
+	dirAt  map[*fs.Directory]uint32
+	fileAt map[*fs.Inode]uint32
+	inodes *protobuf.FsImageInodeSection
+	dirs   *protobuf.FsImageDirectorySection
+	snaps  *protobuf.FsImageSnapshotSection
+}
+
+// addTree adds dir, everything below it and its snapshots, and returns the
+// position of dir in the inode section. A directory shared by several trees
+// is only added the first time. Children are added in name order so the same
+// namespace always gives the same image.
+func (e *fsImageEncoder) addTree(dir *fs.Directory) uint32 {
+	if index, exists := e.dirAt[dir]; exists {
+		return index
+	}
+	index := e.addInode(dir.Inode, dir)
+	e.dirAt[dir] = index
+
+	var children []uint32
+	for _, name := range sortedKeys(dir.ChildFiles) {
+		children = append(children, e.addFile(dir.ChildFiles[name]))
+	}
+	for _, name := range sortedKeys(dir.ChildDirs) {
+		children = append(children, e.addTree(dir.ChildDirs[name]))
+	}
+	if len(children) > 0 {
+		e.dirs.Directories = append(e.dirs.Directories, &protobuf.FsImageDirectory{Inode: index, Children: children})
+	}
+
+	for _, name := range sortedKeys(dir.Snapshots) {
+		snapshot := dir.Snapshots[name]
+		e.snaps.Snapshots = append(e.snaps.Snapshots, &protobuf.FsImageSnapshot{
+			Directory: index,
+			Name:      snapshot.Name,
+			Timestamp: unixNano(snapshot.Timestamp),
+			Root:      e.addTree(snapshot.Root),
+		})
+	}
+	return index
+}
+
+// addFile returns the position of a file in the inode section, adding it the
+// first time a directory lists it
+func (e *fsImageEncoder) addFile(inode *fs.Inode) uint32 {
+	if index, exists := e.fileAt[inode]; exists {
+		return index
+	}
+	index := e.addInode(inode, nil)
+	e.fileAt[inode] = index
+	return index
+}
+
+// addInode adds an inode, with the quota and snapshot settings of dir if it
+// is a directory, and returns its position in the inode section
+func (e *fsImageEncoder) addInode(inode *fs.Inode, dir *fs.Directory) uint32 {
+	encoded := &protobuf.FsImageInode{
+		Id:          inode.ID,
+		Name:        e.intern(inode.Name),
+		Owner:       e.intern(inode.Owner),
+		Group:       e.intern(inode.Group),
+		IsDir:       inode.IsDir,
+		Size:        inode.Size,
+		Timestamp:   unixNano(inode.Timestamp),
+		Replication: int32(inode.Replication),
+		Permission:  uint32(inode.Permission),
+		Acl:         encodeAcl(inode.Acl),
+	}
+	for _, block := range inode.Blocks {
+		encoded.Blocks = append(encoded.Blocks, e.addBlock(block))
+	}
+	if dir != nil {
+		encoded.Snapshottable = dir.Snapshottable
+		if dir.Quota != nil {
+			encoded.Quota = &protobuf.FsImageQuota{NamespaceQuota: dir.Quota.NamespaceQuota, SpaceQuota: dir.Quota.SpaceQuota}
+		}
+	}
+
+	e.inodes.Inodes = append(e.inodes.Inodes, encoded)
+	return uint32(len(e.inodes.Inodes) - 1)
+}
+
+// addBlock returns the position of a block in the block section, adding it
+// the first time a file refers to it
+func (e *fsImageEncoder) addBlock(block fs.BlockAssignment) uint32 {
+	if index, exists := e.blockAt[block.BlockID]; exists {
+		return index
+	}
+	encoded := &protobuf.FsImageBlock{BlockId: block.BlockID}
+	for _, address := range block.DataNodeAddresses {
+		encoded.Locations = append(encoded.Locations, e.intern(address))
+	}
+	e.blocks.Blocks = append(e.blocks.Blocks, encoded)
+	index := uint32(len(e.blocks.Blocks) - 1)
+	e.blockAt[block.BlockID] = index
+	return index
+}
+
+// intern returns the position of value in the string table
+func (e *fsImageEncoder) intern(value string) uint32 {
+	if index, exists := e.strings[value]; exists {
+		return index
+	}
+	e.table.Strings = append(e.table.Strings, value)
+	index := uint32(len(e.table.Strings) - 1)
+	e.strings[value] = index
+	return index
+}
+
+// decodeFsImage returns the namespace held by an fsimage and the ID of the
+// last transaction it includes, with the loader of the image's layout version
+func decodeFsImage(data []byte) (*fs.Directory, int64, error) {
+	if !bytes.HasPrefix(data, []byte(fsImageMagic)) {
+		return nil, 0, errors.New("fsimage has an invalid header")
+	}
+	reader := &delimitedReader{data: data[len(fsImageMagic):]}
+
+	header := &protobuf.FsImageHeader{}
+	if err := reader.next(header); err != nil {
+		return nil, 0, fmt.Errorf("reading fsimage header: %w", err)
+	}
+	version := header.GetLayoutVersion()
+	if version > fsImageLayoutVersion {
+		return nil, 0, fmt.Errorf("%w: it has layout version %d, and this NameNode reads layouts up to %d", errNewerFsImageLayout, version, fsImageLayoutVersion)
+	}
+	loader, supported := fsImageLoaders[version]
+	if !supported {
+		return nil, 0, fmt.Errorf("%w: layout version %d", errUnsupportedFsImageLayout, version)
+	}
+	root, err := loader(header, reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	return root, header.GetLastTxId(), nil
+}
+
+// loadFsImageV1 loads the sections of layout version 1
+func loadFsImageV1(header *protobuf.FsImageHeader, reader *delimitedReader) (*fs.Directory, error) {
+	table := &protobuf.FsImageStringTable{}
+	blocks := &protobuf.FsImageBlockSection{}
+	inodes := &protobuf.FsImageInodeSection{}
+	dirs := &protobuf.FsImageDirectorySection{}
+	snaps := &protobuf.FsImageSnapshotSection{}
+	sections := map[string]proto.Message{
+		stringsSection:     table,
+		blocksSection:      blocks,
+		inodesSection:      inodes,
+		directoriesSection: dirs,
+		snapshotsSection:   snaps,
+	}
+	for _, name := range header.GetSections() {
+		// Sections added by newer NameNodes are skipped without decoding
+		section, known := sections[name]
+		if !known {
+			if err := reader.skip(); err != nil {
+				return nil, fmt.Errorf("skipping fsimage section %s: %w", name, err)
+			}
+			continue
+		}
+		if err := reader.next(section); err != nil {
+			return nil, fmt.Errorf("reading fsimage section %s: %w", name, err)
+		}
+	}
+
+	decoder := &fsImageDecoder{table: table.GetStrings(), blocks: blocks.GetBlocks()}
+	return decoder.build(inodes.GetInodes(), dirs.GetDirectories(), snaps.GetSnapshots())
+}
+
+// delimitedReader reads the length-delimited messages of an fsimage
+type delimitedReader struct {
+	data []byte
+}
+
+// next decodes the next message into message
+func (r *delimitedReader) next(message proto.Message) error {
+	payload, err := r.payload()
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(payload, message)
+}
+
+// skip moves past the next message without decoding it
+func (r *delimitedReader) skip() error {
+	_, err := r.payload()
+	return err
+}
+
+// payload returns the encoding of the next message and moves past it
+func (r *delimitedReader) payload() ([]byte, error) {
+	length, n := binary.Uvarint(r.data)
+	if n <= 0 || uint64(len(r.data)-n) < length {
+		return nil, errors.New("fsimage is truncated")
+	}
+	payload := r.data[n : n+int(length)]
+	r.data = r.data[n+int(length):]
+	return payload, nil
+}
+
+// fsImageDecoder rebuilds a namespace from the sections of an fsimage
+type fsImageDecoder struct {
+	table  []string
+	blocks []*protobuf.FsImageBlock
+}
+
+// build links the decoded inodes into the namespace, whose root is the first
+func (d *fsImageDecoder) build(inodes []*protobuf.FsImageInode, dirs []*protobuf.FsImageDirectory, snaps []*protobuf.FsImageSnapshot) (*fs.Directory, error) {
+	if len(inodes) == 0 || !inodes[0].GetIsDir() {
+		return nil, errors.New("fsimage has no root directory")
+	}
+
+	decoded := make([]*fs.Inode, len(inodes))
+	directories := make([]*fs.Directory, len(inodes))
+	for i, encoded := range inodes {
+		inode, err := d.inode(encoded)
+		if err != nil {
+			return nil, err
+		}
+		decoded[i] = inode
+		if inode.IsDir {
+			directories[i] = &fs.Directory{
+				Inode:         inode,
+				ChildFiles:    make(map[string]*fs.Inode),
+				ChildDirs:     make(map[string]*fs.Directory),
+				Snapshottable: encoded.GetSnapshottable(),
+			}
+			if quota := encoded.GetQuota(); quota != nil {
+				// The usage is recalculated once the namespace is loaded
+				directories[i].Quota = &fs.Quota{NamespaceQuota: quota.GetNamespaceQuota(), SpaceQuota: quota.GetSpaceQuota()}
+			}
+		}
+	}
+
+	directory := func(index uint32) (*fs.Directory, error) {
+		if int(index) >= len(directories) || directories[index] == nil {
+			return nil, fmt.Errorf("fsimage refers to missing directory %d", index)
+		}
+		return directories[index], nil
+	}
+	parents := make([]int, len(inodes))
+	for _, encoded := range dirs {
+		parent, err := directory(encoded.GetInode())
+		if err != nil {
+			return nil, err
+		}
+		for _, child := range encoded.GetChildren() {
+			if int(child) >= len(decoded) || child == 0 {
+				return nil, fmt.Errorf("fsimage refers to missing inode %d", child)
+			}
+			if inode := decoded[child]; inode.IsDir {
+				parent.ChildDirs[inode.Name] = directories[child]
+			} else {
+				parent.ChildFiles[inode.Name] = inode
+			}
+			parents[child]++
+		}
+	}
+	// Inodes listed by several directories are shared by snapshots and the
+	// live namespace, and are copied before they change
+	for i, count := range parents {
+		if count < 2 {
+			continue
+		}
+		if directories[i] != nil {
+			fs.ShareDirectory(directories[i])
+		} else {
+			fs.ShareFile(decoded[i])
+		}
+	}
+	for _, encoded := range snaps {
+		dir, err := directory(encoded.GetDirectory())
+		if err != nil {
+			return nil, err
+		}
+		root, err := directory(encoded.GetRoot())
+		if err != nil {
+			return nil, err
+		}
+		if dir.Snapshots == nil {
+			dir.Snapshots = make(map[string]*fs.Snapshot)
+		}
+		dir.Snapshots[encoded.GetName()] = &fs.Snapshot{Name: encoded.GetName(), Timestamp: fromUnixNano(encoded.GetTimestamp()), Root: root}
+	}
+	return directories[0], nil
+}
+
+func (d *fsImageDecoder) inode(encoded *protobuf.FsImageInode) (*fs.Inode, error) {
+	name, err := d.string(encoded.GetName())
+	if err != nil {
+		return nil, err
+	}
+	owner, err := d.string(encoded.GetOwner())
+	if err != nil {
+		return nil, err
+	}
+	group, err := d.string(encoded.GetGroup())
+	if err != nil {
+		return nil, err
+	}
+	inode := &fs.Inode{
+		ID:          encoded.GetId(),
+		Name:        name,
+		IsDir:       encoded.GetIsDir(),
+		Size:        encoded.GetSize(),
+		Timestamp:   fromUnixNano(encoded.GetTimestamp()),
+		Replication: int(encoded.GetReplication()),
+		Owner:       owner,
+		Group:       group,
+		Permission:  fs.Permission(encoded.GetPermission()),
+		Acl:         decodeAcl(encoded.GetAcl()),
+	}
+	for _, index := range encoded.GetBlocks() {
+		if int(index) >= len(d.blocks) {
+			return nil, fmt.Errorf("fsimage refers to missing block %d", index)
+		}
+		block := fs.BlockAssignment{BlockID: d.blocks[index].GetBlockId()}
+		for _, location := range d.blocks[index].GetLocations() {
+			address, err := d.string(location)
+			if err != nil {
+				return nil, err
+			}
+			block.DataNodeAddresses = append(block.DataNodeAddresses, address)
+		}
+		inode.Blocks = append(inode.Blocks, block)
+	}
+	return inode, nil
+}
+
+func (d *fsImageDecoder) string(index uint32) (string, error) {
+	if int(index) >= len(d.table) {
+		return "", fmt.Errorf("fsimage refers to missing string %d", index)
+	}
+	return d.table[index], nil
+}
+
+func encodeAcl(acl []fs.AclEntry) []*protobuf.FsImageAclEntry {
+	var encoded []*protobuf.FsImageAclEntry
+	for _, entry := range acl {
+		encoded = append(encoded, &protobuf.FsImageAclEntry{
+			Scope:      string(entry.Scope),
+			Type:       string(entry.Type),
+			Name:       entry.Name,
+			Permission: uint32(entry.Permission),
+		})
+	}
+	return encoded
+}
+
+func decodeAcl(encoded []*protobuf.FsImageAclEntry) []fs.AclEntry {
+	var acl []fs.AclEntry
+	for _, entry := range encoded {
+		acl = append(acl, fs.AclEntry{
+			Scope:      fs.AclEntryScope(entry.GetScope()),
+			Type:       fs.AclEntryType(entry.GetType()),
+			Name:       entry.GetName(),
+			Permission: fs.FsAction(entry.GetPermission()),
+		})
+	}
+	return acl
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unixNano returns t in nanoseconds since the epoch, or 0 for the zero time
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
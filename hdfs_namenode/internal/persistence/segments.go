@@ -0,0 +1,297 @@
+package persistence
+
+import (
+	"encoding/binary"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The edit log is split into segments named after the transactions they hold,
+// as in HDFS: edits_<first>-<last> for finalized segments, and
+// edits_inprogress_<first> for the one being written. A segment is finalized
+// when it grows past segmentSizeThreshold, gets older than
+// segmentRollInterval, or at a checkpoint; segments only needed to replay
+// fsimages older than the retainedCheckpoints most recent ones are purged.
+
+var (
+	segmentSizeThreshold int64 = 1 << 20
+	segmentRollInterval        = 10 * time.Minute
+	retainedCheckpoints        = 2
+
+	// editLogFile is the in-progress segment, holding the transactions from
+	// segmentFirstTxID on
+	editLogFile      *os.File
+	segmentFirstTxID int64
+	segmentSize      int64
+	segmentOpened    time.Time
+)
+
+// SetEditLogPolicy configures when edit log segments are rolled, and how many
+// fsimage checkpoints are kept along with the segments needed to replay them
+func SetEditLogPolicy(segmentSize int64, rollInterval time.Duration, checkpoints int) {
+	editLogMutex.Lock()
+	defer editLogMutex.Unlock()
+	segmentSizeThreshold = segmentSize
+	segmentRollInterval = rollInterval
+	retainedCheckpoints = checkpoints
+}
+
+// editLogSegment is an edit log segment file; lastTxID is unknown for the
+// in-progress segment
+type editLogSegment struct {
+	name       string
+	firstTxID  int64
+	lastTxID   int64
+	inProgress bool
+}
+
+func inProgressSegmentName(firstTxID int64) string {
+	return fmt.Sprintf("edits_inprogress_%019d", firstTxID)
+}
+
+func finalizedSegmentName(firstTxID, lastTxID int64) string {
+	return fmt.Sprintf("edits_%019d-%019d", firstTxID, lastTxID)
+}
+
+// listSegments returns the edit log segments, in transaction order
+func listSegments() ([]editLogSegment, error) {
+	names, err := filepath.Glob("edits_*")
+	if err != nil {
+		return nil, err
+	}
+	var segments []editLogSegment
+	for _, name := range names {
+		if segment, ok := parseSegmentName(name); ok {
+			segments = append(segments, segment)
+		}
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].firstTxID < segments[j].firstTxID })
+	return segments, nil
+}
+
+func parseSegmentName(name string) (editLogSegment, bool) {
+	if first, ok := strings.CutPrefix(name, "edits_inprogress_"); ok {
+		firstTxID, err := strconv.ParseInt(first, 10, 64)
+		return editLogSegment{name: name, firstTxID: firstTxID, inProgress: true}, err == nil
+	}
+	first, last, ok := strings.Cut(strings.TrimPrefix(name, "edits_"), "-")
+	if !ok {
+		return editLogSegment{}, false
+	}
+	firstTxID, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return editLogSegment{}, false
+	}
+	lastTxID, err := strconv.ParseInt(last, 10, 64)
+	return editLogSegment{name: name, firstTxID: firstTxID, lastTxID: lastTxID}, err == nil
+}
+
+// loadEditLog returns the entries of the edit log after the transaction
+// imageTxID, which the fsimage already includes, and opens a new in-progress
+// segment for the next ones. A record cut short or corrupted by a crash ends
+// the in-progress segment it was written to: it is truncated there, as the
+// change it held was never acknowledged, and the segment finalized. A corrupt
+// record with valid ones after it fails the load instead. Entries of legacy
+// edit logs are moved into the new segment.
+func loadEditLog(imageTxID int64) ([]EditLogEntry, error) {
+	editLogMutex.Lock()
+	defer editLogMutex.Unlock()
+
+	if editLogFile != nil {
+		editLogFile.Close()
+		editLogFile = nil
+	}
+	editLogFailure = nil
+	segments, err := listSegments()
+	if err != nil {
+		return nil, err
+	}
+
+	lastTxID = imageTxID
+	var entries []EditLogEntry
+	for _, segment := range segments {
+		if !segment.inProgress && segment.lastTxID <= imageTxID {
+			continue
+		}
+		logged, err := readSegment(segment)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range logged {
+			if entry.TxID <= lastTxID {
+				continue
+			}
+			if entry.TxID != lastTxID+1 {
+				return nil, fmt.Errorf("edit log is missing transactions %d to %d", lastTxID+1, entry.TxID-1)
+			}
+			entries = append(entries, entry)
+			lastTxID = entry.TxID
+		}
+		if segment.inProgress {
+			if err := finalizeSegment(segment.name, segment.firstTxID, logged); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := openSegment(lastTxID + 1); err != nil {
+		return nil, err
+	}
+
+	legacy, err := loadLegacyEditLog()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range legacy {
+		lastTxID++
+		entry.TxID = lastTxID
+		written, err := appendEditLogRecord(editLogFile, entry)
+		if err != nil {
+			return nil, err
+		}
+		segmentSize += int64(written)
+		entries = append(entries, entry)
+	}
+	if err := os.Remove(legacyEditLogFileName); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	editsSinceCheckpoint = len(entries)
+	return entries, nil
+}
+
+// readSegment decodes the entries of a segment. The torn tail of an
+// in-progress segment is truncated; a finalized segment must be intact, and
+// corruption before the tail of either fails the read.
+func readSegment(segment editLogSegment) ([]EditLogEntry, error) {
+	data, err := os.ReadFile(segment.name)
+	if err != nil {
+		return nil, err
+	}
+	entries, valid, err := decodeEditLog(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", segment.name, err)
+	}
+	if valid < len(data) {
+		if !segment.inProgress {
+			return nil, fmt.Errorf("edit log segment %s is corrupt at offset %d", segment.name, valid)
+		}
+		log.Printf("Truncating %d bytes of a torn record at offset %d of %s", len(data)-valid, valid, segment.name)
+		if err := os.Truncate(segment.name, int64(valid)); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// finalizeSegment renames the in-progress segment name after the transactions
+// it holds, or removes it if it holds none
+func finalizeSegment(name string, firstTxID int64, entries []EditLogEntry) error {
+	if len(entries) == 0 {
+		return os.Remove(name)
+	}
+	if err := os.Rename(name, finalizedSegmentName(firstTxID, entries[len(entries)-1].TxID)); err != nil {
+		return err
+	}
+	return syncDir()
+}
+
+// openSegment starts the in-progress segment holding the transactions from
+// firstTxID on. Must be called with editLogMutex held.
+func openSegment(firstTxID int64) error {
+	file, err := os.OpenFile(inProgressSegmentName(firstTxID), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, editLogHeaderSize)
+	copy(header, editLogMagic)
+	binary.BigEndian.PutUint32(header[4:], editLogVersion)
+	if _, err := file.Write(header); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	editLogFile = file
+	segmentFirstTxID = firstTxID
+	segmentSize = editLogHeaderSize
+	segmentOpened = time.Now()
+	return syncDir()
+}
+
+// rollEditLog finalizes the in-progress segment and starts the next one,
+// unless it holds no transactions yet. Must be called with editLogMutex held.
+func rollEditLog() error {
+	if editLogFile == nil {
+		return openSegment(lastTxID + 1)
+	}
+	if lastTxID < segmentFirstTxID {
+		return nil
+	}
+	if err := editLogFile.Close(); err != nil {
+		return err
+	}
+	editLogFile = nil
+	if err := os.Rename(inProgressSegmentName(segmentFirstTxID), finalizedSegmentName(segmentFirstTxID, lastTxID)); err != nil {
+		return err
+	}
+	return openSegment(lastTxID + 1)
+}
+
+// purgeCheckpoints deletes all but the retainedCheckpoints most recent
+// fsimages, and the finalized segments only needed to replay older ones. Must
+// be called with editLogMutex held.
+func purgeCheckpoints() error {
+	images, err := listFsImages()
+	if err != nil || len(images) == 0 {
+		return err
+	}
+	oldest := max(len(images)-retainedCheckpoints, 0)
+	for _, txID := range images[:oldest] {
+		if err := removeFsImage(fsImageFileName(txID)); err != nil {
+			return err
+		}
+	}
+	// Left behind by checkpoints interrupted by a crash
+	leftovers, err := filepath.Glob("fsimage_*" + checkpointSuffix)
+	if err != nil {
+		return err
+	}
+	for _, name := range leftovers {
+		if err := os.Remove(name); err != nil {
+			return err
+		}
+	}
+
+	segments, err := listSegments()
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if !segment.inProgress && segment.lastTxID <= images[oldest] {
+			if err := os.Remove(segment.name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// syncDir fsyncs the directory holding the fsimages and edit log, so files
+// created or renamed in it survive a crash
+func syncDir() error {
+	dir, err := os.Open(".")
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+
+	utils "github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+)
+
+// GetAclStatus returns the ACL of a file or directory
+func (fs *FileSystemService) GetAclStatus(user, path string) (*utils.AclStatus, error) {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	if err := fs.permissionChecker(user).checkTraverse(filepath.Dir(filepath.Clean(path))); err != nil {
+		return nil, err
+	}
+	inode := utils.Lookup(fs.rootDirectory, path)
+	if inode == nil {
+		return nil, fmt.Errorf("file or directory %w", utils.ErrNotFound)
+	}
+
+	return &utils.AclStatus{
+		Owner:      inode.Owner,
+		Group:      inode.Group,
+		Permission: inode.Permission,
+		Entries:    utils.FullAcl(inode.Permission, inode.Acl),
+	}, nil
+}
+
+// SetAcl replaces the ACL of a file or directory. The owner, group and other
+// entries are kept unless given.
+func (fs *FileSystemService) SetAcl(user, path string, entries []utils.AclEntry) error {
+	return fs.updateAcl(user, path, func(current []utils.AclEntry) []utils.AclEntry {
+		given := make(map[utils.AclEntryType]bool)
+		for _, entry := range entries {
+			if entry.Scope == utils.AclScopeAccess && entry.Name == "" {
+				given[entry.Type] = true
+			}
+		}
+		updated := append([]utils.AclEntry(nil), entries...)
+		for _, entry := range current {
+			if isBaseEntry(entry) && !given[entry.Type] {
+				updated = append(updated, entry)
+			}
+		}
+		return updated
+	})
+}
+
+// ModifyAclEntries adds entries to the ACL of a file or directory, replacing
+// those for the same users and groups
+func (fs *FileSystemService) ModifyAclEntries(user, path string, entries []utils.AclEntry) error {
+	return fs.updateAcl(user, path, func(current []utils.AclEntry) []utils.AclEntry {
+		replaced := make(map[utils.AclEntry]bool)
+		for _, entry := range entries {
+			replaced[utils.AclEntry{Scope: entry.Scope, Type: entry.Type, Name: entry.Name}] = true
+		}
+		var updated []utils.AclEntry
+		for _, entry := range withoutMasks(current, entries) {
+			if !replaced[utils.AclEntry{Scope: entry.Scope, Type: entry.Type, Name: entry.Name}] {
+				updated = append(updated, entry)
+			}
+		}
+		return append(updated, entries...)
+	})
+}
+
+// RemoveAclEntries removes the entries for the given users and groups from the
+// ACL of a file or directory; their permissions are ignored
+func (fs *FileSystemService) RemoveAclEntries(user, path string, entries []utils.AclEntry) error {
+	removed := make(map[utils.AclEntry]bool)
+	for _, entry := range entries {
+		if isBaseEntry(entry) {
+			return fmt.Errorf("invalid ACL: the %s:: entry cannot be removed", entry.Type)
+		}
+		removed[utils.AclEntry{Scope: entry.Scope, Type: entry.Type, Name: entry.Name}] = true
+	}
+	return fs.updateAcl(user, path, func(current []utils.AclEntry) []utils.AclEntry {
+		var updated []utils.AclEntry
+		for _, entry := range withoutMasks(current, nil) {
+			if !removed[utils.AclEntry{Scope: entry.Scope, Type: entry.Type, Name: entry.Name}] {
+				updated = append(updated, entry)
+			}
+		}
+		return updated
+	})
+}
+
+// RemoveDefaultAcl removes the default ACL of a directory
+func (fs *FileSystemService) RemoveDefaultAcl(user, path string) error {
+	return fs.updateAcl(user, path, utils.AccessEntries)
+}
+
+// RemoveAcl removes every extended entry from the ACL of a file or directory,
+// leaving only the permission bits
+func (fs *FileSystemService) RemoveAcl(user, path string) error {
+	return fs.updateAcl(user, path, func(current []utils.AclEntry) []utils.AclEntry {
+		var updated []utils.AclEntry
+		for _, entry := range current {
+			if isBaseEntry(entry) {
+				updated = append(updated, entry)
+			}
+		}
+		return updated
+	})
+}
+
+// updateAcl replaces the full ACL of the inode at path with the result of
+// change, which only the owner of the inode may do
+func (fs *FileSystemService) updateAcl(user, path string, change func([]utils.AclEntry) []utils.AclEntry) error {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	if err := checkWritable(path); err != nil {
+		return err
+	}
+	checker := fs.permissionChecker(user)
+	if err := checker.checkTraverse(filepath.Dir(filepath.Clean(path))); err != nil {
+		return err
+	}
+	inode := utils.MutableInode(fs.rootDirectory, path)
+	if inode == nil {
+		return fmt.Errorf("file or directory %w", utils.ErrNotFound)
+	}
+	if err := checker.checkOwner(path, inode); err != nil {
+		return err
+	}
+
+	permission, acl, err := utils.ApplyAcl(inode.Permission, change(utils.FullAcl(inode.Permission, inode.Acl)))
+	if err != nil {
+		return err
+	}
+	if !inode.IsDir && len(utils.DefaultEntries(acl)) > 0 {
+		return fmt.Errorf("invalid ACL: only directories may have a default ACL")
+	}
+
+	if err := persistence.RecordSetAcl(filepath.Clean(path), permission, acl); err != nil {
+		return err
+	}
+	inode.Permission = permission
+	inode.Acl = acl
+
+	return nil
+}
+
+// isBaseEntry reports whether entry is one of the owner, group and other
+// access entries every ACL has
+func isBaseEntry(entry utils.AclEntry) bool {
+	return entry.Scope == utils.AclScopeAccess && entry.Name == "" && entry.Type != utils.AclMask
+}
+
+// withoutMasks drops the masks of current so they are calculated again,
+// except in the scopes where entries give a new mask
+func withoutMasks(current, entries []utils.AclEntry) []utils.AclEntry {
+	kept := make(map[utils.AclEntryScope]bool)
+	for _, entry := range entries {
+		if entry.Type == utils.AclMask {
+			kept[entry.Scope] = true
+		}
+	}
+	var updated []utils.AclEntry
+	for _, entry := range current {
+		if entry.Type != utils.AclMask || kept[entry.Scope] {
+			updated = append(updated, entry)
+		}
+	}
+	return updated
+}
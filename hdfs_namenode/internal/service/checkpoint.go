@@ -0,0 +1,22 @@
+package service
+
+import (
+	"fmt"
+
+	utils "github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+)
+
+// SaveNamespace checkpoints the namespace to a new fsimage right away and
+// returns the ID of the last transaction it includes. Only the superuser may
+// save the namespace.
+func (fs *FileSystemService) SaveNamespace(user string) (int64, error) {
+	fs.rootMutex.RLock()
+	superuser := fs.permissionChecker(user).superuser
+	// The checkpoint takes the lock itself to copy the namespace
+	fs.rootMutex.RUnlock()
+	if !superuser {
+		return 0, fmt.Errorf("%w: only the superuser may save the namespace", utils.ErrPermissionDenied)
+	}
+	return persistence.SaveNamespace()
+}
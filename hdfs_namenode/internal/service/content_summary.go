@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	utils "github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+)
+
+// GetContentSummary counts the files, directories and bytes below a path,
+// which the user must be able to list. Large trees are walked a few thousand
+// inodes at a time, letting other operations at the namespace in between, so
+// the summary of a tree changed meanwhile mixes its state before and after
+// the change, as in HDFS.
+func (fs *FileSystemService) GetContentSummary(user, path string) (*utils.ContentSummary, error) {
+	fs.rootMutex.RLock()
+	defer fs.rootMutex.RUnlock()
+
+	path = filepath.Clean(path)
+	checker := fs.permissionChecker(user)
+	if err := checker.checkTraverse(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	inode := utils.Lookup(fs.rootDirectory, path)
+	if inode == nil {
+		return nil, fmt.Errorf("file or directory %w", utils.ErrNotFound)
+	}
+	if !inode.IsDir {
+		return &utils.ContentSummary{
+			Length:            inode.Size,
+			FileCount:         1,
+			SpaceConsumed:     inode.SpaceConsumed(),
+			NamespaceConsumed: 1,
+		}, nil
+	}
+
+	dir := utils.FindDirectory(fs.rootDirectory, path)
+	walk := &contentSummaryWalk{fs: fs, checker: checker, limit: fs.config.ContentSummaryLimit}
+	if err := walk.walk(path, dir); err != nil {
+		return nil, err
+	}
+	summary := &walk.summary
+	summary.NamespaceConsumed = summary.FileCount + summary.DirectoryCount
+	if dir.Quota != nil {
+		summary.NamespaceQuota = dir.Quota.NamespaceQuota
+		summary.SpaceQuota = dir.Quota.SpaceQuota
+	}
+	return summary, nil
+}
+
+// contentSummaryWalk counts a subtree for GetContentSummary, giving up the
+// read lock on the namespace after every limit inodes so writers waiting for
+// it are not held up for the whole walk
+type contentSummaryWalk struct {
+	fs      *FileSystemService
+	checker *permissionChecker
+	limit   int
+	// Inodes counted since the lock was last taken
+	counted int
+	summary utils.ContentSummary
+}
+
+// walk adds dir, which the user must be able to list, and everything below it
+// to the summary. Must be called with fs.rootMutex read-locked.
+func (w *contentSummaryWalk) walk(path string, dir *utils.Directory) error {
+	if err := w.checker.check(path, dir.Inode, utils.ActionRead|utils.ActionExecute); err != nil {
+		return err
+	}
+	w.summary.DirectoryCount++
+	w.count()
+
+	// The children are copied first, as dir may change while the lock is given up
+	files := make([]*utils.Inode, 0, len(dir.ChildFiles))
+	for _, inode := range dir.ChildFiles {
+		files = append(files, inode)
+	}
+	children := make([]*utils.Directory, 0, len(dir.ChildDirs))
+	for _, child := range dir.ChildDirs {
+		children = append(children, child)
+	}
+
+	for _, inode := range files {
+		w.summary.FileCount++
+		w.summary.Length += inode.Size
+		w.summary.SpaceConsumed += inode.SpaceConsumed()
+		w.count()
+	}
+	for _, child := range children {
+		if err := w.walk(filepath.Join(path, child.Inode.Name), child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// count records an inode counted, giving up the lock once limit are
+func (w *contentSummaryWalk) count() {
+	w.counted++
+	if w.limit == 0 || w.counted < w.limit {
+		return
+	}
+	w.counted = 0
+	w.fs.rootMutex.RUnlock()
+	runtime.Gosched()
+	w.fs.rootMutex.RLock()
+}
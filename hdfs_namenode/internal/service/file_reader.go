@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"slices"
+
+	utils "github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+)
+
+// ReadFileData streams the content of a file opened with OpenFile to w.
+// Blocks are streamed in order; if a DataNode holding a replica fails, the
+// read resumes from the next replica at the offset already delivered.
+func (fs *FileSystemService) ReadFileData(inode *utils.Inode, w io.Writer) error {
+	out := &trackingWriter{w: w}
+	for _, block := range inode.Blocks {
+		if err := readBlock(block, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OpenFile returns the inode of a file the user may read
+func (fs *FileSystemService) OpenFile(user, filePath string) (*utils.Inode, error) {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	checker := fs.permissionChecker(user)
+	inode, err := fs.lookupFile(checker, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checker.check(filePath, inode, utils.ActionRead); err != nil {
+		return nil, err
+	}
+	return inode, nil
+}
+
+// trackingWriter remembers write failures so they are not mistaken for
+// DataNode failures
+type trackingWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (t *trackingWriter) Write(p []byte) (int, error) {
+	n, err := t.w.Write(p)
+	if err != nil {
+		t.err = err
+	}
+	return n, err
+}
+
+// readBlock streams a block from the first DataNode that can serve it.
+func readBlock(block utils.BlockAssignment, out *trackingWriter) error {
+	var offset int64
+	for _, address := range blockLocations(block) {
+		client, err := gRPC.NewNameNodeClient(address)
+		if err != nil {
+			log.Printf("Failed to connect to DataNode %s for block %s: %v", address, block.BlockID, err)
+			continue
+		}
+
+		n, err := client.ReadBlock(block.BlockID, offset, out)
+		client.Close()
+		offset += n
+		if err == nil {
+			return nil
+		}
+		if out.err != nil {
+			return fmt.Errorf("failed to write block %s: %w", block.BlockID, out.err)
+		}
+		log.Printf("Failed to read block %s from DataNode %s: %v", block.BlockID, address, err)
+	}
+
+	return fmt.Errorf("no reachable replica for block %s", block.BlockID)
+}
+
+// blockLocations lists the DataNodes to read a block from: the replicas
+// reported in block reports, then those recorded when the file was written
+// that have not been reported yet
+func blockLocations(block utils.BlockAssignment) []string {
+	locations := gRPC.GetBlockMap().Locations(block.BlockID)
+	for _, address := range block.DataNodeAddresses {
+		if !slices.Contains(locations, address) {
+			locations = append(locations, address)
+		}
+	}
+	return locations
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	utils "github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+)
+
+// WriteFile creates a file and streams its content to the DataNodes.
+// Each block is streamed straight from data through its replication pipeline,
+// so a block is never held in memory; the file is only completed once every
+// block has been acknowledged. On failure the file is abandoned and never
+// becomes visible.
+func (fs *FileSystemService) WriteFile(user, filePath string, fileSize int64, replication int, data io.Reader) (*utils.Inode, error) {
+	// The NameNode itself writes the blocks, so no rack is preferred
+	inode, err := fs.CreateFile(user, filePath, fileSize, replication, "")
+	if err != nil {
+		return nil, err
+	}
+
+	ackedBlocks := make([]utils.BlockAssignment, 0, len(inode.Blocks))
+	remaining := fileSize
+	for _, block := range inode.Blocks {
+		chunkSize := blockSize
+		if remaining < chunkSize {
+			chunkSize = remaining
+		}
+
+		ackedNodes, err := writeBlock(block, io.LimitReader(data, chunkSize), chunkSize)
+		if err != nil {
+			fs.AbandonFile(user, filePath)
+			return nil, err
+		}
+
+		ackedBlocks = append(ackedBlocks, utils.BlockAssignment{BlockID: block.BlockID, DataNodeAddresses: ackedNodes})
+		remaining -= chunkSize
+	}
+
+	return fs.CompleteFile(user, filePath, ackedBlocks)
+}
+
+// writeBlock streams a block of the given size through the pipeline formed by
+// its assigned DataNodes and returns the DataNodes that stored it.
+// While streaming, the block is spooled to a temporary file. If the head of
+// the pipeline fails, the pipeline is rebuilt from the surviving DataNodes and
+// the block is replayed from the spool; failures further down the pipeline
+// are reported in the acks and simply leave the block with fewer replicas.
+func writeBlock(block utils.BlockAssignment, data io.Reader, size int64) ([]string, error) {
+	if len(block.DataNodeAddresses) == 0 {
+		return nil, fmt.Errorf("block %s has no DataNode assigned", block.BlockID)
+	}
+
+	spool, err := os.CreateTemp("", "block-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool for block %s: %w", block.BlockID, err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	source := &trackingReader{r: io.TeeReader(data, spool)}
+	pipeline := block.DataNodeAddresses
+	for {
+		ackedNodes, err := writePipeline(block.BlockID, pipeline, source, size)
+		if err == nil {
+			return ackedNodes, nil
+		}
+		if source.err != nil {
+			return nil, fmt.Errorf("failed to read data for block %s: %w", block.BlockID, source.err)
+		}
+
+		// Pull the rest of the block into the spool, then replay it from there
+		if _, err := io.Copy(io.Discard, source); err != nil {
+			return nil, fmt.Errorf("failed to read data for block %s: %w", block.BlockID, err)
+		}
+		if spooled, err := spool.Seek(0, io.SeekCurrent); err != nil || spooled != size {
+			return nil, fmt.Errorf("data for block %s ended after %d of %d bytes", block.BlockID, spooled, size)
+		}
+
+		pipeline = pipeline[1:]
+		if len(pipeline) == 0 {
+			return nil, fmt.Errorf("every DataNode in the pipeline for block %s failed: %w", block.BlockID, err)
+		}
+		log.Printf("Rebuilding pipeline for block %s from %v: %v", block.BlockID, pipeline, err)
+
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind spool for block %s: %w", block.BlockID, err)
+		}
+		source = &trackingReader{r: spool}
+	}
+}
+
+// writePipeline streams a block to pipeline[0], which forwards it to the rest
+// of the pipeline, and returns the DataNodes that acknowledged it
+func writePipeline(blockID string, pipeline []string, data io.Reader, size int64) ([]string, error) {
+	address := pipeline[0]
+	client, err := gRPC.NewNameNodeClient(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to DataNode %s: %w", address, err)
+	}
+	defer client.Close()
+
+	response, err := client.WriteBlock(blockID, pipeline[1:], data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write block %s to DataNode %s: %w", blockID, address, err)
+	}
+	if response.GetBytesWritten() != size {
+		return nil, fmt.Errorf("block %s: expected %d bytes but DataNode %s stored %d", blockID, size, address, response.GetBytesWritten())
+	}
+	if len(response.GetFailedNodes()) > 0 {
+		log.Printf("Block %s lost replicas on %v during the write", blockID, response.GetFailedNodes())
+	}
+	log.Printf("Stored block %s on DataNodes %v", blockID, response.GetAckedNodes())
+
+	return response.GetAckedNodes(), nil
+}
+
+// trackingReader remembers read failures so they are not mistaken for
+// DataNode failures
+type trackingReader struct {
+	r   io.Reader
+	err error
+}
+
+func (t *trackingReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if err != nil && err != io.EOF {
+		t.err = err
+	}
+	return n, err
+}
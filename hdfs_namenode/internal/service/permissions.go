@@ -0,0 +1,231 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	utils "github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+)
+
+// GroupMapping resolves the groups a user belongs to
+type GroupMapping interface {
+	Groups(user string) []string
+}
+
+// StaticGroupMapping maps each user to a fixed list of groups
+type StaticGroupMapping map[string][]string
+
+func (m StaticGroupMapping) Groups(user string) []string {
+	return m[user]
+}
+
+// LoadGroupMapping reads a StaticGroupMapping from a file listing a user
+// followed by its groups on each line; blank lines and lines starting with '#'
+// are ignored
+func LoadGroupMapping(path string) (StaticGroupMapping, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	mapping := make(StaticGroupMapping)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		mapping[fields[0]] = append(mapping[fields[0]], fields[1:]...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// SetGroupMapping configures how the groups of users are resolved
+func (fs *FileSystemService) SetGroupMapping(mapping GroupMapping) {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+	fs.groupMapping = mapping
+}
+
+// permissionChecker checks the access of one user to the namespace, the way
+// HDFS does: the owner's bits apply to the owner, the group's bits to members
+// of the inode's group and the other bits to everyone else. The superuser is
+// never denied.
+type permissionChecker struct {
+	root      *utils.Directory
+	user      string
+	groups    map[string]bool
+	superuser bool
+}
+
+// permissionChecker returns the checker of user. Must be called with
+// fs.rootMutex held.
+func (fs *FileSystemService) permissionChecker(user string) *permissionChecker {
+	checker := &permissionChecker{root: fs.rootDirectory, user: user, groups: make(map[string]bool)}
+	if fs.groupMapping != nil {
+		for _, group := range fs.groupMapping.Groups(user) {
+			checker.groups[group] = true
+		}
+	}
+	checker.superuser = !fs.config.PermissionsEnabled || user == fs.config.Superuser || checker.groups[fs.config.Supergroup]
+	return checker
+}
+
+// checkTraverse checks that the user may look up entries in dirPath and every
+// directory above it, snapshots included. Missing directories are left for the
+// caller to report.
+func (c *permissionChecker) checkTraverse(dirPath string) error {
+	if c.superuser {
+		return nil
+	}
+	return utils.WalkDirectories(c.root, dirPath, func(path string, dir *utils.Directory) error {
+		return c.check(path, dir.Inode, utils.ActionExecute)
+	})
+}
+
+// check checks that the user has access to the inode at path
+func (c *permissionChecker) check(path string, inode *utils.Inode, access utils.FsAction) error {
+	if c.superuser || c.granted(inode, access) {
+		return nil
+	}
+	return c.denied(path, inode, access)
+}
+
+// granted reports whether the permission and ACL of the inode grant access to
+// the user. As in HDFS, a named user entry takes precedence over the groups,
+// and a user in any group named by the ACL is denied unless one of those
+// groups' entries grants the whole access.
+func (c *permissionChecker) granted(inode *utils.Inode, access utils.FsAction) bool {
+	permission := inode.Permission
+	if c.user == inode.Owner {
+		return permission.Owner()&access == access
+	}
+
+	if acl := utils.AccessEntries(inode.Acl); len(acl) > 0 {
+		mask := permission.Group()
+		for _, entry := range acl {
+			if entry.Type == utils.AclUser && entry.Name == c.user {
+				return entry.Permission&mask&access == access
+			}
+		}
+		member := false
+		for _, entry := range acl {
+			if entry.Type != utils.AclGroup {
+				continue
+			}
+			group := entry.Name
+			if group == "" {
+				group = inode.Group
+			}
+			if c.groups[group] {
+				member = true
+				if entry.Permission&mask&access == access {
+					return true
+				}
+			}
+		}
+		if member {
+			return false
+		}
+	} else if c.groups[inode.Group] {
+		return permission.Group()&access == access
+	}
+	return permission.Other()&access == access
+}
+
+// checkOwner checks that the user owns the inode at path
+func (c *permissionChecker) checkOwner(path string, inode *utils.Inode) error {
+	if c.superuser || c.user == inode.Owner {
+		return nil
+	}
+	return fmt.Errorf("%w: user %s is not the owner of %s", utils.ErrPermissionDenied, c.user, path)
+}
+
+// checkStickyBit checks that the user may remove child from a parent directory
+// with the sticky bit set: only the owner of either may
+func (c *permissionChecker) checkStickyBit(path string, parent, child *utils.Inode) error {
+	if c.superuser || !parent.Permission.Sticky() || c.user == parent.Owner || c.user == child.Owner {
+		return nil
+	}
+	return fmt.Errorf("%w: the sticky bit is set on the parent of %s, owned by %s", utils.ErrPermissionDenied, path, child.Owner)
+}
+
+// checkSubtree checks that the user has access to dir and every directory below it
+func (c *permissionChecker) checkSubtree(path string, dir *utils.Directory, access utils.FsAction) error {
+	if c.superuser {
+		return nil
+	}
+	if err := c.check(path, dir.Inode, access); err != nil {
+		return err
+	}
+	for name, child := range dir.ChildDirs {
+		if err := c.checkSubtree(filepath.Join(path, name), child, access); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *permissionChecker) denied(path string, inode *utils.Inode, access utils.FsAction) error {
+	kind := "-"
+	if inode.IsDir {
+		kind = "d"
+	}
+	mode := inode.Permission.String()
+	if len(inode.Acl) > 0 {
+		mode += "+"
+	}
+	return fmt.Errorf("%w: user=%s, access=%s, inode=%q:%s:%s:%s%s",
+		utils.ErrPermissionDenied, c.user, access, path, inode.Owner, inode.Group, kind, mode)
+}
+
+// newInodeOwnership sets the owner, group and permission of an inode created
+// by user in parent: the group is inherited from the parent, as in HDFS, and
+// the umask is removed from the default permission, unless the parent has a
+// default ACL to inherit instead
+func (fs *FileSystemService) newInodeOwnership(inode *utils.Inode, user string, parent *utils.Inode) {
+	inode.Owner = user
+	inode.Group = parent.Group
+	mode := utils.DefaultFilePermission
+	if inode.IsDir {
+		mode = utils.DefaultDirPermission
+	}
+	if len(utils.DefaultEntries(parent.Acl)) > 0 {
+		inode.Permission, inode.Acl = utils.InheritAcl(mode, parent.Acl, inode.IsDir)
+		return
+	}
+	inode.Permission = mode &^ fs.config.Umask
+}
+
+// assignDefaultOwnership gives the inodes of a namespace saved before
+// permissions existed to the superuser, with the default permissions
+func (fs *FileSystemService) assignDefaultOwnership(dir *utils.Directory) {
+	assign := func(inode *utils.Inode) {
+		if inode.Owner != "" {
+			return
+		}
+		inode.Owner = fs.config.Superuser
+		inode.Group = fs.config.Supergroup
+		if inode.IsDir {
+			inode.Permission = utils.DefaultDirPermission &^ fs.config.Umask
+		} else {
+			inode.Permission = utils.DefaultFilePermission &^ fs.config.Umask
+		}
+	}
+
+	assign(dir.Inode)
+	for _, inode := range dir.ChildFiles {
+		assign(inode)
+	}
+	for _, child := range dir.ChildDirs {
+		fs.assignDefaultOwnership(child)
+	}
+}
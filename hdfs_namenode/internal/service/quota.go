@@ -0,0 +1,83 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	utils "github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+)
+
+// SetQuota bounds the number of files and directories, and the bytes stored
+// with every replica, below a directory; 0 removes a limit. Only the
+// superuser may set quotas. A quota may be set below the current usage, in
+// which case nothing more can be added until enough is removed.
+func (fs *FileSystemService) SetQuota(user, dirPath string, namespaceQuota, spaceQuota int64) error {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	if err := checkWritable(dirPath); err != nil {
+		return err
+	}
+	checker := fs.permissionChecker(user)
+	if !checker.superuser {
+		return fmt.Errorf("%w: only the superuser may set quotas", utils.ErrPermissionDenied)
+	}
+	if namespaceQuota < 0 || spaceQuota < 0 {
+		return fmt.Errorf("invalid quota: quotas must not be negative")
+	}
+	dir := utils.MutableDirectory(fs.rootDirectory, dirPath)
+	if dir == nil {
+		return fmt.Errorf("directory %w", utils.ErrNotFound)
+	}
+
+	if err := persistence.RecordSetQuota(filepath.Clean(dirPath), namespaceQuota, spaceQuota); err != nil {
+		return err
+	}
+	dir.Quota = nil
+	if namespaceQuota > 0 || spaceQuota > 0 {
+		usage := utils.SubtreeUsage(dir)
+		dir.Quota = &utils.Quota{
+			NamespaceQuota: namespaceQuota,
+			SpaceQuota:     spaceQuota,
+			NamespaceUsed:  usage.Namespace,
+			SpaceUsed:      usage.Space,
+		}
+	}
+
+	return nil
+}
+
+// verifyQuota checks that adding delta below dirPath keeps every directory
+// within its quota, counting the files still being written. Must be called
+// with fs.rootMutex held.
+func (fs *FileSystemService) verifyQuota(dirPath string, delta utils.Usage) error {
+	return utils.VerifyQuota(fs.rootDirectory, dirPath, delta, fs.pendingUsage)
+}
+
+// pendingUsage returns the usage of the files being written below dirPath,
+// which only counts towards quotas once they are completed. Must be called
+// with fs.rootMutex held.
+func (fs *FileSystemService) pendingUsage(dirPath string) utils.Usage {
+	prefix := strings.TrimSuffix(filepath.Clean(dirPath), "/") + "/"
+	var usage utils.Usage
+	for filePath, pending := range fs.underConstruction {
+		if strings.HasPrefix(filePath, prefix) {
+			usage = usage.Add(utils.FileUsage(pending.inode))
+		}
+	}
+	return usage
+}
+
+// usageAt returns the usage of the file or directory at path. Must be called
+// with fs.rootMutex held.
+func (fs *FileSystemService) usageAt(path string) utils.Usage {
+	if dir := utils.FindDirectory(fs.rootDirectory, path); dir != nil {
+		return utils.SubtreeUsage(dir)
+	}
+	if inode := utils.Lookup(fs.rootDirectory, path); inode != nil {
+		return utils.FileUsage(inode)
+	}
+	return utils.Usage{}
+}
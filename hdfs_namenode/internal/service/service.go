@@ -3,99 +3,163 @@ package service
 import (
 	"errors"
 	"fmt"
+	"log"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/aarrasseayoub01/namenode/namenode/internal/config"
 	utils "github.com/aarrasseayoub01/namenode/namenode/internal/fs"
 	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
 	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
 )
 
+// blockSize is the maximum number of bytes stored in a single block.
+const blockSize int64 = 64 * 1024 * 1024
+
 type FileSystemService struct {
 	rootDirectory *utils.Directory
 	rootMutex     sync.RWMutex
+	config        *config.Config
+	// placementPolicy chooses the DataNodes storing new blocks
+	placementPolicy gRPC.BlockPlacementPolicy
+	// groupMapping resolves the groups of users for permission checks
+	groupMapping GroupMapping
+
+	// Files whose blocks are still being written, keyed by file path
+	underConstruction map[string]*pendingFile
+
+	// snapshotBlocks counts the snapshotted files sharing each block, keyed
+	// by block ID; retainedBlocks holds the blocks of deleted files kept
+	// until no snapshot references them
+	snapshotBlocks map[string]int
+	retainedBlocks map[string]utils.BlockAssignment
+}
+
+// pendingFile is a file that has been allocated blocks but is not yet part of
+// the namespace. It becomes visible once every block has been acknowledged.
+type pendingFile struct {
+	inode *utils.Inode
+	// DataNodes that acknowledged each block, keyed by block ID
+	acked map[string][]string
+}
+
+func NewFileSystemService(root *utils.Directory, cfg *config.Config) *FileSystemService {
+	// The default policy is always registered; main replaces it with the
+	// configured one
+	placementPolicy, err := gRPC.NewPlacementPolicy(gRPC.DefaultPlacementPolicy)
+	if err != nil {
+		log.Fatalf("Failed to create the default block placement policy: %v", err)
+	}
+	fs := &FileSystemService{
+		rootDirectory:     root,
+		config:            cfg,
+		placementPolicy:   placementPolicy,
+		underConstruction: make(map[string]*pendingFile),
+		snapshotBlocks:    make(map[string]int),
+		retainedBlocks:    make(map[string]utils.BlockAssignment),
+	}
+	// Let block reports tell the namespace's blocks from orphans
+	fs.registerBlocks(root)
+	fs.loadSnapshotBlocks(root)
+	fs.assignDefaultOwnership(root)
+	utils.UpdateQuotaUsage(root)
+	// Checkpoints copy the namespace between requests changing it
+	persistence.SetNamespaceLock(fs.rootMutex.RLocker())
+	return fs
 }
 
-func NewFileSystemService(root *utils.Directory) *FileSystemService {
-	return &FileSystemService{rootDirectory: root}
-}
-
-// CreateFile creates a new file in the file system.
-// func (fs *FileSystemService) CreateFile(filePath string) (*utils.Inode, error) {
-// 	fs.rootMutex.Lock()
-// 	defer fs.rootMutex.Unlock()
-// 	dirPath, fileName := filepath.Split(filePath)
-// 	parentDir := utils.FindDirectory(fs.rootDirectory, dirPath)
-// 	if parentDir == nil {
-// 		return nil, fmt.Errorf("parent directory does not exist")
-// 	}
-// 	if _, exists := parentDir.ChildFiles[fileName]; exists {
-// 		return nil, fmt.Errorf("file already exists")
-// 	}
-
-// 	newFileInode := &utils.Inode{
-// 		ID:        utils.GenerateInodeID(),
-// 		Name:      fileName,
-// 		IsDir:     false,
-// 		Size:      0,
-// 		Timestamp: time.Now(),
-// 	}
-// 	parentDir.ChildFiles[fileName] = newFileInode
-// 	persistence.RecordEditLog("CREATE_FILE", filePath, newFileInode, blocks)
-
-// 	return newFileInode, nil
-// }
+// SetPlacementPolicy configures how the DataNodes storing new blocks are chosen
+func (fs *FileSystemService) SetPlacementPolicy(policy gRPC.BlockPlacementPolicy) {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+	fs.placementPolicy = policy
+}
 
 // ReadFile reads a file in the file system.
-func (fs *FileSystemService) ReadFile(filePath string) (*utils.Inode, error) {
+func (fs *FileSystemService) ReadFile(user, filePath string) (*utils.Inode, error) {
 	fs.rootMutex.Lock()
 	defer fs.rootMutex.Unlock()
+	return fs.lookupFile(fs.permissionChecker(user), filePath)
+}
+
+// lookupFile returns the inode of a file whose parent directories the user
+// may traverse. Must be called with fs.rootMutex held.
+func (fs *FileSystemService) lookupFile(checker *permissionChecker, filePath string) (*utils.Inode, error) {
 	dirPath, fileName := filepath.Split(filePath)
+	if err := checker.checkTraverse(dirPath); err != nil {
+		return nil, err
+	}
 	parentDir := utils.FindDirectory(fs.rootDirectory, dirPath)
 	if parentDir == nil {
-		return nil, fmt.Errorf("parent directory does not exist")
+		return nil, fmt.Errorf("parent directory %w", utils.ErrNotFound)
 	}
-	if _, exists := parentDir.ChildFiles[fileName]; !exists {
-		return nil, fmt.Errorf("file doesn't exists")
+	inode, exists := parentDir.ChildFiles[fileName]
+	if !exists {
+		return nil, fmt.Errorf("file %w", utils.ErrNotFound)
 	}
-
-	return parentDir.ChildFiles[fileName], nil
+	return inode, nil
 }
 
 // DeleteFile deletes a file from the file system.
-func (fs *FileSystemService) DeleteFile(filePath string) error {
+func (fs *FileSystemService) DeleteFile(user, filePath string) error {
 	fs.rootMutex.Lock()
 	defer fs.rootMutex.Unlock()
 
+	if err := checkWritable(filePath); err != nil {
+		return err
+	}
+	if err := fs.checkRemove(fs.permissionChecker(user), filePath); err != nil {
+		return err
+	}
+
 	dirPath, fileName := filepath.Split(filePath)
-	parentDir := utils.FindDirectory(fs.rootDirectory, dirPath)
+	parentDir := utils.MutableDirectory(fs.rootDirectory, dirPath)
 	if parentDir == nil {
-		return fmt.Errorf("directory does not exist")
+		return fmt.Errorf("directory %w", utils.ErrNotFound)
 	}
 	if _, exists := parentDir.ChildFiles[fileName]; !exists {
-		return fmt.Errorf("file does not exist")
+		return fmt.Errorf("file %w", utils.ErrNotFound)
 	}
 
+	if err := persistence.RecordEditLog("DELETE_FILE", filePath, nil); err != nil {
+		return err
+	}
+	// The blocks are only deleted once the deletion is logged
+	fs.invalidateBlocks(parentDir.ChildFiles[fileName])
+	utils.AddUsage(fs.rootDirectory, dirPath, utils.FileUsage(parentDir.ChildFiles[fileName]).Negate())
 	delete(parentDir.ChildFiles, fileName)
 
-	persistence.RecordEditLog("DELETE_FILE", filePath, nil)
-
 	return nil
 }
 
 // CreateDirectory creates a new directory in the file system.
-func (fs *FileSystemService) CreateDirectory(dirPath string) (*utils.Inode, error) {
+func (fs *FileSystemService) CreateDirectory(user, dirPath string) (*utils.Inode, error) {
 	fs.rootMutex.Lock()
 	defer fs.rootMutex.Unlock()
+	if err := checkWritable(dirPath); err != nil {
+		return nil, err
+	}
 	parentPath, dirName := filepath.Dir(dirPath), filepath.Base(dirPath)
-	parentDir := utils.FindDirectory(fs.rootDirectory, parentPath)
+	checker := fs.permissionChecker(user)
+	if err := checker.checkTraverse(parentPath); err != nil {
+		return nil, err
+	}
+	parentDir := utils.MutableDirectory(fs.rootDirectory, parentPath)
 	if parentDir == nil {
 		return nil, fmt.Errorf("No parent path provided " + parentPath)
 	}
 	if _, exists := parentDir.ChildDirs[dirName]; exists {
 		return nil, fmt.Errorf("directory already exists")
 	}
+	if err := checker.check(parentPath, parentDir.Inode, utils.ActionWrite); err != nil {
+		return nil, err
+	}
+	if err := fs.verifyQuota(parentPath, utils.Usage{Namespace: 1}); err != nil {
+		return nil, err
+	}
 
 	newDirInode := &utils.Inode{
 		ID:        utils.GenerateInodeID(),
@@ -104,39 +168,44 @@ func (fs *FileSystemService) CreateDirectory(dirPath string) (*utils.Inode, erro
 		Blocks:    []utils.BlockAssignment{},
 		Timestamp: time.Now(),
 	}
+	fs.newInodeOwnership(newDirInode, user, parentDir.Inode)
+	if err := persistence.RecordEditLog("CREATE_DIRECTORY", dirPath, newDirInode); err != nil {
+		return nil, err
+	}
 	parentDir.ChildDirs[dirName] = &utils.Directory{
 		Inode:      newDirInode,
 		ChildFiles: make(map[string]*utils.Inode),
 		ChildDirs:  make(map[string]*utils.Directory),
 	}
-
-	persistence.RecordEditLog("CREATE_DIRECTORY", dirPath, newDirInode)
+	utils.AddUsage(fs.rootDirectory, parentPath, utils.Usage{Namespace: 1})
 
 	return newDirInode, nil
 }
 
-func (fs *FileSystemService) ReadDirectory(dirPath string) ([]*utils.Inode, error) {
+func (fs *FileSystemService) ReadDirectory(user, dirPath string) ([]*utils.Inode, error) {
 	fs.rootMutex.Lock()
 	defer fs.rootMutex.Unlock()
 
-	// parentPath, dirName := filepath.Dir(dirPath), filepath.Base(dirPath)
+	checker := fs.permissionChecker(user)
+	if err := checker.checkTraverse(filepath.Dir(dirPath)); err != nil {
+		return nil, err
+	}
+	// <dir>/.snapshot lists the snapshots of dir
+	if filepath.Base(dirPath) == utils.SnapshotDirName {
+		return fs.listSnapshots(checker, dirPath)
+	}
 
-	// Find the parent directory
+	// Find the directory
 	dir := utils.FindDirectory(fs.rootDirectory, dirPath)
-	fmt.Println(dirPath)
 	if dir == nil {
 		return nil, fmt.Errorf("no parent path provided: %s", dirPath)
 	}
-
-	// // Check if the directory exists
-	// dir, exists := parentDir.ChildDirs[dirName]
-	// if !exists {
-	// 	return nil, fmt.Errorf("directory does not exist: %s", dirPath)
-	// }
+	if err := checker.check(dirPath, dir.Inode, utils.ActionRead|utils.ActionExecute); err != nil {
+		return nil, err
+	}
 
 	// Read child files and directories
 	childFiles := make([]*utils.Inode, 0, len(dir.ChildFiles))
-	fmt.Println(dirPath)
 	for _, inode := range dir.ChildFiles {
 		childFiles = append(childFiles, inode)
 	}
@@ -150,81 +219,523 @@ func (fs *FileSystemService) ReadDirectory(dirPath string) ([]*utils.Inode, erro
 	return childFiles, nil
 }
 
-// DeleteDirectory deletes a directory from the file system.
-func (fs *FileSystemService) DeleteDirectory(dirPath string) error {
+// DeleteDirectory deletes a directory from the file system. Non-empty
+// directories are only deleted if recursive is set, in which case the whole
+// subtree goes at once and the DataNodes are asked to delete its blocks.
+func (fs *FileSystemService) DeleteDirectory(user, dirPath string, recursive bool) error {
 	fs.rootMutex.Lock()
 	defer fs.rootMutex.Unlock()
 
+	if err := checkWritable(dirPath); err != nil {
+		return err
+	}
+	checker := fs.permissionChecker(user)
+	if err := fs.checkRemove(checker, dirPath); err != nil {
+		return err
+	}
+
 	parentPath, dirName := filepath.Dir(dirPath), filepath.Base(dirPath)
-	parentDir := utils.FindDirectory(fs.rootDirectory, parentPath)
+	parentDir := utils.MutableDirectory(fs.rootDirectory, parentPath)
 	if parentDir == nil {
-		return fmt.Errorf("directory does not exist")
+		return fmt.Errorf("directory %w", utils.ErrNotFound)
 	}
-	if dir, exists := parentDir.ChildDirs[dirName]; !exists || len(dir.ChildFiles) > 0 || len(dir.ChildDirs) > 0 {
+	dir, exists := parentDir.ChildDirs[dirName]
+	if !exists || (!recursive && (len(dir.ChildFiles) > 0 || len(dir.ChildDirs) > 0)) {
 		return fmt.Errorf("directory is not empty or does not exist")
 	}
+	if utils.HasSnapshots(dir) {
+		return fmt.Errorf("directory has snapshots, which must be deleted first")
+	}
+	// Deleting a subtree needs full access to every directory in it
+	if len(dir.ChildFiles) > 0 || len(dir.ChildDirs) > 0 {
+		if err := checker.checkSubtree(dirPath, dir, utils.ActionAll); err != nil {
+			return err
+		}
+	}
 
+	if err := persistence.RecordEditLog("DELETE_DIRECTORY", dirPath, nil); err != nil {
+		return err
+	}
 	delete(parentDir.ChildDirs, dirName)
+	utils.AddUsage(fs.rootDirectory, parentPath, utils.SubtreeUsage(dir).Negate())
+
+	// The blocks are only deleted once the deletion is logged
+	if recursive {
+		utils.WalkFiles(dir, fs.invalidateBlocks)
+
+		// Files still being written under the directory can't be completed
+		prefix := filepath.Clean(dirPath) + "/"
+		for filePath, pending := range fs.underConstruction {
+			if strings.HasPrefix(filePath, prefix) {
+				fs.invalidateBlocks(pending.inode)
+				delete(fs.underConstruction, filePath)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Rename atomically moves a file or a whole directory subtree from srcPath to
+// dstPath. An existing file, or empty directory, at dstPath is only replaced
+// if overwrite is set. Files being written under a moved directory are
+// completed at their new path.
+func (fs *FileSystemService) Rename(user, srcPath, dstPath string, overwrite bool) error {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	if err := checkWritable(srcPath, dstPath); err != nil {
+		return err
+	}
+	if err := fs.checkRename(fs.permissionChecker(user), srcPath, dstPath, overwrite); err != nil {
+		return err
+	}
+	if dir := utils.FindDirectory(fs.rootDirectory, dstPath); overwrite && dir != nil && utils.HasSnapshots(dir) {
+		return fmt.Errorf("destination directory has snapshots, which must be deleted first")
+	}
+
+	// The moved usage leaves the quotas above the source before those above
+	// the destination are checked, so common ancestors don't count it twice
+	srcParent, dstParent := filepath.Dir(filepath.Clean(srcPath)), filepath.Dir(filepath.Clean(dstPath))
+	moved := fs.usageAt(srcPath)
+	delta := moved
+	if overwrite && utils.Lookup(fs.rootDirectory, dstPath) != nil {
+		delta = delta.Add(fs.usageAt(dstPath).Negate())
+	}
+	utils.AddUsage(fs.rootDirectory, srcParent, moved.Negate())
+	if err := fs.verifyQuota(dstParent, delta); err != nil {
+		utils.AddUsage(fs.rootDirectory, srcParent, moved)
+		return err
+	}
+
+	move, err := utils.PrepareRename(fs.rootDirectory, srcPath, dstPath, overwrite)
+	if err == nil {
+		err = persistence.RecordRename(filepath.Clean(srcPath), filepath.Clean(dstPath), overwrite)
+	}
+	if err != nil {
+		utils.AddUsage(fs.rootDirectory, srcParent, moved)
+		return err
+	}
+	replaced := move()
+	utils.AddUsage(fs.rootDirectory, dstParent, delta)
+
+	srcPrefix := filepath.Clean(srcPath) + "/"
+	for filePath, pending := range fs.underConstruction {
+		if strings.HasPrefix(filePath, srcPrefix) {
+			delete(fs.underConstruction, filePath)
+			fs.underConstruction[filepath.Join(dstPath, strings.TrimPrefix(filePath, srcPrefix))] = pending
+		}
+	}
 
-	persistence.RecordEditLog("DELETE_DIRECTORY", dirPath, nil)
+	// The blocks of a replaced file are only deleted once the rename is logged
+	if replaced != nil {
+		fs.invalidateBlocks(replaced)
+	}
 
 	return nil
 }
 
-func (fs *FileSystemService) CreateFile(filePath string, fileSize int64) (*utils.Inode, error) {
-	const blockSize int64 = 64 * 1024 * 1024
-	var blockAssignments []utils.BlockAssignment
+// SetPermission changes the permission of a file or directory. Only its owner
+// may change it.
+func (fs *FileSystemService) SetPermission(user, path string, permission utils.Permission) error {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	if err := checkWritable(path); err != nil {
+		return err
+	}
+	checker := fs.permissionChecker(user)
+	if err := checker.checkTraverse(filepath.Dir(filepath.Clean(path))); err != nil {
+		return err
+	}
+	inode := utils.MutableInode(fs.rootDirectory, path)
+	if inode == nil {
+		return fmt.Errorf("file or directory %w", utils.ErrNotFound)
+	}
+	if err := checker.checkOwner(path, inode); err != nil {
+		return err
+	}
+
+	if err := persistence.RecordSetPermission(filepath.Clean(path), permission); err != nil {
+		return err
+	}
+	inode.Permission = permission
+
+	return nil
+}
+
+// SetOwner changes the owner and group of a file or directory; empty values
+// are left unchanged. Only the superuser may give an inode away; its owner may
+// change its group to one they belong to.
+func (fs *FileSystemService) SetOwner(user, path, owner, group string) error {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	if owner == "" && group == "" {
+		return fmt.Errorf("an owner or a group is required")
+	}
+	if err := checkWritable(path); err != nil {
+		return err
+	}
+	checker := fs.permissionChecker(user)
+	if err := checker.checkTraverse(filepath.Dir(filepath.Clean(path))); err != nil {
+		return err
+	}
+	inode := utils.MutableInode(fs.rootDirectory, path)
+	if inode == nil {
+		return fmt.Errorf("file or directory %w", utils.ErrNotFound)
+	}
+	if !checker.superuser {
+		if owner != "" && owner != inode.Owner {
+			return fmt.Errorf("%w: only the superuser may change the owner of %s", utils.ErrPermissionDenied, path)
+		}
+		if err := checker.checkOwner(path, inode); err != nil {
+			return err
+		}
+		if group != "" && !checker.groups[group] {
+			return fmt.Errorf("%w: user %s does not belong to group %s", utils.ErrPermissionDenied, user, group)
+		}
+	}
+
+	if err := persistence.RecordSetOwner(filepath.Clean(path), owner, group); err != nil {
+		return err
+	}
+	if owner != "" {
+		inode.Owner = owner
+	}
+	if group != "" {
+		inode.Group = group
+	}
+
+	return nil
+}
+
+// checkRename checks that the user may remove srcPath from its directory and
+// add dstPath to its own, replacing what is there if overwrite is set. Missing
+// paths are left for utils.PrepareRename to report. Must be called with
+// fs.rootMutex held.
+func (fs *FileSystemService) checkRename(checker *permissionChecker, srcPath, dstPath string, overwrite bool) error {
+	if err := fs.checkRemove(checker, srcPath); err != nil {
+		return err
+	}
+	if overwrite && utils.Lookup(fs.rootDirectory, dstPath) != nil {
+		return fs.checkRemove(checker, dstPath)
+	}
+
+	parentPath := filepath.Dir(filepath.Clean(dstPath))
+	if err := checker.checkTraverse(parentPath); err != nil {
+		return err
+	}
+	if parent := utils.FindDirectory(fs.rootDirectory, parentPath); parent != nil {
+		return checker.check(parentPath, parent.Inode, utils.ActionWrite)
+	}
+	return nil
+}
+
+// checkRemove checks that the user may remove the entry at path from its
+// directory. Must be called with fs.rootMutex held.
+func (fs *FileSystemService) checkRemove(checker *permissionChecker, path string) error {
+	path = filepath.Clean(path)
+	parentPath := filepath.Dir(path)
+	if err := checker.checkTraverse(parentPath); err != nil {
+		return err
+	}
+	parent := utils.FindDirectory(fs.rootDirectory, parentPath)
+	inode := utils.Lookup(fs.rootDirectory, path)
+	if parent == nil || inode == nil {
+		return nil
+	}
+	if err := checker.check(parentPath, parent.Inode, utils.ActionWrite); err != nil {
+		return err
+	}
+	return checker.checkStickyBit(path, parent.Inode, inode)
+}
+
+// CreateFile allocates blocks for a new file and registers it as under
+// construction. Each block is assigned to replication distinct DataNodes
+// (the cluster default when replication is 0), the first of which heads the
+// write pipeline. The returned inode carries the block assignments the client
+// must write to; the file stays invisible until CompleteFile is called.
+// writer is the address of the client writing the file, whose rack gets the
+// first replica of each block, or empty if unknown.
+func (fs *FileSystemService) CreateFile(user, filePath string, fileSize int64, replication int, writer string) (*utils.Inode, error) {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	if fileSize < 0 {
+		return nil, fmt.Errorf("invalid file size: %d", fileSize)
+	}
+	if replication == 0 {
+		replication = fs.config.DefaultReplication
+	}
+	if replication < 1 || replication > fs.config.MaxReplication {
+		return nil, fmt.Errorf("replication must be between 1 and %d", fs.config.MaxReplication)
+	}
+
+	if err := checkWritable(filePath); err != nil {
+		return nil, err
+	}
+	dirPath, fileName := filepath.Split(filePath)
+	checker := fs.permissionChecker(user)
+	if err := checker.checkTraverse(dirPath); err != nil {
+		return nil, err
+	}
+	parentDir := utils.FindDirectory(fs.rootDirectory, dirPath)
+	if parentDir == nil {
+		return nil, fmt.Errorf("parent directory %w", utils.ErrNotFound)
+	}
+	if err := checker.check(dirPath, parentDir.Inode, utils.ActionWrite); err != nil {
+		return nil, err
+	}
+	if _, exists := parentDir.ChildFiles[fileName]; exists {
+		return nil, fmt.Errorf("file already exists")
+	}
+	if _, exists := fs.underConstruction[filePath]; exists {
+		return nil, fmt.Errorf("file is already being written")
+	}
+	// Every replica of the file counts towards space quotas
+	if err := fs.verifyQuota(dirPath, utils.Usage{Namespace: 1, Space: fileSize * int64(replication)}); err != nil {
+		return nil, err
+	}
 
-	dataNodeManager := gRPC.GetInstance()
-	dataNodes := dataNodeManager.GetDataNodes()
 	// Calculate the number of blocks needed
 	numBlocks := fileSize / blockSize
 	if fileSize%blockSize != 0 {
 		numBlocks++
 	}
 
-	// Prepare a slice of DataNode addresses for round-robin allocation
-	dataNodeAddresses := make([]string, 0, len(dataNodes))
-
-	if len(dataNodes) == 0 {
-		return nil, errors.New("There are no DataNodes")
+	// Stale and dead DataNodes are left out of placement
+	dataNodeManager := gRPC.GetInstance()
+	dataNodes := dataNodeManager.GetLiveDataNodes()
+	if numBlocks > 0 && len(dataNodes) == 0 {
+		return nil, errors.New("There are no live DataNodes")
 	}
-	for address := range dataNodes {
-		dataNodeAddresses = append(dataNodeAddresses, address)
+
+	writerRack := ""
+	if writer != "" {
+		writerRack = dataNodeManager.ResolveRack(writer)
 	}
 
+	inodeID := utils.GenerateInodeID()
+
 	// Assign blocks to DataNodes
+	blockAssignments := make([]utils.BlockAssignment, 0, numBlocks)
 	for i := int64(0); i < numBlocks; i++ {
-		blockID := fmt.Sprintf("%s-block-%d", filepath.Base(filePath), i)
-		dataNodeIndex := i % int64(len(dataNodeAddresses)) // Round-robin allocation
+		// Block IDs embed the inode ID so files with the same name in
+		// different directories never share a block on a DataNode
+		blockID := fmt.Sprintf("%d-block-%d", inodeID, i)
+
+		size := blockSize
+		if remaining := fileSize - i*blockSize; remaining < size {
+			size = remaining
+		}
+
+		// A block can't have more replicas than there are DataNodes; the
+		// missing ones are made up by the replication monitor once more
+		// nodes join
+		targets := fs.placementPolicy.ChooseTargets(replication, writerRack, size, dataNodes, nil)
+		if len(targets) == 0 {
+			return nil, errors.New("There are no DataNodes with enough remaining capacity")
+		}
+		reserve(dataNodes, targets, size)
 
 		blockAssignments = append(blockAssignments, utils.BlockAssignment{
 			BlockID:           blockID,
-			DataNodeAddresses: []string{dataNodeAddresses[dataNodeIndex]},
+			DataNodeAddresses: targets,
 		})
 	}
 
+	newFileInode := &utils.Inode{
+		ID:        inodeID,
+		Name:      fileName,
+		IsDir:     false,
+		Size:      fileSize,
+		Blocks:    blockAssignments,
+		Timestamp: time.Now(),
+
+		Replication: replication,
+	}
+	fs.newInodeOwnership(newFileInode, user, parentDir.Inode)
+	blockMap := gRPC.GetBlockMap()
+	for _, block := range blockAssignments {
+		blockMap.AllocateBlock(block.BlockID, replication)
+	}
+	fs.underConstruction[filePath] = &pendingFile{
+		inode: newFileInode,
+		acked: make(map[string][]string),
+	}
+
+	return newFileInode, nil
+}
+
+// CompleteFile records the acknowledged blocks of a file under construction
+// and, once every block is acknowledged by at least one DataNode, adds the
+// file to the namespace. The DataNodes that acknowledged a block replace its
+// assigned locations, so replicas lost in a pipeline failure are not listed;
+// acknowledgements from DataNodes the block wasn't assigned to are ignored.
+// Only the user who created the file may complete it.
+func (fs *FileSystemService) CompleteFile(user, filePath string, ackedBlocks []utils.BlockAssignment) (*utils.Inode, error) {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	pending, exists := fs.underConstruction[filePath]
+	if !exists {
+		return nil, fmt.Errorf("file is not under construction")
+	}
+	if err := fs.permissionChecker(user).checkOwner(filePath, pending.inode); err != nil {
+		return nil, err
+	}
+
+	assigned := make(map[string][]string, len(pending.inode.Blocks))
+	for _, block := range pending.inode.Blocks {
+		assigned[block.BlockID] = block.DataNodeAddresses
+	}
+	for _, block := range ackedBlocks {
+		targets, exists := assigned[block.BlockID]
+		if !exists {
+			return nil, fmt.Errorf("block %s is not part of the file", block.BlockID)
+		}
+		var acked []string
+		for _, address := range block.DataNodeAddresses {
+			if slices.Contains(targets, address) && !slices.Contains(acked, address) {
+				acked = append(acked, address)
+			}
+		}
+		if len(acked) > 0 {
+			pending.acked[block.BlockID] = acked
+		}
+	}
+	for _, block := range pending.inode.Blocks {
+		if len(pending.acked[block.BlockID]) == 0 {
+			return nil, fmt.Errorf("block %s has not been acknowledged", block.BlockID)
+		}
+	}
+
 	dirPath, fileName := filepath.Split(filePath)
-	parentDir := utils.FindDirectory(fs.rootDirectory, dirPath)
+	parentDir := utils.MutableDirectory(fs.rootDirectory, dirPath)
 	if parentDir == nil {
-		return nil, fmt.Errorf("parent directory does not exist")
+		delete(fs.underConstruction, filePath)
+		fs.invalidateBlocks(pending.inode)
+		return nil, fmt.Errorf("parent directory %w", utils.ErrNotFound)
 	}
 	if _, exists := parentDir.ChildFiles[fileName]; exists {
+		delete(fs.underConstruction, filePath)
+		fs.invalidateBlocks(pending.inode)
 		return nil, fmt.Errorf("file already exists")
 	}
 
-	newFileInode := &utils.Inode{
-		ID:        utils.GenerateInodeID(),
-		Name:      fileName,
-		IsDir:     false,
-		Size:      fileSize,
-		Blocks:    utils.AllocateFileBlocksResponse{BlockAssignments: blockAssignments}.BlockAssignments,
-		Timestamp: time.Now(),
+	// The file is logged with the DataNodes that acknowledged its blocks, and
+	// stays under construction if that fails
+	completed := *pending.inode
+	completed.Blocks = make([]utils.BlockAssignment, len(pending.inode.Blocks))
+	for i, block := range pending.inode.Blocks {
+		completed.Blocks[i] = utils.BlockAssignment{BlockID: block.BlockID, DataNodeAddresses: pending.acked[block.BlockID]}
+	}
+	if err := persistence.RecordEditLog("CREATE_FILE", filePath, &completed); err != nil {
+		return nil, err
 	}
-	parentDir.ChildFiles[fileName] = newFileInode
-	persistence.RecordEditLog("CREATE_FILE", filePath, newFileInode)
 
-	// return &utils.AllocateFileBlocksResponse{BlockAssignments: blockAssignments}, nil
-	return newFileInode, nil
+	delete(fs.underConstruction, filePath)
+	blockMap := gRPC.GetBlockMap()
+	pending.inode.Blocks = completed.Blocks
+	for _, block := range pending.inode.Blocks {
+		blockMap.CompleteBlock(block.BlockID)
+	}
+	parentDir.ChildFiles[fileName] = pending.inode
+	utils.AddUsage(fs.rootDirectory, dirPath, utils.FileUsage(pending.inode))
+
+	return pending.inode, nil
+}
+
+// AbandonFile discards a file under construction. Only the user who created
+// the file may abandon it.
+func (fs *FileSystemService) AbandonFile(user, filePath string) error {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	pending, exists := fs.underConstruction[filePath]
+	if !exists {
+		return fmt.Errorf("file is not under construction")
+	}
+	if err := fs.permissionChecker(user).checkOwner(filePath, pending.inode); err != nil {
+		return err
+	}
+	delete(fs.underConstruction, filePath)
+	fs.invalidateBlocks(pending.inode)
+
+	return nil
+}
+
+// reserve accounts for a block about to be written to targets, so the next
+// blocks of the same file are placed knowing about it
+func reserve(dataNodes map[string]*gRPC.DataNode, targets []string, size int64) {
+	for _, address := range targets {
+		stats := &dataNodes[address].Stats
+		stats.ActiveTransfers++
+		stats.DfsUsedBytes += size
+		if stats.CapacityBytes > 0 {
+			stats.RemainingBytes -= size
+		}
+	}
+}
+
+// invalidateBlocks removes the blocks of a file leaving the namespace from the
+// block map and asks the DataNodes holding them to delete their replicas.
+// Blocks a snapshot still references are retained until it is deleted.
+func (fs *FileSystemService) invalidateBlocks(inode *utils.Inode) {
+	blocks := make([]utils.BlockAssignment, 0, len(inode.Blocks))
+	for _, block := range inode.Blocks {
+		if fs.snapshotBlocks[block.BlockID] > 0 {
+			fs.retainedBlocks[block.BlockID] = block
+			continue
+		}
+		blocks = append(blocks, block)
+	}
+	fs.deleteBlocks(blocks)
+}
 
+// deleteBlocks removes blocks from the block map and asks the DataNodes
+// holding them to delete their replicas. Replicas written but not reported
+// yet are found from the block assignments.
+func (fs *FileSystemService) deleteBlocks(blocks []utils.BlockAssignment) {
+	blockMap := gRPC.GetBlockMap()
+	replicas := make(map[string][]string)
+	for _, block := range blocks {
+		locations := blockMap.RemoveBlock(block.BlockID)
+		for _, address := range block.DataNodeAddresses {
+			if !slices.Contains(locations, address) {
+				locations = append(locations, address)
+			}
+		}
+		for _, address := range locations {
+			replicas[address] = append(replicas[address], block.BlockID)
+		}
+	}
+
+	dataNodeManager := gRPC.GetInstance()
+	for address, blockIDs := range replicas {
+		dataNodeManager.QueueBlockDeletion(address, blockIDs)
+	}
+}
+
+// registerBlocks adds the blocks of every file under dir to the block map
+func (fs *FileSystemService) registerBlocks(dir *utils.Directory) {
+	blockMap := gRPC.GetBlockMap()
+	for _, inode := range dir.ChildFiles {
+		for _, block := range inode.Blocks {
+			blockMap.AddBlock(block.BlockID, fs.replication(inode))
+		}
+	}
+	for _, child := range dir.ChildDirs {
+		fs.registerBlocks(child)
+	}
+}
+
+// replication returns the number of replicas kept of the blocks of a file
+func (fs *FileSystemService) replication(inode *utils.Inode) int {
+	if inode.Replication == 0 {
+		// Files written before replication was recorded
+		return fs.config.DefaultReplication
+	}
+	return inode.Replication
 }
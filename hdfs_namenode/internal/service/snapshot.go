@@ -0,0 +1,302 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	utils "github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+)
+
+// AllowSnapshot lets snapshots be taken of a directory. Only the superuser may
+// allow snapshots, and snapshottable directories may not be nested.
+func (fs *FileSystemService) AllowSnapshot(user, dirPath string) error {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	dir, err := fs.snapshotAdminDirectory(user, dirPath)
+	if err != nil || dir.Snapshottable {
+		return err
+	}
+	nested := false
+	utils.WalkDirectories(fs.rootDirectory, dirPath, func(_ string, ancestor *utils.Directory) error {
+		nested = nested || ancestor.Snapshottable
+		return nil
+	})
+	if nested || hasSnapshottableDescendant(dir) {
+		return fmt.Errorf("nested snapshottable directories are not allowed")
+	}
+
+	if err := persistence.RecordEditLog("ALLOW_SNAPSHOT", filepath.Clean(dirPath), nil); err != nil {
+		return err
+	}
+	dir.Snapshottable = true
+
+	return nil
+}
+
+// DisallowSnapshot stops snapshots being taken of a directory, which must not
+// have any left. Only the superuser may disallow snapshots.
+func (fs *FileSystemService) DisallowSnapshot(user, dirPath string) error {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	dir, err := fs.snapshotAdminDirectory(user, dirPath)
+	if err != nil || !dir.Snapshottable {
+		return err
+	}
+	if len(dir.Snapshots) > 0 {
+		return fmt.Errorf("directory has %d snapshots, which must be deleted first", len(dir.Snapshots))
+	}
+
+	if err := persistence.RecordEditLog("DISALLOW_SNAPSHOT", filepath.Clean(dirPath), nil); err != nil {
+		return err
+	}
+	dir.Snapshottable = false
+
+	return nil
+}
+
+// CreateSnapshot takes a snapshot of a snapshottable directory, named after
+// the current time if name is empty, and returns its path. Only the owner of
+// the directory may take snapshots of it.
+func (fs *FileSystemService) CreateSnapshot(user, dirPath, name string) (string, error) {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	dir, err := fs.snapshotOwnerDirectory(user, dirPath)
+	if err != nil {
+		return "", err
+	}
+	timestamp := time.Now()
+	if name == "" {
+		name = "s" + timestamp.Format("20060102-150405.000")
+	}
+	snapshot, err := utils.CreateSnapshot(dir, name, timestamp)
+	if err != nil {
+		return "", err
+	}
+	if err := persistence.RecordCreateSnapshot(filepath.Clean(dirPath), name, timestamp); err != nil {
+		utils.DeleteSnapshot(dir, name)
+		return "", err
+	}
+	fs.referenceBlocks(snapshot)
+
+	return filepath.Join(dirPath, utils.SnapshotDirName, name), nil
+}
+
+// DeleteSnapshot deletes a snapshot of a directory. Blocks kept only for the
+// snapshot are deleted from the DataNodes. Only the owner of the directory
+// may delete its snapshots.
+func (fs *FileSystemService) DeleteSnapshot(user, dirPath, name string) error {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	dir, err := fs.snapshotOwnerDirectory(user, dirPath)
+	if err != nil {
+		return err
+	}
+	snapshot, err := utils.DeleteSnapshot(dir, name)
+	if err != nil {
+		return err
+	}
+	if err := persistence.RecordDeleteSnapshot(filepath.Clean(dirPath), name); err != nil {
+		dir.Snapshots[name] = snapshot
+		return err
+	}
+	fs.releaseBlocks(snapshot)
+
+	return nil
+}
+
+// RenameSnapshot renames a snapshot of a directory. Only the owner of the
+// directory may rename its snapshots.
+func (fs *FileSystemService) RenameSnapshot(user, dirPath, oldName, newName string) error {
+	fs.rootMutex.Lock()
+	defer fs.rootMutex.Unlock()
+
+	dir, err := fs.snapshotOwnerDirectory(user, dirPath)
+	if err != nil {
+		return err
+	}
+	if err := utils.RenameSnapshot(dir, oldName, newName); err != nil {
+		return err
+	}
+	if err := persistence.RecordRenameSnapshot(filepath.Clean(dirPath), oldName, newName); err != nil {
+		utils.RenameSnapshot(dir, newName, oldName)
+		return err
+	}
+
+	return nil
+}
+
+// SnapshotDiff reports the changes to a snapshottable directory from the
+// snapshot from to the snapshot to. An empty name, or ".", stands for the
+// current state of the directory. The user must be able to list the directory.
+func (fs *FileSystemService) SnapshotDiff(user, dirPath, from, to string) ([]utils.DiffEntry, error) {
+	fs.rootMutex.RLock()
+	defer fs.rootMutex.RUnlock()
+
+	checker := fs.permissionChecker(user)
+	if err := checker.checkTraverse(dirPath); err != nil {
+		return nil, err
+	}
+	dir := utils.FindDirectory(fs.rootDirectory, dirPath)
+	if dir == nil || utils.IsSnapshotPath(dirPath) {
+		return nil, fmt.Errorf("directory %w", utils.ErrNotFound)
+	}
+	if !dir.Snapshottable {
+		return nil, fmt.Errorf("directory is not snapshottable")
+	}
+	if err := checker.check(dirPath, dir.Inode, utils.ActionRead|utils.ActionExecute); err != nil {
+		return nil, err
+	}
+
+	tree := func(name string) (*utils.Directory, error) {
+		if name == "" || name == "." {
+			return dir, nil
+		}
+		snapshot, exists := dir.Snapshots[name]
+		if !exists {
+			return nil, fmt.Errorf("snapshot %s %w", name, utils.ErrNotFound)
+		}
+		return snapshot.Root, nil
+	}
+	fromTree, err := tree(from)
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := tree(to)
+	if err != nil {
+		return nil, err
+	}
+	return utils.SnapshotDiff(fromTree, toTree), nil
+}
+
+// listSnapshots returns the roots of the snapshots of the directory whose
+// .snapshot directory is at path, oldest first. Must be called with
+// fs.rootMutex held.
+func (fs *FileSystemService) listSnapshots(checker *permissionChecker, path string) ([]*utils.Inode, error) {
+	dirPath := filepath.Dir(filepath.Clean(path))
+	dir := utils.FindDirectory(fs.rootDirectory, dirPath)
+	if dir == nil || !dir.Snapshottable {
+		return nil, fmt.Errorf("directory is not snapshottable: %s", dirPath)
+	}
+	if err := checker.check(dirPath, dir.Inode, utils.ActionRead|utils.ActionExecute); err != nil {
+		return nil, err
+	}
+
+	roots := make([]*utils.Inode, 0, len(dir.Snapshots))
+	for _, snapshot := range dir.Snapshots {
+		roots = append(roots, snapshot.Root.Inode)
+	}
+	sort.Slice(roots, func(i, j int) bool { return roots[i].Timestamp.Before(roots[j].Timestamp) })
+	return roots, nil
+}
+
+// checkWritable rejects changes to paths inside snapshots, and to entries
+// named after the reserved .snapshot directory
+func checkWritable(paths ...string) error {
+	for _, path := range paths {
+		if utils.IsSnapshotPath(path) {
+			return fmt.Errorf("%w: %s", utils.ErrSnapshotReadOnly, path)
+		}
+	}
+	return nil
+}
+
+// snapshotAdminDirectory returns the directory at dirPath for the superuser to
+// allow or disallow snapshots of. Must be called with fs.rootMutex held.
+func (fs *FileSystemService) snapshotAdminDirectory(user, dirPath string) (*utils.Directory, error) {
+	if !fs.permissionChecker(user).superuser {
+		return nil, fmt.Errorf("%w: only the superuser may allow or disallow snapshots", utils.ErrPermissionDenied)
+	}
+	if err := checkWritable(dirPath); err != nil {
+		return nil, err
+	}
+	dir := utils.MutableDirectory(fs.rootDirectory, dirPath)
+	if dir == nil {
+		return nil, fmt.Errorf("directory %w", utils.ErrNotFound)
+	}
+	return dir, nil
+}
+
+// snapshotOwnerDirectory returns the directory at dirPath for its owner to
+// change the snapshots of. Must be called with fs.rootMutex held.
+func (fs *FileSystemService) snapshotOwnerDirectory(user, dirPath string) (*utils.Directory, error) {
+	if err := checkWritable(dirPath); err != nil {
+		return nil, err
+	}
+	checker := fs.permissionChecker(user)
+	if err := checker.checkTraverse(dirPath); err != nil {
+		return nil, err
+	}
+	dir := utils.MutableDirectory(fs.rootDirectory, dirPath)
+	if dir == nil {
+		return nil, fmt.Errorf("directory %w", utils.ErrNotFound)
+	}
+	if err := checker.checkOwner(dirPath, dir.Inode); err != nil {
+		return nil, err
+	}
+	return dir, nil
+}
+
+func hasSnapshottableDescendant(dir *utils.Directory) bool {
+	for _, child := range dir.ChildDirs {
+		if child.Snapshottable || hasSnapshottableDescendant(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// referenceBlocks counts the blocks of a new snapshot as referenced, so they
+// outlive the live files sharing them. Must be called with fs.rootMutex held.
+func (fs *FileSystemService) referenceBlocks(snapshot *utils.Snapshot) {
+	utils.WalkBlocks(snapshot.Root, func(block utils.BlockAssignment) {
+		fs.snapshotBlocks[block.BlockID]++
+	})
+}
+
+// releaseBlocks drops the references of a deleted snapshot to its blocks, and
+// deletes those no longer referenced by any snapshot or live file. Must be
+// called with fs.rootMutex held.
+func (fs *FileSystemService) releaseBlocks(snapshot *utils.Snapshot) {
+	var released []utils.BlockAssignment
+	utils.WalkBlocks(snapshot.Root, func(block utils.BlockAssignment) {
+		fs.snapshotBlocks[block.BlockID]--
+		if fs.snapshotBlocks[block.BlockID] > 0 {
+			return
+		}
+		delete(fs.snapshotBlocks, block.BlockID)
+		if retained, exists := fs.retainedBlocks[block.BlockID]; exists {
+			delete(fs.retainedBlocks, block.BlockID)
+			released = append(released, retained)
+		}
+	})
+	fs.deleteBlocks(released)
+}
+
+// loadSnapshotBlocks counts the references of the snapshots under root to
+// their blocks, and registers the blocks only snapshots still reference so
+// block reports don't take them for orphans
+func (fs *FileSystemService) loadSnapshotBlocks(root *utils.Directory) {
+	live := make(map[string]bool)
+	utils.WalkBlocks(root, func(block utils.BlockAssignment) {
+		live[block.BlockID] = true
+	})
+	utils.WalkSnapshots(root, func(snapshot *utils.Snapshot) {
+		fs.referenceBlocks(snapshot)
+		utils.WalkFiles(snapshot.Root, func(inode *utils.Inode) {
+			for _, block := range inode.Blocks {
+				if !live[block.BlockID] {
+					fs.retainedBlocks[block.BlockID] = block
+					gRPC.GetBlockMap().AddBlock(block.BlockID, fs.replication(inode))
+				}
+			}
+		})
+	})
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/config"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/service"
+)
+
+func mustParseAclSpec(t *testing.T, spec string, withPermission bool) []fs.AclEntry {
+	entries, err := fs.ParseAclSpec(spec, withPermission)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func TestAclNamedEntries(t *testing.T) {
+	groups := service.StaticGroupMapping{"carol": {"analysts"}}
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+	service.SetGroupMapping(groups)
+
+	_, err := service.CreateDirectory(superuser, "/acl-data")
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetOwner(superuser, "/acl-data", "alice", ""))
+	assert.NoError(t, service.SetPermission("alice", "/acl-data", 0750))
+	_, err = service.ReadDirectory("bob", "/acl-data")
+	assert.ErrorIs(t, err, fs.ErrPermissionDenied)
+
+	// Only the owner changes the ACL
+	spec := mustParseAclSpec(t, "user:bob:r-x,group:analysts:rwx", true)
+	assert.ErrorIs(t, service.ModifyAclEntries("bob", "/acl-data", spec), fs.ErrPermissionDenied)
+	assert.NoError(t, service.ModifyAclEntries("alice", "/acl-data", spec))
+	_, err = service.ReadDirectory("bob", "/acl-data")
+	assert.NoError(t, err)
+	_, err = service.CreateDirectory("carol", "/acl-data/carol")
+	assert.NoError(t, err)
+
+	// The group bits now hold the mask, calculated from the entries it limits
+	status, err := service.GetAclStatus("bob", "/acl-data")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.Permission(0770), status.Permission)
+	assert.Equal(t, []string{
+		"user::rwx", "user:bob:r-x", "group::r-x", "group:analysts:rwx", "mask::rwx", "other::---",
+	}, aclStrings(status.Entries))
+
+	// The mask limits named entries, and chmod changes the mask
+	assert.NoError(t, service.SetPermission("alice", "/acl-data", 0740))
+	_, err = service.ReadDirectory("bob", "/acl-data")
+	assert.ErrorIs(t, err, fs.ErrPermissionDenied)
+	assert.NoError(t, service.SetPermission("alice", "/acl-data", 0750))
+
+	// The mask limits named groups too
+	_, err = service.CreateDirectory("carol", "/acl-data/carol-2")
+	assert.ErrorIs(t, err, fs.ErrPermissionDenied)
+
+	assert.NoError(t, service.RemoveAclEntries("alice", "/acl-data", mustParseAclSpec(t, "user:bob", false)))
+	_, err = service.ReadDirectory("bob", "/acl-data")
+	assert.ErrorIs(t, err, fs.ErrPermissionDenied)
+	assert.Error(t, service.RemoveAclEntries("alice", "/acl-data", mustParseAclSpec(t, "user:", false)))
+
+	// Removing the ACL restores the group bits from the group entry
+	assert.NoError(t, service.RemoveAcl("alice", "/acl-data"))
+	status, err = service.GetAclStatus("alice", "/acl-data")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.Permission(0750), status.Permission)
+	assert.Equal(t, []string{"user::rwx", "group::r-x", "other::---"}, aclStrings(status.Entries))
+}
+
+func TestDefaultAclInheritance(t *testing.T) {
+	groups := service.StaticGroupMapping{"carol": {"analysts"}}
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+	service.SetGroupMapping(groups)
+
+	_, err := service.CreateDirectory(superuser, "/acl-shared")
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetAcl(superuser, "/acl-shared",
+		mustParseAclSpec(t, "other::---,group:analysts:rwx,default:group:analysts:rwx,default:other::---", true)))
+
+	// New files take the default ACL, limited by their mode instead of the umask
+	_, err = service.CreateFile(superuser, "/acl-shared/report.csv", 0, 0, "")
+	assert.NoError(t, err)
+	_, err = service.CompleteFile(superuser, "/acl-shared/report.csv", nil)
+	assert.NoError(t, err)
+	status, err := service.GetAclStatus(superuser, "/acl-shared/report.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.Permission(0660), status.Permission)
+	assert.Equal(t, []string{
+		"user::rw-", "group::r-x", "group:analysts:rwx", "mask::rw-", "other::---",
+	}, aclStrings(status.Entries))
+	_, err = service.OpenFile("carol", "/acl-shared/report.csv")
+	assert.NoError(t, err)
+	_, err = service.OpenFile("bob", "/acl-shared/report.csv")
+	assert.ErrorIs(t, err, fs.ErrPermissionDenied)
+
+	// New directories also inherit the default ACL itself
+	_, err = service.CreateDirectory("carol", "/acl-shared/2024")
+	assert.NoError(t, err)
+	_, err = service.CreateDirectory("carol", "/acl-shared/2024/q1")
+	assert.NoError(t, err)
+	status, err = service.GetAclStatus("carol", "/acl-shared/2024/q1")
+	assert.NoError(t, err)
+	assert.Contains(t, aclStrings(status.Entries), "default:group:analysts:rwx")
+
+	// Once the default ACL is removed the umask applies again
+	assert.NoError(t, service.RemoveDefaultAcl(superuser, "/acl-shared"))
+	inode, err := service.CreateDirectory(superuser, "/acl-shared/plain")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.Permission(0755), inode.Permission)
+	assert.Empty(t, inode.Acl)
+
+	// Files have no default ACL
+	assert.Error(t, service.ModifyAclEntries(superuser, "/acl-shared/report.csv",
+		mustParseAclSpec(t, "default:user:bob:r--", true)))
+}
+
+func TestParseAclSpec(t *testing.T) {
+	entries := mustParseAclSpec(t, "user:bob:rw-, default:group:analysts:r-x,mask::r--,other:---", true)
+	assert.Equal(t, []fs.AclEntry{
+		{Scope: fs.AclScopeAccess, Type: fs.AclUser, Name: "bob", Permission: fs.ActionRead | fs.ActionWrite},
+		{Scope: fs.AclScopeDefault, Type: fs.AclGroup, Name: "analysts", Permission: fs.ActionRead | fs.ActionExecute},
+		{Scope: fs.AclScopeAccess, Type: fs.AclMask, Permission: fs.ActionRead},
+		{Scope: fs.AclScopeAccess, Type: fs.AclOther, Permission: fs.ActionNone},
+	}, entries)
+
+	entries = mustParseAclSpec(t, "user:bob,default:mask", false)
+	assert.Equal(t, "user:bob:---", entries[0].String())
+	assert.Equal(t, "default:mask::---", entries[1].String())
+
+	for _, spec := range []string{"user:bob", "owner:bob:rwx", "mask:x:rwx", "user:bob:rwz"} {
+		_, err := fs.ParseAclSpec(spec, true)
+		assert.Error(t, err, spec)
+	}
+}
+
+func aclStrings(entries []fs.AclEntry) []string {
+	var strings []string
+	for _, entry := range entries {
+		strings = append(strings, entry.String())
+	}
+	return strings
+}
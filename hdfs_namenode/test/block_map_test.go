@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+func TestBlockMapReports(t *testing.T) {
+	blockMap := gRPC.NewBlockMap()
+	blockMap.AddBlock("1-block-0", 2)
+	blockMap.AddBlock("1-block-1", 2)
+
+	orphans := blockMap.ProcessBlockReport("10.0.0.1:50052", []*protobuf.ReportedBlock{
+		{BlockId: "1-block-0", Size: 10},
+		{BlockId: "1-block-1", Size: 10},
+		{BlockId: "9-block-0", Size: 10},
+	})
+	assert.Equal(t, []string{"9-block-0"}, orphans)
+	assert.Equal(t, []string{"10.0.0.1:50052"}, blockMap.Locations("1-block-0"))
+
+	orphans = blockMap.ProcessIncrementalReport("10.0.0.2:50052",
+		[]*protobuf.ReportedBlock{{BlockId: "1-block-0", Size: 10}}, nil)
+	assert.Empty(t, orphans)
+	assert.Equal(t, []string{"10.0.0.1:50052", "10.0.0.2:50052"}, blockMap.Locations("1-block-0"))
+
+	// A full report replaces what was known of the DataNode
+	blockMap.ProcessBlockReport("10.0.0.1:50052", []*protobuf.ReportedBlock{{BlockId: "1-block-1", Size: 10}})
+	assert.Equal(t, []string{"10.0.0.2:50052"}, blockMap.Locations("1-block-0"))
+
+	blockMap.ProcessIncrementalReport("10.0.0.2:50052", nil, []string{"1-block-0"})
+	assert.Empty(t, blockMap.Locations("1-block-0"))
+
+	blockMap.RemoveDataNode("10.0.0.1:50052")
+	assert.Empty(t, blockMap.Locations("1-block-1"))
+
+	assert.Empty(t, blockMap.RemoveBlock("1-block-1"))
+	assert.Nil(t, blockMap.GetBlock("1-block-1"))
+}
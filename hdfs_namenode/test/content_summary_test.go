@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/config"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/service"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+func TestGetContentSummary(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	cfg := config.DefaultConfig()
+	// Give up the lock after every inode, so the walk yields as often as it can
+	cfg.ContentSummaryLimit = 1
+	service := service.NewFileSystemService(rootDir, cfg)
+
+	_, err := service.CreateDirectory(superuser, "/du")
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		dirPath := fmt.Sprintf("/du/part-%d", i)
+		_, err = service.CreateDirectory(superuser, dirPath)
+		assert.NoError(t, err)
+		for j := 0; j < 2; j++ {
+			filePath := fmt.Sprintf("%s/file-%d", dirPath, j)
+			_, err = service.CreateFile(superuser, filePath, 0, 0, "")
+			assert.NoError(t, err)
+			_, err = service.CompleteFile(superuser, filePath, nil)
+			assert.NoError(t, err)
+		}
+	}
+	assert.NoError(t, service.SetQuota(superuser, "/du", 100, 0))
+
+	summary, err := service.GetContentSummary(superuser, "/du")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.ContentSummary{
+		FileCount:         6,
+		DirectoryCount:    4,
+		NamespaceConsumed: 10,
+		NamespaceQuota:    100,
+	}, *summary)
+
+	summary, err = service.GetContentSummary(superuser, "/du/part-0/file-0")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), summary.FileCount)
+
+	// Every directory of the subtree must be listable
+	assert.NoError(t, service.SetPermission(superuser, "/du/part-2", 0700))
+	_, err = service.GetContentSummary("alice", "/du")
+	assert.ErrorIs(t, err, fs.ErrPermissionDenied)
+	_, err = service.GetContentSummary(superuser, "/du/missing")
+	assert.Error(t, err)
+
+	// The summary is served over gRPC too
+	server := gRPC.NewNameNodeServer(service)
+	response, err := server.GetContentSummary(context.Background(), &protobuf.ContentSummaryRequest{Path: "/du/part-1", User: "alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), response.GetFileCount())
+	assert.Equal(t, int64(1), response.GetDirectoryCount())
+}
+
+func TestGetContentSummaryDuringWrites(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	cfg := config.DefaultConfig()
+	cfg.ContentSummaryLimit = 1
+	service := service.NewFileSystemService(rootDir, cfg)
+
+	_, err := service.CreateDirectory(superuser, "/du-busy")
+	assert.NoError(t, err)
+	for i := 0; i < 50; i++ {
+		_, err := service.CreateDirectory(superuser, fmt.Sprintf("/du-busy/%d", i))
+		assert.NoError(t, err)
+	}
+
+	// Writers run between the chunks of the walk; the summary counts every
+	// directory that existed throughout
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 50; i < 100; i++ {
+			service.CreateDirectory(superuser, fmt.Sprintf("/du-busy/%d", i))
+		}
+	}()
+	summary, err := service.GetContentSummary(superuser, "/du-busy")
+	<-done
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, summary.DirectoryCount, int64(51))
+	assert.LessOrEqual(t, summary.DirectoryCount, int64(101))
+}
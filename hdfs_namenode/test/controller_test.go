@@ -9,31 +9,39 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
-	"github.com/aarrasseayoub01/hdfs-mini/internal/controller"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/controller"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
 )
 
 // Mock the FileSystemService interface
 type MockFileSystemService struct {
 	mock.Mock
+	// Methods not mocked below panic if called
+	controller.FileSystemService
 }
 
-func (m *MockFileSystemService) CreateFile(filePath string) error {
-	args := m.Called(filePath)
-	return args.Error(0)
+func (m *MockFileSystemService) CreateFile(user, filePath string, fileSize int64, replication int, writer string) (*fs.Inode, error) {
+	args := m.Called(user, filePath, fileSize, replication, writer)
+	return args.Get(0).(*fs.Inode), args.Error(1)
 }
 
-func (m *MockFileSystemService) DeleteFile(filePath string) error {
-	args := m.Called(filePath)
+func (m *MockFileSystemService) DeleteFile(user, filePath string) error {
+	args := m.Called(user, filePath)
 	return args.Error(0)
 }
 
-func (m *MockFileSystemService) CreateDirectory(dirPath string) error {
-	args := m.Called(dirPath)
+func (m *MockFileSystemService) CreateDirectory(user, dirPath string) (*fs.Inode, error) {
+	args := m.Called(user, dirPath)
+	return args.Get(0).(*fs.Inode), args.Error(1)
+}
+
+func (m *MockFileSystemService) DeleteDirectory(user, dirPath string, recursive bool) error {
+	args := m.Called(user, dirPath, recursive)
 	return args.Error(0)
 }
 
-func (m *MockFileSystemService) DeleteDirectory(dirPath string) error {
-	args := m.Called(dirPath)
+func (m *MockFileSystemService) Rename(user, srcPath, dstPath string, overwrite bool) error {
+	args := m.Called(user, srcPath, dstPath, overwrite)
 	return args.Error(0)
 }
 
@@ -43,12 +51,12 @@ func TestFileSystemController_CreateFileHandler(t *testing.T) {
 	controller := &controller.FileSystemController{Service: mockService}
 
 	// Prepare a test request
-	requestBody := `{"filePath": "/test.txt"}`
+	requestBody := `{"filePath": "/test.txt", "fileSize": 40}`
 	req := httptest.NewRequest("POST", "/createFile", strings.NewReader(requestBody))
 	w := httptest.NewRecorder()
 
 	// Mock the service method and call the handler
-	mockService.On("CreateFile", "/test.txt").Return(nil)
+	mockService.On("CreateFile", "dr.who", "/test.txt", int64(40), 0, "192.0.2.1").Return(&fs.Inode{Name: "test.txt", Size: 40}, nil)
 	controller.CreateFileHandler(w, req)
 
 	// Check the response status code and service method calls
@@ -67,7 +75,7 @@ func TestFileSystemController_DeleteFileHandler(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// Mock the service method and call the handler
-	mockService.On("DeleteFile", "/test.txt").Return(nil)
+	mockService.On("DeleteFile", "dr.who", "/test.txt").Return(nil)
 	controller.DeleteFileHandler(w, req)
 
 	// Check the response status code and service method calls
@@ -86,7 +94,7 @@ func TestFileSystemController_CreateDirectoryHandler(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// Mock the service method and call the handler
-	mockService.On("CreateDirectory", "/testdir").Return(nil)
+	mockService.On("CreateDirectory", "dr.who", "/testdir").Return(&fs.Inode{Name: "testdir", IsDir: true}, nil)
 	controller.CreateDirectoryHandler(w, req)
 
 	// Check the response status code and service method calls
@@ -100,15 +108,30 @@ func TestFileSystemController_DeleteDirectoryHandler(t *testing.T) {
 	controller := &controller.FileSystemController{Service: mockService}
 
 	// Prepare a test request
-	requestBody := `{"dirPath": "/testdir"}`
+	requestBody := `{"dirPath": "/testdir", "recursive": true}`
 	req := httptest.NewRequest("DELETE", "/deleteDirectory", strings.NewReader(requestBody))
 	w := httptest.NewRecorder()
 
 	// Mock the service method and call the handler
-	mockService.On("DeleteDirectory", "/testdir").Return(nil)
+	mockService.On("DeleteDirectory", "dr.who", "/testdir", true).Return(nil)
 	controller.DeleteDirectoryHandler(w, req)
 
 	// Check the response status code and service method calls
 	assert.Equal(t, http.StatusOK, w.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestFileSystemController_RenameHandler(t *testing.T) {
+	mockService := new(MockFileSystemService)
+	controller := &controller.FileSystemController{Service: mockService}
+
+	requestBody := `{"srcPath": "/tmp/part-0", "dstPath": "/data/part-0", "overwrite": true}`
+	req := httptest.NewRequest("POST", "/rename", strings.NewReader(requestBody))
+	w := httptest.NewRecorder()
+
+	mockService.On("Rename", "dr.who", "/tmp/part-0", "/data/part-0", true).Return(nil)
+	controller.RenameHandler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
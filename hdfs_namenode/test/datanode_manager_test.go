@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+func TestDataNodeLiveness(t *testing.T) {
+	manager := gRPC.NewDataNodeManager()
+	manager.SetTimeouts(time.Minute, 5*time.Minute)
+	events := manager.Subscribe()
+
+	manager.RegisterDataNode("10.0.0.1:50052", "dn-1")
+	event := <-events
+	assert.Equal(t, gRPC.NodeState(""), event.From)
+	assert.Equal(t, gRPC.NodeLive, event.To)
+
+	registeredAt := manager.GetDataNodes()["10.0.0.1:50052"].LastHeartbeat
+
+	// Still live before the stale interval elapses
+	manager.CheckLiveness(registeredAt.Add(30 * time.Second))
+	assert.Len(t, manager.GetLiveDataNodes(), 1)
+
+	// Stale nodes are kept but no longer offered for placement
+	manager.CheckLiveness(registeredAt.Add(2 * time.Minute))
+	assert.Equal(t, gRPC.NodeStale, manager.GetDataNodes()["10.0.0.1:50052"].State)
+	assert.Empty(t, manager.GetLiveDataNodes())
+	assert.Equal(t, gRPC.NodeStale, (<-events).To)
+
+	manager.CheckLiveness(registeredAt.Add(6 * time.Minute))
+	assert.Equal(t, gRPC.NodeDead, manager.GetDataNodes()["10.0.0.1:50052"].State)
+	assert.Equal(t, gRPC.NodeDead, (<-events).To)
+
+	// A heartbeat brings the node back
+	assert.True(t, manager.Heartbeat("10.0.0.1:50052", gRPC.DataNodeStats{}))
+	event = <-events
+	assert.Equal(t, gRPC.NodeDead, event.From)
+	assert.Equal(t, gRPC.NodeLive, event.To)
+	assert.Len(t, manager.GetLiveDataNodes(), 1)
+
+	// Unknown DataNodes are reported to the caller
+	assert.False(t, manager.Heartbeat("10.0.0.9:50052", gRPC.DataNodeStats{}))
+}
+
+func TestSlowSubscriberGetsEveryEvent(t *testing.T) {
+	manager := gRPC.NewDataNodeManager()
+	events := manager.Subscribe()
+
+	// Transitions published while the subscriber isn't reading are queued
+	var addresses []string
+	for i := 0; i < 200; i++ {
+		address := fmt.Sprintf("10.0.%d.%d:50052", i/256, i%256)
+		addresses = append(addresses, address)
+		manager.RegisterDataNode(address, address)
+	}
+	manager.CheckLiveness(time.Now().Add(gRPC.DefaultDeadInterval))
+	for i := 0; i < 2*len(addresses); i++ {
+		select {
+		case event := <-events:
+			if i < len(addresses) {
+				assert.Equal(t, addresses[i], event.Address)
+				assert.Equal(t, gRPC.NodeLive, event.To)
+			} else {
+				assert.Equal(t, gRPC.NodeDead, event.To)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("received %d of %d events", i, 2*len(addresses))
+		}
+	}
+}
+
+func TestDataNodeStatsReport(t *testing.T) {
+	manager := gRPC.NewDataNodeManager()
+	manager.RegisterDataNode("10.0.0.1:50052", "dn-1")
+	manager.RegisterDataNode("10.0.0.2:50052", "dn-2")
+
+	manager.Heartbeat("10.0.0.1:50052", gRPC.DataNodeStats{CapacityBytes: 1000, DfsUsedBytes: 900, RemainingBytes: 100, BlockCount: 3})
+	manager.Heartbeat("10.0.0.2:50052", gRPC.DataNodeStats{CapacityBytes: 1000, DfsUsedBytes: 100, RemainingBytes: 900, BlockCount: 1, ActiveTransfers: 2})
+
+	dataNodes := manager.GetDataNodes()
+	assert.False(t, dataNodes["10.0.0.1:50052"].HasSpaceFor(500))
+	assert.True(t, dataNodes["10.0.0.2:50052"].HasSpaceFor(500))
+
+	report := manager.Report()
+	assert.Equal(t, 2, report.LiveNodes)
+	assert.Equal(t, int64(2000), report.CapacityBytes)
+	assert.Equal(t, int64(1000), report.RemainingBytes)
+	assert.Equal(t, int64(4), report.BlockCount)
+	assert.Equal(t, int64(2), report.ActiveTransfers)
+	assert.Equal(t, "10.0.0.1:50052", report.DataNodes[0].Address)
+}
+
+func TestDataNodeCommands(t *testing.T) {
+	manager := gRPC.NewDataNodeManager()
+	manager.RegisterDataNode("10.0.0.1:50052", "dn-1")
+
+	var acked []*protobuf.CommandAck
+	manager.OnCommandAck(func(address string, command *protobuf.DataNodeCommand, ack *protobuf.CommandAck) {
+		acked = append(acked, ack)
+	})
+
+	id := manager.QueueCommand("10.0.0.1:50052", &protobuf.DataNodeCommand{
+		Type:     protobuf.DataNodeCommand_DELETE_BLOCKS,
+		BlockIds: []string{"1-block-0"},
+	})
+
+	now := time.Now()
+	commands := manager.TakeCommands("10.0.0.1:50052", now)
+	if assert.Len(t, commands, 1) {
+		assert.Equal(t, id, commands[0].GetCommandId())
+	}
+
+	// Unacknowledged commands are only delivered again after a while
+	assert.Empty(t, manager.TakeCommands("10.0.0.1:50052", now.Add(time.Second)))
+	assert.Len(t, manager.TakeCommands("10.0.0.1:50052", now.Add(5*time.Minute)), 1)
+
+	manager.AckCommands("10.0.0.1:50052", []*protobuf.CommandAck{{CommandId: id, Success: true}})
+	assert.Len(t, acked, 1)
+	assert.Equal(t, 0, manager.PendingCommandCount("10.0.0.1:50052"))
+
+	// A repeated ack is ignored
+	manager.AckCommands("10.0.0.1:50052", []*protobuf.CommandAck{{CommandId: id, Success: true}})
+	assert.Len(t, acked, 1)
+
+	// A restarted NameNode doesn't reuse the IDs DataNodes may still be
+	// running commands under
+	restarted := gRPC.NewDataNodeManager()
+	restarted.RegisterDataNode("10.0.0.1:50052", "dn-1")
+	assert.Greater(t, restarted.QueueCommand("10.0.0.1:50052", &protobuf.DataNodeCommand{Type: protobuf.DataNodeCommand_SHUTDOWN}), id)
+}
@@ -0,0 +1,453 @@
+package service
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/config"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/service"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+func TestEditLogRecovery(t *testing.T) {
+	var root *fs.Directory
+	var fileSystem *service.FileSystemService
+	restart := func() {
+		root = persistence.InitializeFileSystem()
+		fileSystem = service.NewFileSystemService(root, config.DefaultConfig())
+	}
+	restart()
+
+	dirs := []string{"/wal", "/wal/a", "/wal/b", "/wal/c"}
+	for _, dir := range dirs {
+		_, err := fileSystem.CreateDirectory(superuser, dir)
+		assert.NoError(t, err)
+	}
+
+	// Changes survive a restart, whether checkpointed or only in the edit log
+	restart()
+	for _, dir := range dirs {
+		assert.NotNil(t, fs.FindDirectory(root, dir), dir)
+	}
+
+	// A record cut short by a crash is truncated on recovery, and the segment
+	// it was written to finalized
+	_, err := fileSystem.CreateDirectory(superuser, "/wal/torn")
+	assert.NoError(t, err)
+	inProgress, err := filepath.Glob("edits_inprogress_*")
+	assert.NoError(t, err)
+	if !assert.Len(t, inProgress, 1) {
+		return
+	}
+	info, err := os.Stat(inProgress[0])
+	assert.NoError(t, err)
+	file, err := os.OpenFile(inProgress[0], os.O_WRONLY|os.O_APPEND, 0644)
+	assert.NoError(t, err)
+	_, err = file.Write([]byte{0, 0, 0, 100, 1, 2, 3, 4, '{', '"'})
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+
+	restart()
+	for _, dir := range append(dirs, "/wal/torn") {
+		assert.NotNil(t, fs.FindDirectory(root, dir), dir)
+	}
+	finalized, err := filepath.Glob(strings.Replace(inProgress[0], "inprogress_", "", 1) + "-*")
+	assert.NoError(t, err)
+	if assert.Len(t, finalized, 1) {
+		recovered, err := os.Stat(finalized[0])
+		assert.NoError(t, err)
+		assert.Equal(t, info.Size(), recovered.Size())
+	}
+
+	// The edit log keeps being appended to after recovery
+	_, err = fileSystem.CreateDirectory(superuser, "/wal/d")
+	assert.NoError(t, err)
+	restart()
+	assert.NotNil(t, fs.FindDirectory(root, "/wal/d"))
+}
+
+func TestEditLogCorruption(t *testing.T) {
+	// Run in a child process, as a corrupt edit log stops the NameNode
+	if dir := os.Getenv("EDIT_LOG_CORRUPTION_DIR"); dir != "" {
+		assert.NoError(t, os.Chdir(dir))
+		persistence.InitializeFileSystem()
+		return
+	}
+
+	root := persistence.InitializeFileSystem()
+	fileSystem := service.NewFileSystemService(root, config.DefaultConfig())
+	for _, dir := range []string{"/corrupt-wal", "/corrupt-wal/a", "/corrupt-wal/b"} {
+		_, err := fileSystem.CreateDirectory(superuser, dir)
+		assert.NoError(t, err)
+	}
+
+	// Copy the namespace, with a bit flipped in the first record of the
+	// in-progress segment: the records after it can't be dropped as a torn
+	// tail
+	dir := t.TempDir()
+	entries, err := os.ReadDir(".")
+	assert.NoError(t, err)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(entry.Name())
+		assert.NoError(t, err)
+		if strings.HasPrefix(entry.Name(), "edits_inprogress_") {
+			data[8+8+8+2] ^= 1
+		}
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, entry.Name()), data, 0644))
+	}
+
+	command := exec.Command(os.Args[0], "-test.run=^TestEditLogCorruption$")
+	command.Env = append(os.Environ(), "EDIT_LOG_CORRUPTION_DIR="+dir)
+	output, err := command.CombinedOutput()
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Contains(t, string(output), "valid records follow")
+}
+
+func TestEditLogSegments(t *testing.T) {
+	// Every transaction gets its own segment
+	persistence.SetEditLogPolicy(1, time.Hour, 2)
+	defer persistence.SetEditLogPolicy(1<<20, 10*time.Minute, 2)
+
+	root := persistence.InitializeFileSystem()
+	fileSystem := service.NewFileSystemService(root, config.DefaultConfig())
+	for i := 0; i < 10; i++ {
+		_, err := fileSystem.CreateDirectory(superuser, "/segments-"+strconv.Itoa(i))
+		assert.NoError(t, err)
+		if i%3 == 2 {
+			_, err = persistence.SaveNamespace()
+			assert.NoError(t, err)
+		}
+	}
+
+	// Only the two most recent checkpoints are kept, with the segments
+	// needed to replay the older one
+	images := fsImages(t)
+	if !assert.Len(t, images, 2) {
+		return
+	}
+	oldest, err := strconv.ParseInt(strings.TrimPrefix(images[0], "fsimage_"), 10, 64)
+	assert.NoError(t, err)
+	segments, err := filepath.Glob("edits_0*")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, segments)
+	for _, segment := range segments {
+		first, last, _ := strings.Cut(strings.TrimPrefix(segment, "edits_"), "-")
+		assert.Equal(t, first, last, segment)
+		txID, err := strconv.ParseInt(last, 10, 64)
+		assert.NoError(t, err)
+		assert.Greater(t, txID, oldest, segment)
+	}
+
+	root = persistence.InitializeFileSystem()
+	for i := 0; i < 10; i++ {
+		assert.NotNil(t, fs.FindDirectory(root, "/segments-"+strconv.Itoa(i)))
+	}
+}
+
+func TestEditLogWriteFailure(t *testing.T) {
+	workDir, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	persistence.SetEditLogPolicy(1, time.Hour, 2)
+	defer func() {
+		persistence.SetEditLogPolicy(1<<20, 10*time.Minute, 2)
+		assert.NoError(t, os.Chdir(workDir))
+		persistence.InitializeFileSystem()
+	}()
+
+	root := persistence.InitializeFileSystem()
+	fileSystem := service.NewFileSystemService(root, config.DefaultConfig())
+	// The segment the first transaction rolls over to can't be created
+	assert.NoError(t, os.Mkdir("edits_inprogress_0000000000000000002", 0755))
+	_, err = fileSystem.CreateDirectory(superuser, "/logged")
+	assert.NoError(t, err)
+
+	// A change that can't be logged fails and is not made
+	_, err = fileSystem.CreateDirectory(superuser, "/unlogged")
+	assert.Error(t, err)
+	assert.Nil(t, fs.FindDirectory(root, "/unlogged"))
+	assert.Error(t, fileSystem.DeleteDirectory(superuser, "/logged", false))
+	assert.NotNil(t, fs.FindDirectory(root, "/logged"))
+
+	// Once the edit log can be written again, so can the namespace
+	assert.NoError(t, os.Remove("edits_inprogress_0000000000000000002"))
+	_, err = fileSystem.CreateDirectory(superuser, "/later")
+	assert.NoError(t, err)
+	root = persistence.InitializeFileSystem()
+	assert.NotNil(t, fs.FindDirectory(root, "/logged"))
+	assert.NotNil(t, fs.FindDirectory(root, "/later"))
+	assert.Nil(t, fs.FindDirectory(root, "/unlogged"))
+}
+
+func TestFsImageFallback(t *testing.T) {
+	root := persistence.InitializeFileSystem()
+	fileSystem := service.NewFileSystemService(root, config.DefaultConfig())
+	for i := 0; i < 7; i++ {
+		_, err := fileSystem.CreateDirectory(superuser, "/fallback-"+strconv.Itoa(i))
+		assert.NoError(t, err)
+		if i%3 == 2 {
+			_, err = persistence.SaveNamespace()
+			assert.NoError(t, err)
+		}
+	}
+
+	// Every checkpoint comes with its digest
+	images := fsImages(t)
+	if !assert.Len(t, images, 2) {
+		return
+	}
+	for _, image := range images {
+		assert.FileExists(t, image+".md5")
+	}
+
+	// A corrupt checkpoint is set aside, and the one before it replayed
+	// with the edits since
+	newest := images[len(images)-1]
+	data, err := os.ReadFile(newest)
+	assert.NoError(t, err)
+	data[len(data)/2] ^= 0xff
+	assert.NoError(t, os.WriteFile(newest, data, 0644))
+
+	root = persistence.InitializeFileSystem()
+	for i := 0; i < 7; i++ {
+		assert.NotNil(t, fs.FindDirectory(root, "/fallback-"+strconv.Itoa(i)))
+	}
+	assert.FileExists(t, newest+".corrupt")
+	assert.NoFileExists(t, newest)
+}
+
+func TestFsImageLayout(t *testing.T) {
+	dataNodeManager := gRPC.GetInstance()
+	dataNodeManager.RegisterDataNode("10.0.11.1:50052", "dn-11")
+
+	root := persistence.InitializeFileSystem()
+	fileSystem := service.NewFileSystemService(root, config.DefaultConfig())
+	fileSystem.SetPlacementPolicy(pinnedPlacement{targets: []string{"10.0.11.1:50052"}})
+	_, err := fileSystem.CreateDirectory(superuser, "/layout")
+	assert.NoError(t, err)
+	inode, err := fileSystem.CreateFile(superuser, "/layout/part-0", 1024, 1, "")
+	assert.NoError(t, err)
+	blocks := []fs.BlockAssignment{{BlockID: inode.Blocks[0].BlockID, DataNodeAddresses: []string{"10.0.11.1:50052"}}}
+	_, err = fileSystem.CompleteFile(superuser, "/layout/part-0", blocks)
+	assert.NoError(t, err)
+	assert.NoError(t, fileSystem.SetAcl(superuser, "/layout", mustParseAclSpec(t, "user::rwx,group::r-x,other::---,user:bob:r-x", true)))
+	assert.NoError(t, fileSystem.SetQuota(superuser, "/layout", 10, 0))
+	assert.NoError(t, fileSystem.AllowSnapshot(superuser, "/layout"))
+	_, err = fileSystem.CreateSnapshot(superuser, "/layout", "s1")
+	assert.NoError(t, err)
+	assert.NoError(t, fileSystem.DeleteFile(superuser, "/layout/part-0"))
+	for i := 0; i < 3; i++ {
+		_, err = fileSystem.CreateDirectory(superuser, "/layout/dir-"+strconv.Itoa(i))
+		assert.NoError(t, err)
+	}
+	_, err = persistence.SaveNamespace()
+	assert.NoError(t, err)
+
+	images := fsImages(t)
+	if !assert.NotEmpty(t, images) {
+		return
+	}
+	data, err := os.ReadFile(images[len(images)-1])
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "HDFSIMG1"))
+
+	restored := persistence.InitializeFileSystem()
+	dir := fs.FindDirectory(restored, "/layout")
+	if !assert.NotNil(t, dir) {
+		return
+	}
+	original := fs.FindDirectory(root, "/layout")
+	assert.Equal(t, original.Inode.ID, dir.Inode.ID)
+	assert.Equal(t, original.Inode.Acl, dir.Inode.Acl)
+	assert.Equal(t, original.Inode.Permission, dir.Inode.Permission)
+	assert.True(t, original.Inode.Timestamp.Equal(dir.Inode.Timestamp))
+	if assert.NotNil(t, dir.Quota) {
+		assert.Equal(t, int64(10), dir.Quota.NamespaceQuota)
+	}
+	assert.True(t, dir.Snapshottable)
+	assert.Len(t, dir.ChildDirs, 3)
+	assert.Empty(t, dir.ChildFiles)
+	if assert.Contains(t, dir.Snapshots, "s1") {
+		snapshotted := dir.Snapshots["s1"].Root.ChildFiles["part-0"]
+		if assert.NotNil(t, snapshotted) {
+			assert.Equal(t, blocks, snapshotted.Blocks)
+			assert.Equal(t, int64(1024), snapshotted.Size)
+		}
+	}
+}
+
+func TestFsImageUnknownSection(t *testing.T) {
+	workDir, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	defer func() {
+		// Back to the edit log of the other tests
+		assert.NoError(t, os.Chdir(workDir))
+		persistence.InitializeFileSystem()
+	}()
+
+	root := persistence.InitializeFileSystem()
+	fileSystem := service.NewFileSystemService(root, config.DefaultConfig())
+	_, err = fileSystem.CreateDirectory(superuser, "/future")
+	assert.NoError(t, err)
+	_, err = persistence.SaveNamespace()
+	assert.NoError(t, err)
+	images := fsImages(t)
+	if !assert.NotEmpty(t, images) {
+		return
+	}
+	path := images[len(images)-1]
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	// A newer NameNode adds a section whose content is no string table
+	data = data[len("HDFSIMG1"):]
+	length, n := binary.Uvarint(data)
+	header := &protobuf.FsImageHeader{}
+	assert.NoError(t, proto.Unmarshal(data[n:n+int(length)], header))
+	header.Sections = append([]string{"future"}, header.Sections...)
+	encoded, err := proto.Marshal(header)
+	assert.NoError(t, err)
+	image := append([]byte("HDFSIMG1"), binary.AppendUvarint(nil, uint64(len(encoded)))...)
+	image = append(image, encoded...)
+	future := []byte{0x0a, 0xff, 0xff, 0x03}
+	image = append(image, binary.AppendUvarint(nil, uint64(len(future)))...)
+	image = append(image, future...)
+	image = append(image, data[n+int(length):]...)
+	assert.NoError(t, os.WriteFile(path, image, 0644))
+	digest := md5.Sum(image)
+	assert.NoError(t, os.WriteFile(path+".md5", []byte(hex.EncodeToString(digest[:])), 0644))
+
+	// The section is skipped rather than the image taken for a corrupt one
+	restored := persistence.InitializeFileSystem()
+	assert.NotNil(t, fs.FindDirectory(restored, "/future"))
+	assert.NoFileExists(t, path+".corrupt")
+}
+
+func TestFsImageUnreadableLayout(t *testing.T) {
+	// Run in a child process, as loading the image stops the NameNode
+	if dir := os.Getenv("FSIMAGE_LAYOUT_DIR"); dir != "" {
+		assert.NoError(t, os.Chdir(dir))
+		persistence.InitializeFileSystem()
+		return
+	}
+
+	tests := []struct {
+		name    string
+		version int32
+		message string
+	}{
+		{"newer layout", 99, "written by a newer NameNode"},
+		{"older layout", 0, "layout version is no longer supported"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			header, err := proto.Marshal(&protobuf.FsImageHeader{LayoutVersion: test.version, LastTxId: 100})
+			assert.NoError(t, err)
+			image := append([]byte("HDFSIMG1"), binary.AppendUvarint(nil, uint64(len(header)))...)
+			path := filepath.Join(dir, "fsimage_0000000000000000100")
+			assert.NoError(t, os.WriteFile(path, append(image, header...), 0644))
+
+			command := exec.Command(os.Args[0], "-test.run=^TestFsImageUnreadableLayout$")
+			command.Env = append(os.Environ(), "FSIMAGE_LAYOUT_DIR="+dir)
+			output, err := command.CombinedOutput()
+			var exitErr *exec.ExitError
+			assert.ErrorAs(t, err, &exitErr)
+			assert.Contains(t, string(output), test.message)
+			// The image is not taken for a corrupt one
+			assert.FileExists(t, path)
+		})
+	}
+}
+
+func TestSaveNamespace(t *testing.T) {
+	root := persistence.InitializeFileSystem()
+	fileSystem := service.NewFileSystemService(root, config.DefaultConfig())
+	_, err := fileSystem.CreateDirectory(superuser, "/save-namespace")
+	assert.NoError(t, err)
+
+	// Only the superuser may save the namespace
+	_, err = fileSystem.SaveNamespace("alice")
+	assert.ErrorIs(t, err, fs.ErrPermissionDenied)
+
+	txID, err := fileSystem.SaveNamespace(superuser)
+	assert.NoError(t, err)
+	images := fsImages(t)
+	if assert.NotEmpty(t, images) {
+		assert.Equal(t, fmt.Sprintf("fsimage_%019d", txID), images[len(images)-1])
+	}
+	_, err = os.Stat(fmt.Sprintf("edits_inprogress_%019d", txID+1))
+	assert.NoError(t, err, "the edit log is rolled at the checkpoint")
+}
+
+func TestBackgroundCheckpoint(t *testing.T) {
+	persistence.SetCheckpointPolicy(5, time.Hour)
+	defer persistence.SetCheckpointPolicy(10000, time.Hour)
+
+	root := persistence.InitializeFileSystem()
+	fileSystem := service.NewFileSystemService(root, config.DefaultConfig())
+	images := fsImages(t)
+	stop := make(chan struct{})
+	persistence.StartCheckpointer(stop)
+
+	// Checkpoints are taken while requests keep changing the namespace
+	var writers sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		writers.Add(1)
+		go func(w int) {
+			defer writers.Done()
+			for i := 0; i < 25; i++ {
+				_, err := fileSystem.CreateDirectory(superuser, fmt.Sprintf("/background-%d-%d", w, i))
+				assert.NoError(t, err)
+			}
+		}(w)
+	}
+	writers.Wait()
+	assert.Eventually(t, func() bool {
+		latest := fsImages(t)
+		return len(latest) > 0 && (len(images) == 0 || latest[len(latest)-1] > images[len(images)-1])
+	}, 5*time.Second, 10*time.Millisecond)
+	close(stop)
+
+	// Whatever the last checkpoint missed is in the edit log
+	root = persistence.InitializeFileSystem()
+	for w := 0; w < 4; w++ {
+		for i := 0; i < 25; i++ {
+			assert.NotNil(t, fs.FindDirectory(root, fmt.Sprintf("/background-%d-%d", w, i)))
+		}
+	}
+}
+
+// fsImages lists the fsimage files of the checkpoints, leaving out their digests
+func fsImages(t *testing.T) []string {
+	names, err := filepath.Glob("fsimage_*")
+	assert.NoError(t, err)
+	var images []string
+	for _, name := range names {
+		if filepath.Ext(name) == "" {
+			images = append(images, name)
+		}
+	}
+	return images
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/crc32"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/config"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/service"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+// fakeDataNode serves data as every block it is asked for, in 4 byte
+// packets. It fails the read after failAfter packets if set, and corrupts
+// the checksum of packets from corruptFrom on if set.
+type fakeDataNode struct {
+	protobuf.UnimplementedDataNodeServiceServer
+	data        []byte
+	failAfter   int
+	corruptFrom int
+	reads       []int64
+}
+
+func (d *fakeDataNode) ReadBlock(req *protobuf.ReadBlockRequest, stream protobuf.DataNodeService_ReadBlockServer) error {
+	d.reads = append(d.reads, req.GetOffset())
+	table := crc32.MakeTable(crc32.Castagnoli)
+	for seqno, offset := int64(0), req.GetOffset(); ; seqno++ {
+		if d.failAfter > 0 && int(seqno) == d.failAfter {
+			return status.Error(codes.Internal, "disk failure")
+		}
+		end := min(offset+4, int64(len(d.data)))
+		packet := &protobuf.BlockPacket{
+			Seqno:      seqno,
+			Offset:     offset,
+			Data:       d.data[offset:end],
+			Checksum:   crc32.Checksum(d.data[offset:end], table),
+			LastPacket: end == int64(len(d.data)),
+		}
+		if d.corruptFrom > 0 && int(seqno) >= d.corruptFrom {
+			packet.Checksum++
+		}
+		if err := stream.Send(&protobuf.ReadBlockResponse{Packet: packet}); err != nil {
+			return err
+		}
+		if packet.LastPacket {
+			return nil
+		}
+		offset = end
+	}
+}
+
+// startFakeDataNode serves dataNode on a local port and returns its address
+func startFakeDataNode(t *testing.T, dataNode *fakeDataNode) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	protobuf.RegisterDataNodeServiceServer(server, dataNode)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func TestReadFileFailover(t *testing.T) {
+	data := []byte("0123456789abcdefghij")
+	failing := &fakeDataNode{data: data, failAfter: 2}
+	corrupt := &fakeDataNode{data: data, corruptFrom: 1}
+	healthy := &fakeDataNode{data: data}
+	pipeline := []string{startFakeDataNode(t, failing), startFakeDataNode(t, corrupt), startFakeDataNode(t, healthy)}
+
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+	dataNodeManager := gRPC.GetInstance()
+	for i, address := range pipeline {
+		dataNodeManager.RegisterDataNode(address, fmt.Sprintf("dn-read-%d", i))
+	}
+	service.SetPlacementPolicy(pinnedPlacement{targets: pipeline})
+
+	inode, err := service.CreateFile(superuser, "/failover.txt", int64(len(data)), 3, "")
+	assert.NoError(t, err)
+	_, err = service.CompleteFile(superuser, "/failover.txt", []fs.BlockAssignment{{BlockID: inode.Blocks[0].BlockID, DataNodeAddresses: pipeline}})
+	assert.NoError(t, err)
+
+	// Each replica that fails hands over to the next at the offset reached,
+	// and packets failing their checksum are never delivered
+	opened, err := service.OpenFile(superuser, "/failover.txt")
+	assert.NoError(t, err)
+	var out bytes.Buffer
+	assert.NoError(t, service.ReadFileData(opened, &out))
+	assert.Equal(t, data, out.Bytes())
+	assert.Equal(t, []int64{0}, failing.reads)
+	assert.Equal(t, []int64{8}, corrupt.reads)
+	assert.Equal(t, []int64{12}, healthy.reads)
+
+	// Without any replica left the read fails
+	healthy.failAfter = 1
+	assert.Error(t, service.ReadFileData(opened, &bytes.Buffer{}))
+}
+
+// readFileStream collects what the NameNode streams to a ReadFile client
+type readFileStream struct {
+	grpc.ServerStream
+	data bytes.Buffer
+}
+
+func (s *readFileStream) Send(response *protobuf.ReadFileResponse) error {
+	s.data.Write(response.GetData())
+	return nil
+}
+
+func TestNameNodeServerErrors(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+	server := gRPC.NewNameNodeServer(service)
+
+	_, err := service.CreateDirectory(superuser, "/grpc-private")
+	assert.NoError(t, err)
+	_, err = service.CreateFile(superuser, "/grpc-private/empty", 0, 0, "")
+	assert.NoError(t, err)
+	_, err = service.CompleteFile(superuser, "/grpc-private/empty", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetPermission(superuser, "/grpc-private", 0700))
+
+	// Missing paths, permission failures and other errors get their own codes
+	err = server.ReadFile(&protobuf.ReadFileRequest{FilePath: "/grpc-missing", User: superuser}, &readFileStream{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	err = server.ReadFile(&protobuf.ReadFileRequest{FilePath: "/grpc-private/empty", User: "alice"}, &readFileStream{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = server.Rename(context.Background(), &protobuf.RenameRequest{SrcPath: "/grpc-missing", DstPath: "/grpc-other", User: superuser})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = server.Rename(context.Background(), &protobuf.RenameRequest{SrcPath: "/grpc-private", DstPath: "/grpc-private/empty/nested", User: superuser})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = server.GetContentSummary(context.Background(), &protobuf.ContentSummaryRequest{Path: "/grpc-missing", User: superuser})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Requests without a user run as dr.who, who may not read the directory
+	_, err = server.GetContentSummary(context.Background(), &protobuf.ContentSummaryRequest{Path: "/grpc-private"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	stream := &readFileStream{}
+	assert.NoError(t, server.ReadFile(&protobuf.ReadFileRequest{FilePath: "/grpc-private/empty", User: superuser}, stream))
+	assert.Empty(t, stream.data.Bytes())
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+func TestInvalidationLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalidations.log")
+	invalidations, err := gRPC.LoadInvalidationLog(path)
+	assert.NoError(t, err)
+
+	manager := gRPC.NewDataNodeManager()
+	manager.SetInvalidationLog(invalidations)
+	manager.RegisterDataNode("10.0.0.1:50052", "dn1")
+	manager.QueueBlockDeletion("10.0.0.1:50052", []string{"1-block-0", "1-block-1"})
+	assert.Equal(t, 2, manager.PendingBlockDeletions())
+
+	// The NameNode restarts before the DataNode deleted the blocks
+	invalidations, err = gRPC.LoadInvalidationLog(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1-block-0", "1-block-1"}, invalidations.Pending("10.0.0.1:50052"))
+
+	manager = gRPC.NewDataNodeManager()
+	manager.SetInvalidationLog(invalidations)
+	manager.RegisterDataNode("10.0.0.1:50052", "dn1")
+	commands := manager.TakeCommands("10.0.0.1:50052", time.Now())
+	if assert.Len(t, commands, 1) {
+		assert.Equal(t, protobuf.DataNodeCommand_DELETE_BLOCKS, commands[0].GetType())
+		assert.Equal(t, []string{"1-block-0", "1-block-1"}, commands[0].GetBlockIds())
+	}
+
+	// A failed deletion stays pending, a successful one is forgotten
+	manager.AckCommands("10.0.0.1:50052", []*protobuf.CommandAck{{CommandId: commands[0].GetCommandId(), Success: false}})
+	assert.Equal(t, 2, manager.PendingBlockDeletions())
+	manager.RegisterDataNode("10.0.0.1:50052", "dn1")
+	commands = manager.TakeCommands("10.0.0.1:50052", time.Now())
+	assert.Len(t, commands, 1)
+
+	// When only some blocks fail, the others are forgotten
+	manager.AckCommands("10.0.0.1:50052", []*protobuf.CommandAck{{CommandId: commands[0].GetCommandId(), Success: false, FailedBlockIds: []string{"1-block-1"}}})
+	assert.Equal(t, []string{"1-block-1"}, invalidations.Pending("10.0.0.1:50052"))
+	manager.RegisterDataNode("10.0.0.1:50052", "dn1")
+	commands = manager.TakeCommands("10.0.0.1:50052", time.Now())
+	if assert.Len(t, commands, 1) {
+		assert.Equal(t, []string{"1-block-1"}, commands[0].GetBlockIds())
+	}
+	manager.AckCommands("10.0.0.1:50052", []*protobuf.CommandAck{{CommandId: commands[0].GetCommandId(), Success: true}})
+	assert.Equal(t, 0, manager.PendingBlockDeletions())
+
+	invalidations, err = gRPC.LoadInvalidationLog(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, invalidations.Count())
+}
+
+func TestInvalidationLogJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalidations.log")
+	invalidations, err := gRPC.LoadInvalidationLog(path)
+	assert.NoError(t, err)
+	assert.NoError(t, invalidations.Add("10.0.0.1:50052", []string{"1-block-0"}))
+
+	// Changes are appended rather than rewriting the log, and each deleted
+	// block is eventually dropped from it
+	for i := 0; i < 2000; i++ {
+		blockID := fmt.Sprintf("2-block-%d", i)
+		assert.NoError(t, invalidations.Add("10.0.0.2:50052", []string{blockID}))
+		assert.NoError(t, invalidations.Remove("10.0.0.2:50052", []string{blockID}))
+	}
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Less(t, info.Size(), int64(100<<10))
+
+	// A record torn by a crash is ignored, a corrupt one in the middle is not
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	assert.NoError(t, err)
+	_, err = file.WriteString(`{"op":"add","datanode":"10.0.0.3:50`)
+	assert.NoError(t, err)
+	assert.NoError(t, file.Close())
+	invalidations, err = gRPC.LoadInvalidationLog(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1-block-0"}, invalidations.Pending("10.0.0.1:50052"))
+	assert.Equal(t, 1, invalidations.Count())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, append([]byte("{\n"), data...), 0644))
+	_, err = gRPC.LoadInvalidationLog(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/config"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/service"
+)
+
+func TestPermissionChecks(t *testing.T) {
+	groups := service.StaticGroupMapping{"alice": {"analysts"}, "carol": {"analysts"}}
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+	service.SetGroupMapping(groups)
+
+	// The superuser hands a home directory over to alice
+	_, err := service.CreateDirectory(superuser, "/perm-alice")
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetOwner(superuser, "/perm-alice", "alice", "analysts"))
+
+	// New files belong to their creator and inherit the group of their parent
+	_, err = service.CreateFile("alice", "/perm-alice/data.txt", 0, 0, "")
+	assert.NoError(t, err)
+	inode, err := service.CompleteFile("alice", "/perm-alice/data.txt", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", inode.Owner)
+	assert.Equal(t, "analysts", inode.Group)
+	assert.Equal(t, fs.Permission(0644), inode.Permission)
+
+	// Others may read, but not write into alice's directory
+	_, err = service.OpenFile("bob", "/perm-alice/data.txt")
+	assert.NoError(t, err)
+	_, err = service.CreateFile("bob", "/perm-alice/other.txt", 0, 0, "")
+	assert.ErrorIs(t, err, fs.ErrPermissionDenied)
+
+	// Only the owner changes the permission
+	assert.ErrorIs(t, service.SetPermission("bob", "/perm-alice/data.txt", 0666), fs.ErrPermissionDenied)
+	assert.NoError(t, service.SetPermission("alice", "/perm-alice/data.txt", 0640))
+	_, err = service.OpenFile("bob", "/perm-alice/data.txt")
+	assert.ErrorIs(t, err, fs.ErrPermissionDenied)
+	_, err = service.OpenFile("carol", "/perm-alice/data.txt")
+	assert.NoError(t, err)
+
+	// Without execute on a directory nothing below it can be looked up
+	assert.NoError(t, service.SetPermission("alice", "/perm-alice", 0700))
+	_, err = service.ReadFile("carol", "/perm-alice/data.txt")
+	assert.ErrorIs(t, err, fs.ErrPermissionDenied)
+	_, err = service.ReadDirectory("carol", "/perm-alice")
+	assert.ErrorIs(t, err, fs.ErrPermissionDenied)
+
+	// The owner changes the group to one they belong to; only the superuser
+	// gives files away
+	assert.ErrorIs(t, service.SetOwner("alice", "/perm-alice/data.txt", "bob", ""), fs.ErrPermissionDenied)
+	assert.ErrorIs(t, service.SetOwner("alice", "/perm-alice/data.txt", "", "admins"), fs.ErrPermissionDenied)
+	assert.NoError(t, service.SetOwner(superuser, "/perm-alice/data.txt", "bob", "admins"))
+	inode, err = service.ReadFile(superuser, "/perm-alice/data.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "bob", inode.Owner)
+	assert.Equal(t, "admins", inode.Group)
+}
+
+func TestStickyBit(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+
+	_, err := service.CreateDirectory(superuser, "/perm-tmp")
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetPermission(superuser, "/perm-tmp", 01777))
+
+	_, err = service.CreateFile("alice", "/perm-tmp/alice.txt", 0, 0, "")
+	assert.NoError(t, err)
+	_, err = service.CompleteFile("alice", "/perm-tmp/alice.txt", nil)
+	assert.NoError(t, err)
+
+	// Everyone may write to the directory, but only alice removes her file
+	assert.ErrorIs(t, service.DeleteFile("bob", "/perm-tmp/alice.txt"), fs.ErrPermissionDenied)
+	assert.ErrorIs(t, service.Rename("bob", "/perm-tmp/alice.txt", "/perm-tmp/bob.txt", false), fs.ErrPermissionDenied)
+	assert.NoError(t, service.DeleteFile("alice", "/perm-tmp/alice.txt"))
+}
+
+func TestPermissionsDisabled(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	cfg := config.DefaultConfig()
+	cfg.PermissionsEnabled = false
+	service := service.NewFileSystemService(rootDir, cfg)
+
+	// Root is owned by the superuser, yet anyone may write to it
+	_, err := service.CreateDirectory("bob", "/perm-disabled")
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetPermission("carol", "/perm-disabled", 0700))
+}
+
+func TestPermissionFormat(t *testing.T) {
+	assert.Equal(t, "rwxr-xr-x", fs.Permission(0755).String())
+	assert.Equal(t, "rwxrwxrwt", fs.Permission(01777).String())
+	assert.Equal(t, "rw-r--r-T", fs.Permission(01644).String())
+	assert.Equal(t, "READ_EXECUTE", (fs.ActionRead | fs.ActionExecute).String())
+
+	permission, err := fs.ParsePermission("750")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.Permission(0750), permission)
+	_, err = fs.ParsePermission("8")
+	assert.Error(t, err)
+	_, err = fs.ParsePermission("2777")
+	assert.Error(t, err)
+
+	data, err := json.Marshal(fs.Permission(0644))
+	assert.NoError(t, err)
+	assert.Equal(t, `"644"`, string(data))
+	assert.NoError(t, json.Unmarshal([]byte(`"1777"`), &permission))
+	assert.Equal(t, fs.Permission(01777), permission)
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+)
+
+func placementDataNodes() map[string]*gRPC.DataNode {
+	dataNodes := map[string]*gRPC.DataNode{
+		"10.0.0.1:50052": {Stats: gRPC.DataNodeStats{CapacityBytes: 1000, RemainingBytes: 100, DfsUsedBytes: 900}},
+		"10.0.0.2:50052": {Stats: gRPC.DataNodeStats{CapacityBytes: 1000, RemainingBytes: 800, DfsUsedBytes: 200, ActiveTransfers: 2}},
+		"10.0.0.3:50052": {Stats: gRPC.DataNodeStats{CapacityBytes: 1000, RemainingBytes: 600, DfsUsedBytes: 400}},
+		"10.0.0.4:50052": {Stats: gRPC.DataNodeStats{CapacityBytes: 1000, RemainingBytes: 10, DfsUsedBytes: 990}},
+		"10.0.0.5:50052": {State: gRPC.NodeStale},
+	}
+	for address, dataNode := range dataNodes {
+		dataNode.Address = address
+		if dataNode.State == "" {
+			dataNode.State = gRPC.NodeLive
+		}
+	}
+	return dataNodes
+}
+
+func TestPlacementPolicies(t *testing.T) {
+	dataNodes := placementDataNodes()
+
+	// The default policy prefers idle DataNodes, then the emptiest ones, and
+	// skips stale and full DataNodes
+	policy, err := gRPC.NewPlacementPolicy(gRPC.DefaultPlacementPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.3:50052", "10.0.0.1:50052", "10.0.0.2:50052"}, policy.ChooseTargets(3, "", 50, dataNodes, nil))
+	assert.Equal(t, []string{"10.0.0.1:50052", "10.0.0.2:50052"}, policy.ChooseTargets(3, "", 50, dataNodes, map[string]bool{"10.0.0.3:50052": true}))
+	assert.Empty(t, policy.ChooseTargets(3, "", 5000, dataNodes, nil))
+
+	// The least-used policy fills the DataNodes storing the least data first
+	policy, err = gRPC.NewPlacementPolicy(gRPC.LeastUsedPlacementPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.2:50052", "10.0.0.3:50052"}, policy.ChooseTargets(2, "", 50, dataNodes, nil))
+
+	// The round-robin policy starts each block on the next DataNode
+	policy, err = gRPC.NewPlacementPolicy(gRPC.RoundRobinPlacementPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1:50052", "10.0.0.2:50052"}, policy.ChooseTargets(2, "", 50, dataNodes, nil))
+	assert.Equal(t, []string{"10.0.0.2:50052", "10.0.0.3:50052"}, policy.ChooseTargets(2, "", 50, dataNodes, nil))
+	assert.Equal(t, []string{"10.0.0.3:50052", "10.0.0.1:50052"}, policy.ChooseTargets(2, "", 50, dataNodes, nil))
+}
+
+func TestChooseExcessReplicas(t *testing.T) {
+	dataNodes := placementDataNodes()
+	replicas := []*gRPC.DataNode{dataNodes["10.0.0.2:50052"], dataNodes["10.0.0.3:50052"], dataNodes["10.0.0.4:50052"]}
+
+	policy, _ := gRPC.NewPlacementPolicy(gRPC.DefaultPlacementPolicy)
+	assert.Equal(t, []string{"10.0.0.4:50052"}, policy.ChooseExcessReplicas(1, replicas))
+
+	policy, _ = gRPC.NewPlacementPolicy(gRPC.LeastUsedPlacementPolicy)
+	assert.Equal(t, []string{"10.0.0.4:50052", "10.0.0.3:50052"}, policy.ChooseExcessReplicas(2, replicas))
+}
+
+type firstTargetPolicy struct{}
+
+func (p *firstTargetPolicy) ChooseTargets(n int, writerRack string, blockSize int64, dataNodes map[string]*gRPC.DataNode, exclude map[string]bool) []string {
+	return []string{"10.0.0.1:50052"}
+}
+
+func (p *firstTargetPolicy) ChooseExcessReplicas(n int, replicas []*gRPC.DataNode) []string {
+	return nil
+}
+
+func TestRegisterPlacementPolicy(t *testing.T) {
+	_, err := gRPC.NewPlacementPolicy("rack-aware-test")
+	assert.Error(t, err)
+
+	gRPC.RegisterPlacementPolicy("rack-aware-test", func() gRPC.BlockPlacementPolicy { return &firstTargetPolicy{} })
+	policy, err := gRPC.NewPlacementPolicy("rack-aware-test")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1:50052"}, policy.ChooseTargets(3, "", 50, nil, nil))
+}
+
+func rackDataNodes() map[string]*gRPC.DataNode {
+	racks := map[string]string{
+		"10.0.1.1:50052": "/rack1",
+		"10.0.1.2:50052": "/rack1",
+		"10.0.1.3:50052": "/rack1",
+		"10.0.2.1:50052": "/rack2",
+		"10.0.2.2:50052": "/rack2",
+	}
+	dataNodes := make(map[string]*gRPC.DataNode, len(racks))
+	for address, rack := range racks {
+		dataNodes[address] = &gRPC.DataNode{Address: address, State: gRPC.NodeLive, Rack: rack}
+	}
+	return dataNodes
+}
+
+func TestRackAwarePlacementPolicy(t *testing.T) {
+	dataNodes := rackDataNodes()
+	policy, err := gRPC.NewPlacementPolicy(gRPC.RackAwarePlacementPolicy)
+	assert.NoError(t, err)
+
+	racksOf := func(targets []string) map[string]int {
+		racks := make(map[string]int)
+		for _, address := range targets {
+			racks[dataNodes[address].Rack]++
+		}
+		return racks
+	}
+
+	// The first replica goes on the writer's rack, the others span two racks
+	targets := policy.ChooseTargets(3, "/rack2", 50, dataNodes, nil)
+	assert.Len(t, targets, 3)
+	assert.Equal(t, "/rack2", dataNodes[targets[0]].Rack)
+	assert.Len(t, racksOf(targets), 2)
+
+	targets = policy.ChooseTargets(2, "/rack1", 50, dataNodes, nil)
+	assert.Equal(t, "/rack1", dataNodes[targets[0]].Rack)
+	assert.Equal(t, "/rack2", dataNodes[targets[1]].Rack)
+
+	// A block whose replicas are all on one rack gets a copy on another rack
+	exclude := map[string]bool{"10.0.1.1:50052": true, "10.0.1.2:50052": true}
+	targets = policy.ChooseTargets(1, "/rack1", 50, dataNodes, exclude)
+	assert.Len(t, targets, 1)
+	assert.Equal(t, "/rack2", dataNodes[targets[0]].Rack)
+
+	// Without another rack, replicas still get placed
+	for _, address := range []string{"10.0.2.1:50052", "10.0.2.2:50052"} {
+		delete(dataNodes, address)
+	}
+	assert.Len(t, policy.ChooseTargets(3, "/rack2", 50, dataNodes, nil), 3)
+}
+
+func TestRackAwareExcessReplicas(t *testing.T) {
+	dataNodes := rackDataNodes()
+	replicas := []*gRPC.DataNode{dataNodes["10.0.1.1:50052"], dataNodes["10.0.1.2:50052"], dataNodes["10.0.2.1:50052"]}
+
+	// The only replica on rack2 is kept
+	policy, _ := gRPC.NewPlacementPolicy(gRPC.RackAwarePlacementPolicy)
+	excess := policy.ChooseExcessReplicas(1, replicas)
+	assert.Len(t, excess, 1)
+	assert.Equal(t, "/rack1", dataNodes[excess[0]].Rack)
+	assert.Len(t, policy.ChooseExcessReplicas(2, replicas), 2)
+}
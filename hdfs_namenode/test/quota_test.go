@@ -0,0 +1,103 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/config"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/service"
+)
+
+func TestNamespaceQuota(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+
+	_, err := service.CreateDirectory(superuser, "/quota-ns")
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetOwner(superuser, "/quota-ns", "alice", ""))
+	assert.ErrorIs(t, service.SetQuota("alice", "/quota-ns", 3, 0), fs.ErrPermissionDenied)
+	assert.Error(t, service.SetQuota(superuser, "/quota-ns", -1, 0))
+
+	// The directory itself counts against its quota
+	assert.NoError(t, service.SetQuota(superuser, "/quota-ns", 3, 0))
+	_, err = service.CreateDirectory("alice", "/quota-ns/a")
+	assert.NoError(t, err)
+	_, err = service.CreateFile("alice", "/quota-ns/a/empty", 0, 0, "")
+	assert.NoError(t, err)
+	_, err = service.CreateDirectory("alice", "/quota-ns/b")
+	assert.ErrorIs(t, err, fs.ErrQuotaExceeded)
+	_, err = service.CompleteFile("alice", "/quota-ns/a/empty", nil)
+	assert.NoError(t, err)
+
+	// Deleting and renaming out release quota, renaming in takes it
+	assert.NoError(t, service.DeleteFile("alice", "/quota-ns/a/empty"))
+	_, err = service.CreateDirectory("alice", "/quota-ns/b")
+	assert.NoError(t, err)
+	_, err = service.CreateDirectory(superuser, "/quota-outside")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, service.Rename(superuser, "/quota-outside", "/quota-ns/a/outside", false), fs.ErrQuotaExceeded)
+	assert.NoError(t, service.Rename(superuser, "/quota-ns/b", "/quota-moved-out", false))
+	assert.NoError(t, service.Rename(superuser, "/quota-outside", "/quota-ns/a/outside", false))
+
+	// Moving within the quota doesn't count twice
+	assert.NoError(t, service.Rename(superuser, "/quota-ns/a/outside", "/quota-ns/outside", false))
+
+	summary, err := service.GetContentSummary("alice", "/quota-ns")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), summary.DirectoryCount)
+	assert.Equal(t, int64(3), summary.NamespaceQuota)
+
+	// Clearing the quota lifts the limit
+	assert.NoError(t, service.SetQuota(superuser, "/quota-ns", 0, 0))
+	_, err = service.CreateDirectory("alice", "/quota-ns/c")
+	assert.NoError(t, err)
+}
+
+func TestSpaceQuota(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+
+	dataNodeManager := gRPC.GetInstance()
+	dataNodeManager.RegisterDataNode("10.0.8.1:50052", "dn-1")
+	dataNodeManager.RegisterDataNode("10.0.8.2:50052", "dn-2")
+
+	_, err := service.CreateDirectory(superuser, "/quota-space")
+	assert.NoError(t, err)
+	_, err = service.CreateDirectory(superuser, "/quota-space/jobs")
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetQuota(superuser, "/quota-space", 0, 1000))
+
+	// Every replica counts, and so do files still being written
+	inode, err := service.CreateFile(superuser, "/quota-space/jobs/part-0", 300, 2, "")
+	assert.NoError(t, err)
+	_, err = service.CreateFile(superuser, "/quota-space/jobs/part-1", 300, 2, "")
+	assert.ErrorIs(t, err, fs.ErrQuotaExceeded)
+	_, err = service.CreateFile(superuser, "/quota-space/jobs/part-1", 300, 1, "")
+	assert.NoError(t, err)
+	assert.NoError(t, service.AbandonFile(superuser, "/quota-space/jobs/part-1"))
+
+	_, err = service.CompleteFile(superuser, "/quota-space/jobs/part-0", inode.Blocks)
+	assert.NoError(t, err)
+	_, err = service.CreateFile(superuser, "/quota-space/jobs/part-1", 300, 2, "")
+	assert.ErrorIs(t, err, fs.ErrQuotaExceeded)
+
+	summary, err := service.GetContentSummary(superuser, "/quota-space")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.ContentSummary{
+		Length:            300,
+		FileCount:         1,
+		DirectoryCount:    2,
+		SpaceConsumed:     600,
+		NamespaceConsumed: 3,
+		SpaceQuota:        1000,
+	}, *summary)
+
+	// Deleting a subtree releases its space
+	assert.NoError(t, service.DeleteDirectory(superuser, "/quota-space/jobs", true))
+	_, err = service.CreateFile(superuser, "/quota-space/part-1", 500, 2, "")
+	assert.NoError(t, err)
+}
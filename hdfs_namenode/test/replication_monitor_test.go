@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+func TestReplicationMonitor(t *testing.T) {
+	manager := gRPC.NewDataNodeManager()
+	blockMap := gRPC.NewBlockMap()
+	monitor := gRPC.NewReplicationMonitor(blockMap, manager)
+
+	for _, address := range []string{"10.0.0.1:50052", "10.0.0.2:50052", "10.0.0.3:50052"} {
+		manager.RegisterDataNode(address, address)
+	}
+
+	// One block lost two of its three replicas, another has one too many
+	blockMap.AddBlock("1-block-0", 3)
+	blockMap.AddBlock("2-block-0", 1)
+	blockMap.ProcessBlockReport("10.0.0.1:50052", []*protobuf.ReportedBlock{{BlockId: "1-block-0"}, {BlockId: "2-block-0"}})
+	blockMap.ProcessBlockReport("10.0.0.2:50052", []*protobuf.ReportedBlock{{BlockId: "2-block-0"}})
+
+	now := time.Now()
+	monitor.Scan(now)
+
+	status := monitor.Status()
+	assert.Equal(t, 1, status.HighestPriority)
+	assert.Equal(t, 1, status.OverReplicated)
+	assert.Equal(t, 1, status.PendingReplications)
+	assert.Equal(t, 1, status.PendingDeletions)
+
+	commands := manager.TakeCommands("10.0.0.1:50052", now)
+	var replicate *protobuf.DataNodeCommand
+	for _, command := range commands {
+		if command.GetType() == protobuf.DataNodeCommand_REPLICATE_BLOCK {
+			replicate = command
+		}
+	}
+	if assert.NotNil(t, replicate) {
+		assert.Equal(t, []string{"1-block-0"}, replicate.GetBlockIds())
+		assert.ElementsMatch(t, []string{"10.0.0.2:50052", "10.0.0.3:50052"}, replicate.GetTargets())
+	}
+
+	// A scan before the copy is reported does not schedule it again
+	monitor.Scan(now.Add(time.Second))
+	assert.Equal(t, int64(1), monitor.Status().ScheduledReplications)
+
+	// A failed copy is scheduled again by the next scan
+	manager.AckCommands("10.0.0.1:50052", []*protobuf.CommandAck{{CommandId: replicate.GetCommandId(), Success: false}})
+	assert.Equal(t, 0, monitor.Status().PendingReplications)
+	monitor.Scan(now.Add(2 * time.Second))
+	assert.Equal(t, int64(2), monitor.Status().ScheduledReplications)
+
+	// Once the new replicas are reported the block is healthy
+	blockMap.ProcessIncrementalReport("10.0.0.2:50052", []*protobuf.ReportedBlock{{BlockId: "1-block-0"}}, nil)
+	blockMap.ProcessIncrementalReport("10.0.0.3:50052", []*protobuf.ReportedBlock{{BlockId: "1-block-0"}}, nil)
+	monitor.Scan(now.Add(3 * time.Second))
+	status = monitor.Status()
+	assert.Equal(t, 0, status.HighestPriority)
+	assert.Equal(t, 0, status.PendingReplications)
+}
+
+func TestExcessReplicasWithStaleDataNode(t *testing.T) {
+	manager := gRPC.NewDataNodeManager()
+	blockMap := gRPC.NewBlockMap()
+	monitor := gRPC.NewReplicationMonitor(blockMap, manager)
+
+	addresses := []string{"10.0.0.1:50052", "10.0.0.2:50052", "10.0.0.3:50052", "10.0.0.4:50052"}
+	blockMap.AddBlock("1-block-0", 3)
+	for _, address := range addresses {
+		manager.RegisterDataNode(address, address)
+		blockMap.ProcessBlockReport(address, []*protobuf.ReportedBlock{{BlockId: "1-block-0"}})
+	}
+	// The last DataNode misses its heartbeats
+	now := time.Now().Add(2 * time.Minute)
+	manager.CheckLiveness(now)
+	for _, address := range addresses[:3] {
+		manager.Heartbeat(address, gRPC.DataNodeStats{})
+	}
+	assert.Equal(t, gRPC.NodeStale, manager.GetDataNodes()["10.0.0.4:50052"].State)
+
+	// Three live replicas and a stale one: nothing is deleted while it is stale
+	monitor.Scan(now)
+	assert.Equal(t, 0, monitor.Status().PendingDeletions)
+	for _, address := range addresses {
+		assert.Empty(t, manager.TakeCommands(address, now), address)
+	}
+
+	// Once it is back the excess replica is deleted
+	manager.Heartbeat("10.0.0.4:50052", gRPC.DataNodeStats{})
+	monitor.Scan(now)
+	assert.Equal(t, 1, monitor.Status().PendingDeletions)
+}
@@ -3,14 +3,38 @@
 package service
 
 import (
+	"os"
 	"testing"
-
-	"github.com/aarrasseayoub01/hdfs-mini/internal/fs"
-	"github.com/aarrasseayoub01/hdfs-mini/internal/persistence"
-	"github.com/aarrasseayoub01/hdfs-mini/internal/service"
+	"time"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/config"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/service"
+	"github.com/aarrasseayoub01/namenode/protobuf"
 	"github.com/stretchr/testify/assert"
 )
 
+// TestMain runs the tests from a scratch directory so the fsimage and edit
+// log written by the persistence layer never leak between runs.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "namenode-test")
+	if err != nil {
+		panic(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// superuser is the default superuser, whom permission checks never deny
+const superuser = "hdfs"
+
 func setupMockFileSystem() *fs.Directory {
 	return &fs.Directory{
 		Inode: &fs.Inode{
@@ -23,16 +47,37 @@ func setupMockFileSystem() *fs.Directory {
 	}
 }
 
+// pinnedPlacement places every block on the given DataNodes, so tests know
+// where blocks are whatever other DataNodes earlier tests registered
+type pinnedPlacement struct {
+	gRPC.BlockPlacementPolicy
+	targets []string
+}
+
+func (p pinnedPlacement) ChooseTargets(n int, _ string, _ int64, _ map[string]*gRPC.DataNode, _ map[string]bool) []string {
+	return p.targets[:min(n, len(p.targets))]
+}
+
 func TestCreateFile(t *testing.T) {
 	rootDir := persistence.InitializeFileSystem()
-	service := service.NewFileSystemService(rootDir)
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
 
 	// Test creating a new file
-	err := service.CreateFile("/testfile.txt")
+	_, err := service.CreateFile(superuser, "/testfile.txt", 0, 0, "")
+	assert.NoError(t, err)
+
+	// The file is not visible until it is completed
+	_, err = service.ReadFile(superuser, "/testfile.txt")
+	assert.Error(t, err)
+
+	_, err = service.CompleteFile(superuser, "/testfile.txt", nil)
+	assert.NoError(t, err)
+
+	_, err = service.ReadFile(superuser, "/testfile.txt")
 	assert.NoError(t, err)
 
 	// Test trying to create a file that already exists
-	err = service.CreateFile("/testfile.txt")
+	_, err = service.CreateFile(superuser, "/testfile.txt", 0, 0, "")
 	assert.Error(t, err)
 
 	// Optionally, more assertions to verify the state of rootDir
@@ -40,45 +85,157 @@ func TestCreateFile(t *testing.T) {
 
 func TestDeleteFile(t *testing.T) {
 	rootDir := persistence.InitializeFileSystem()
-	service := service.NewFileSystemService(rootDir)
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
 
 	// Setup: create a file to delete
-	_ = service.CreateFile("/testfile.txt")
+	_, _ = service.CreateFile(superuser, "/testfile.txt", 0, 0, "")
+	_, _ = service.CompleteFile(superuser, "/testfile.txt", nil)
 
 	// Test deleting the file
-	err := service.DeleteFile("/testfile.txt")
+	err := service.DeleteFile(superuser, "/testfile.txt")
 	assert.NoError(t, err)
 
 	// Test trying to delete a non-existent file
-	err = service.DeleteFile("/nonexistent.txt")
+	err = service.DeleteFile(superuser, "/nonexistent.txt")
 	assert.Error(t, err)
 }
 
 func TestCreateDirectory(t *testing.T) {
 	rootDir := persistence.InitializeFileSystem()
-	service := service.NewFileSystemService(rootDir)
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
 
 	// Test creating a new directory
-	err := service.CreateDirectory("/newdir")
+	_, err := service.CreateDirectory(superuser, "/newdir")
 	assert.NoError(t, err)
 
 	// Test trying to create a directory that already exists
-	err = service.CreateDirectory("/newdir")
+	_, err = service.CreateDirectory(superuser, "/newdir")
 	assert.Error(t, err)
 }
 
 func TestDeleteDirectory(t *testing.T) {
 	rootDir := persistence.InitializeFileSystem()
-	service := service.NewFileSystemService(rootDir)
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
 
 	// Setup: create a directory to delete
-	_ = service.CreateDirectory("/newdir")
+	_, _ = service.CreateDirectory(superuser, "/newdir")
 
 	// Test deleting the directory
-	err := service.DeleteDirectory("/newdir")
+	err := service.DeleteDirectory(superuser, "/newdir", false)
 	assert.NoError(t, err)
 
 	// Test trying to delete a non-existent directory
-	err = service.DeleteDirectory("/nonexistentdir")
+	err = service.DeleteDirectory(superuser, "/nonexistentdir", false)
+	assert.Error(t, err)
+}
+
+func TestDeleteDirectoryRecursive(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+
+	dataNodeManager := gRPC.GetInstance()
+	dataNodeManager.RegisterDataNode("10.0.9.1:50052", "dn-9")
+	service.SetPlacementPolicy(pinnedPlacement{targets: []string{"10.0.9.1:50052"}})
+
+	_, _ = service.CreateDirectory(superuser, "/job")
+	_, _ = service.CreateDirectory(superuser, "/job/output")
+	inode, err := service.CreateFile(superuser, "/job/output/part-0", 1024, 1, "")
+	assert.NoError(t, err)
+	blockID := inode.Blocks[0].BlockID
+	_, err = service.CompleteFile(superuser, "/job/output/part-0", []fs.BlockAssignment{{BlockID: blockID, DataNodeAddresses: []string{"10.0.9.1:50052"}}})
+	assert.NoError(t, err)
+	gRPC.GetBlockMap().ProcessIncrementalReport("10.0.9.1:50052", []*protobuf.ReportedBlock{{BlockId: blockID}}, nil)
+
+	// Non-empty directories are only deleted recursively
+	assert.Error(t, service.DeleteDirectory(superuser, "/job", false))
+	assert.NoError(t, service.DeleteDirectory(superuser, "/job", true))
+	_, err = service.ReadDirectory(superuser, "/job/output")
+	assert.Error(t, err)
+
+	// The DataNode holding the deleted block is asked to delete it
+	assert.Nil(t, gRPC.GetBlockMap().GetBlock(blockID))
+	commands := dataNodeManager.TakeCommands("10.0.9.1:50052", time.Now())
+	if assert.Len(t, commands, 1) {
+		assert.Equal(t, protobuf.DataNodeCommand_DELETE_BLOCKS, commands[0].GetType())
+		assert.Equal(t, []string{blockID}, commands[0].GetBlockIds())
+	}
+}
+
+func TestCreateFileReplication(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+
+	dataNodeManager := gRPC.GetInstance()
+	dataNodeManager.RegisterDataNode("10.0.0.1:50052", "dn-1")
+	dataNodeManager.RegisterDataNode("10.0.0.2:50052", "dn-2")
+	dataNodeManager.RegisterDataNode("10.0.0.3:50052", "dn-3")
+
+	// Each block goes to as many distinct DataNodes as requested
+	inode, err := service.CreateFile(superuser, "/replicated.txt", 3*64*1024*1024, 2, "")
+	assert.NoError(t, err)
+	assert.Len(t, inode.Blocks, 3)
+	for _, block := range inode.Blocks {
+		assert.Len(t, block.DataNodeAddresses, 2)
+		assert.NotEqual(t, block.DataNodeAddresses[0], block.DataNodeAddresses[1])
+	}
+
+	// Acknowledgements must be for blocks of the file
+	_, err = service.CompleteFile(superuser, "/replicated.txt", []fs.BlockAssignment{{BlockID: "unknown-block", DataNodeAddresses: []string{"10.0.0.1:50052"}}})
+	assert.Error(t, err)
+
+	// Only the assigned DataNodes that acknowledged a block are kept
+	acked := make([]fs.BlockAssignment, 0, len(inode.Blocks))
+	for _, block := range inode.Blocks {
+		acked = append(acked, fs.BlockAssignment{BlockID: block.BlockID, DataNodeAddresses: []string{block.DataNodeAddresses[0], "192.0.2.99:50052"}})
+	}
+	inode, err = service.CompleteFile(superuser, "/replicated.txt", acked)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, inode.Replication)
+	for _, block := range inode.Blocks {
+		assert.Len(t, block.DataNodeAddresses, 1)
+	}
+
+	// Replication can't exceed the configured maximum
+	_, err = service.CreateFile(superuser, "/too-many.txt", 1, 1000, "")
+	assert.Error(t, err)
+}
+
+func TestRename(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+
+	_, _ = service.CreateDirectory(superuser, "/rename-src")
+	_, _ = service.CreateDirectory(superuser, "/rename-src/sub")
+	_, _ = service.CreateDirectory(superuser, "/rename-dst")
+	for _, filePath := range []string{"/rename-src/sub/a.txt", "/rename-dst/b.txt"} {
+		_, _ = service.CreateFile(superuser, filePath, 0, 0, "")
+		_, _ = service.CompleteFile(superuser, filePath, nil)
+	}
+
+	// Whole subtrees move, renaming the moved directory
+	assert.NoError(t, service.Rename(superuser, "/rename-src", "/rename-dst/moved", false))
+	inode, err := service.ReadFile(superuser, "/rename-dst/moved/sub/a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "a.txt", inode.Name)
+	_, err = service.ReadDirectory(superuser, "/rename-src")
 	assert.Error(t, err)
+
+	// An existing destination is only replaced when asked to
+	assert.Error(t, service.Rename(superuser, "/rename-dst/moved/sub/a.txt", "/rename-dst/b.txt", false))
+	assert.NoError(t, service.Rename(superuser, "/rename-dst/moved/sub/a.txt", "/rename-dst/b.txt", true))
+	inode, err = service.ReadFile(superuser, "/rename-dst/b.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "b.txt", inode.Name)
+
+	// Directories can't be moved into themselves or replace files
+	assert.Error(t, service.Rename(superuser, "/rename-dst", "/rename-dst/moved/inside", false))
+	assert.Error(t, service.Rename(superuser, "/rename-dst/moved", "/rename-dst/b.txt", true))
+	assert.Error(t, service.Rename(superuser, "/rename-missing", "/rename-other", false))
+
+	// Files being written follow their directory
+	_, err = service.CreateFile(superuser, "/rename-dst/moved/pending.txt", 0, 0, "")
+	assert.NoError(t, err)
+	assert.NoError(t, service.Rename(superuser, "/rename-dst/moved", "/rename-final", false))
+	_, err = service.CompleteFile(superuser, "/rename-final/pending.txt", nil)
+	assert.NoError(t, err)
 }
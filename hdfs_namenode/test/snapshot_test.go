@@ -0,0 +1,202 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/config"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/fs"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/persistence"
+	"github.com/aarrasseayoub01/namenode/namenode/internal/service"
+	"github.com/aarrasseayoub01/namenode/protobuf"
+)
+
+func TestSnapshots(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+
+	dataNodeManager := gRPC.GetInstance()
+	dataNodeManager.RegisterDataNode("10.0.10.1:50052", "dn-10")
+	service.SetPlacementPolicy(pinnedPlacement{targets: []string{"10.0.10.1:50052"}})
+
+	_, err := service.CreateDirectory(superuser, "/snap")
+	assert.NoError(t, err)
+	_, err = service.CreateDirectory(superuser, "/snap/data")
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetOwner(superuser, "/snap", "alice", ""))
+	inode, err := service.CreateFile(superuser, "/snap/data/part-0", 1024, 1, "")
+	assert.NoError(t, err)
+	blockID := inode.Blocks[0].BlockID
+	_, err = service.CompleteFile(superuser, "/snap/data/part-0", []fs.BlockAssignment{{BlockID: blockID, DataNodeAddresses: []string{"10.0.10.1:50052"}}})
+	assert.NoError(t, err)
+
+	// Only the superuser may allow snapshots, which can't be nested
+	_, err = service.CreateSnapshot("alice", "/snap", "s1")
+	assert.Error(t, err)
+	assert.ErrorIs(t, service.AllowSnapshot("alice", "/snap"), fs.ErrPermissionDenied)
+	assert.NoError(t, service.AllowSnapshot(superuser, "/snap"))
+	assert.Error(t, service.AllowSnapshot(superuser, "/snap/data"))
+
+	snapshotPath, err := service.CreateSnapshot("alice", "/snap", "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, "/snap/.snapshot/s1", snapshotPath)
+	_, err = service.CreateSnapshot("alice", "/snap", "s1")
+	assert.Error(t, err)
+
+	// Deleting the file leaves it readable in the snapshot, and its block kept
+	assert.NoError(t, service.DeleteFile(superuser, "/snap/data/part-0"))
+	_, err = service.ReadFile(superuser, "/snap/data/part-0")
+	assert.Error(t, err)
+	snapshotted, err := service.ReadFile("alice", "/snap/.snapshot/s1/data/part-0")
+	assert.NoError(t, err)
+	assert.Equal(t, blockID, snapshotted.Blocks[0].BlockID)
+	assert.NotNil(t, gRPC.GetBlockMap().GetBlock(blockID))
+	assert.Empty(t, dataNodeManager.TakeCommands("10.0.10.1:50052", time.Now()))
+
+	entries, err := service.ReadDirectory("alice", "/snap/.snapshot")
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "s1", entries[0].Name)
+	}
+	entries, err = service.ReadDirectory("alice", "/snap/.snapshot/s1/data")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// Snapshots are read-only, and .snapshot is reserved
+	_, err = service.CreateDirectory(superuser, "/snap/.snapshot/s1/new")
+	assert.ErrorIs(t, err, fs.ErrSnapshotReadOnly)
+	assert.ErrorIs(t, service.DeleteFile(superuser, "/snap/.snapshot/s1/data/part-0"), fs.ErrSnapshotReadOnly)
+	assert.ErrorIs(t, service.SetPermission(superuser, "/snap/.snapshot/s1", 0777), fs.ErrSnapshotReadOnly)
+	_, err = service.CreateDirectory(superuser, "/snap/data/.snapshot")
+	assert.ErrorIs(t, err, fs.ErrSnapshotReadOnly)
+
+	// A directory with snapshots can't be deleted or stop being snapshottable
+	assert.Error(t, service.DeleteDirectory(superuser, "/snap", true))
+	assert.Error(t, service.DisallowSnapshot(superuser, "/snap"))
+
+	assert.NoError(t, service.RenameSnapshot("alice", "/snap", "s1", "before-cleanup"))
+	_, err = service.ReadFile("alice", "/snap/.snapshot/before-cleanup/data/part-0")
+	assert.NoError(t, err)
+
+	// Deleting the last snapshot referencing the block deletes it
+	assert.NoError(t, service.DeleteSnapshot("alice", "/snap", "before-cleanup"))
+	assert.Nil(t, gRPC.GetBlockMap().GetBlock(blockID))
+	commands := dataNodeManager.TakeCommands("10.0.10.1:50052", time.Now())
+	if assert.Len(t, commands, 1) {
+		assert.Equal(t, protobuf.DataNodeCommand_DELETE_BLOCKS, commands[0].GetType())
+		assert.Equal(t, []string{blockID}, commands[0].GetBlockIds())
+	}
+	assert.NoError(t, service.DisallowSnapshot(superuser, "/snap"))
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+
+	for _, dir := range []string{"/snapdiff", "/snapdiff/logs", "/snapdiff/tmp", "/snapdiff/gone"} {
+		_, err := service.CreateDirectory(superuser, dir)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, service.AllowSnapshot(superuser, "/snapdiff"))
+	_, err := service.CreateSnapshot(superuser, "/snapdiff", "s1")
+	assert.NoError(t, err)
+
+	_, err = service.CreateDirectory(superuser, "/snapdiff/logs/today")
+	assert.NoError(t, err)
+	assert.NoError(t, service.Rename(superuser, "/snapdiff/tmp", "/snapdiff/scratch", false))
+	assert.NoError(t, service.DeleteDirectory(superuser, "/snapdiff/gone", false))
+	_, err = service.CreateSnapshot(superuser, "/snapdiff", "s2")
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetPermission(superuser, "/snapdiff/logs/today", 0700))
+
+	diff, err := service.SnapshotDiff(superuser, "/snapdiff", "s1", "s2")
+	assert.NoError(t, err)
+	assert.Equal(t, []fs.DiffEntry{
+		{Type: fs.DiffModify, Path: "."},
+		{Type: fs.DiffDelete, Path: "gone"},
+		{Type: fs.DiffModify, Path: "logs"},
+		{Type: fs.DiffCreate, Path: "logs/today"},
+		{Type: fs.DiffRename, Path: "tmp", Target: "scratch"},
+	}, diff)
+
+	// An empty snapshot name stands for the current tree
+	diff, err = service.SnapshotDiff(superuser, "/snapdiff", "s2", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []fs.DiffEntry{{Type: fs.DiffModify, Path: "logs/today"}}, diff)
+
+	_, err = service.SnapshotDiff(superuser, "/snapdiff", "s1", "missing")
+	assert.Error(t, err)
+}
+
+func TestSnapshotCopiesBlockLists(t *testing.T) {
+	file := &fs.Inode{Name: "part-0", Blocks: []fs.BlockAssignment{{BlockID: "snap-copy-0", DataNodeAddresses: []string{"10.0.10.1:50052"}}}}
+	dir := &fs.Directory{
+		Inode:         &fs.Inode{Name: "dir", IsDir: true},
+		ChildFiles:    map[string]*fs.Inode{"part-0": file},
+		ChildDirs:     map[string]*fs.Directory{},
+		Snapshottable: true,
+	}
+	snapshot, err := fs.CreateSnapshot(dir, "s1", time.Now())
+	assert.NoError(t, err)
+
+	// Appending to the file or moving its replicas copies it first, leaving
+	// the snapshot as it was
+	file = fs.MutableFile(dir, "part-0")
+	file.Blocks[0].DataNodeAddresses[0] = "10.0.10.2:50052"
+	file.Blocks = append(file.Blocks, fs.BlockAssignment{BlockID: "snap-copy-1"})
+	assert.Equal(t, []fs.BlockAssignment{{BlockID: "snap-copy-0", DataNodeAddresses: []string{"10.0.10.1:50052"}}}, snapshot.Root.ChildFiles["part-0"].Blocks)
+	assert.Len(t, dir.ChildFiles["part-0"].Blocks, 2)
+}
+
+func TestSnapshotSharesUnchangedSubtrees(t *testing.T) {
+	rootDir := persistence.InitializeFileSystem()
+	service := service.NewFileSystemService(rootDir, config.DefaultConfig())
+
+	for _, dir := range []string{"/cow", "/cow/logs", "/cow/logs/2023", "/cow/logs/2024", "/cow/data"} {
+		_, err := service.CreateDirectory(superuser, dir)
+		assert.NoError(t, err)
+	}
+	_, err := service.CreateFile(superuser, "/cow/data/empty", 0, 1, "")
+	assert.NoError(t, err)
+	_, err = service.CompleteFile(superuser, "/cow/data/empty", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, service.AllowSnapshot(superuser, "/cow"))
+	_, err = service.CreateSnapshot(superuser, "/cow", "s1")
+	assert.NoError(t, err)
+
+	// A new snapshot shares everything below the snapshotted directory
+	for _, dir := range []string{"logs", "logs/2023", "data"} {
+		assert.Same(t, fs.FindDirectory(rootDir, "/cow/"+dir), fs.FindDirectory(rootDir, "/cow/.snapshot/s1/"+dir))
+	}
+
+	// Changes copy what they change and the directories above it, and leave
+	// the rest shared
+	_, err = service.CreateDirectory(superuser, "/cow/logs/2024/jan")
+	assert.NoError(t, err)
+	assert.NoError(t, service.SetPermission(superuser, "/cow/data/empty", 0600))
+	assert.NoError(t, service.Rename(superuser, "/cow/data/empty", "/cow/data/renamed", false))
+	for _, dir := range []string{"logs", "logs/2024", "data"} {
+		assert.NotSame(t, fs.FindDirectory(rootDir, "/cow/"+dir), fs.FindDirectory(rootDir, "/cow/.snapshot/s1/"+dir))
+	}
+	assert.Same(t, fs.FindDirectory(rootDir, "/cow/logs/2023"), fs.FindDirectory(rootDir, "/cow/.snapshot/s1/logs/2023"))
+	assert.Empty(t, fs.FindDirectory(rootDir, "/cow/.snapshot/s1/logs/2024").ChildDirs)
+	snapshotted, err := service.ReadFile(superuser, "/cow/.snapshot/s1/data/empty")
+	assert.NoError(t, err)
+	assert.Equal(t, "empty", snapshotted.Name)
+	assert.NotEqual(t, fs.Permission(0600), snapshotted.Permission)
+	renamed, err := service.ReadFile(superuser, "/cow/data/renamed")
+	assert.NoError(t, err)
+	assert.Equal(t, fs.Permission(0600), renamed.Permission)
+
+	// The fsimage stores what is shared once, and it stays shared once loaded
+	_, err = persistence.SaveNamespace()
+	assert.NoError(t, err)
+	restored := persistence.InitializeFileSystem()
+	shared := fs.FindDirectory(restored, "/cow/logs/2023")
+	assert.Same(t, shared, fs.FindDirectory(restored, "/cow/.snapshot/s1/logs/2023"))
+	assert.NotSame(t, shared, fs.MutableDirectory(restored, "/cow/logs/2023"))
+	assert.Same(t, shared, fs.FindDirectory(restored, "/cow/.snapshot/s1/logs/2023"))
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/aarrasseayoub01/namenode/namenode/internal/gRPC"
+)
+
+func TestLoadTopologyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "topology.txt")
+	mapping := "# host rack\n10.0.1.1 /dc1/rack1\n\n10.0.2.1:50052 dc1/rack2/\n"
+	assert.NoError(t, os.WriteFile(path, []byte(mapping), 0644))
+
+	topology, err := gRPC.LoadTopologyFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "/dc1/rack1", topology.ResolveRack("10.0.1.1:50052"))
+	assert.Equal(t, "/dc1/rack1", topology.ResolveRack("10.0.1.1"))
+	assert.Equal(t, "/dc1/rack2", topology.ResolveRack("10.0.2.1:50052"))
+	assert.Equal(t, gRPC.DefaultRack, topology.ResolveRack("10.0.2.1:50053"))
+
+	assert.NoError(t, os.WriteFile(path, []byte("10.0.1.1\n"), 0644))
+	_, err = gRPC.LoadTopologyFile(path)
+	assert.Error(t, err)
+}
+
+func TestDataNodeRacks(t *testing.T) {
+	manager := gRPC.NewDataNodeManager()
+	manager.RegisterDataNode("10.0.1.1:50052", "dn1")
+	assert.Equal(t, gRPC.DefaultRack, manager.GetDataNodes()["10.0.1.1:50052"].Rack)
+
+	topology, err := gRPC.NewStaticTopology(map[string]string{"10.0.1.1": "/rack1", "10.0.2.1": "/rack2"})
+	assert.NoError(t, err)
+	manager.SetTopologyResolver(topology)
+	manager.RegisterDataNode("10.0.2.1:50052", "dn2")
+
+	dataNodes := manager.GetDataNodes()
+	assert.Equal(t, "/rack1", dataNodes["10.0.1.1:50052"].Rack)
+	assert.Equal(t, "/rack2", dataNodes["10.0.2.1:50052"].Rack)
+	assert.Equal(t, 2, manager.Report().Racks)
+
+	// Resolvers returning an invalid rack fall back to the default rack
+	manager.SetTopologyResolver(gRPC.TopologyResolverFunc(func(string) string { return "" }))
+	assert.Equal(t, gRPC.DefaultRack, manager.ResolveRack("10.0.1.1"))
+}
@@ -0,0 +1,637 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.25.1
+// source: editlog.proto
+
+package protobuf
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EditLogOp_Type int32
+
+const (
+	EditLogOp_UNKNOWN           EditLogOp_Type = 0
+	EditLogOp_CREATE_FILE       EditLogOp_Type = 1
+	EditLogOp_DELETE_FILE       EditLogOp_Type = 2
+	EditLogOp_CREATE_DIRECTORY  EditLogOp_Type = 3
+	EditLogOp_DELETE_DIRECTORY  EditLogOp_Type = 4
+	EditLogOp_RENAME            EditLogOp_Type = 5
+	EditLogOp_SET_PERMISSION    EditLogOp_Type = 6
+	EditLogOp_SET_OWNER         EditLogOp_Type = 7
+	EditLogOp_SET_ACL           EditLogOp_Type = 8
+	EditLogOp_SET_QUOTA         EditLogOp_Type = 9
+	EditLogOp_ALLOW_SNAPSHOT    EditLogOp_Type = 10
+	EditLogOp_DISALLOW_SNAPSHOT EditLogOp_Type = 11
+	EditLogOp_CREATE_SNAPSHOT   EditLogOp_Type = 12
+	EditLogOp_DELETE_SNAPSHOT   EditLogOp_Type = 13
+	EditLogOp_RENAME_SNAPSHOT   EditLogOp_Type = 14
+)
+
+// Enum value maps for EditLogOp_Type.
+var (
+	EditLogOp_Type_name = map[int32]string{
+		0:  "UNKNOWN",
+		1:  "CREATE_FILE",
+		2:  "DELETE_FILE",
+		3:  "CREATE_DIRECTORY",
+		4:  "DELETE_DIRECTORY",
+		5:  "RENAME",
+		6:  "SET_PERMISSION",
+		7:  "SET_OWNER",
+		8:  "SET_ACL",
+		9:  "SET_QUOTA",
+		10: "ALLOW_SNAPSHOT",
+		11: "DISALLOW_SNAPSHOT",
+		12: "CREATE_SNAPSHOT",
+		13: "DELETE_SNAPSHOT",
+		14: "RENAME_SNAPSHOT",
+	}
+	EditLogOp_Type_value = map[string]int32{
+		"UNKNOWN":           0,
+		"CREATE_FILE":       1,
+		"DELETE_FILE":       2,
+		"CREATE_DIRECTORY":  3,
+		"DELETE_DIRECTORY":  4,
+		"RENAME":            5,
+		"SET_PERMISSION":    6,
+		"SET_OWNER":         7,
+		"SET_ACL":           8,
+		"SET_QUOTA":         9,
+		"ALLOW_SNAPSHOT":    10,
+		"DISALLOW_SNAPSHOT": 11,
+		"CREATE_SNAPSHOT":   12,
+		"DELETE_SNAPSHOT":   13,
+		"RENAME_SNAPSHOT":   14,
+	}
+)
+
+func (x EditLogOp_Type) Enum() *EditLogOp_Type {
+	p := new(EditLogOp_Type)
+	*p = x
+	return p
+}
+
+func (x EditLogOp_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EditLogOp_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_editlog_proto_enumTypes[0].Descriptor()
+}
+
+func (EditLogOp_Type) Type() protoreflect.EnumType {
+	return &file_editlog_proto_enumTypes[0]
+}
+
+func (x EditLogOp_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EditLogOp_Type.Descriptor instead.
+func (EditLogOp_Type) EnumDescriptor() ([]byte, []int) {
+	return file_editlog_proto_rawDescGZIP(), []int{0, 0}
+}
+
+// An EditLogOp is the payload of an edit log record, after the transaction ID
+// held in the record framing; see editlog.go for the framing. Fields may be
+// added without changing the edit log version, as readers skip fields they
+// don't know.
+type EditLogOp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type EditLogOp_Type `protobuf:"varint,1,opt,name=type,proto3,enum=hdfs.EditLogOp_Type" json:"type,omitempty"`
+	// In nanoseconds since the epoch
+	Timestamp int64  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Path      string `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`
+	// Set for CREATE_FILE and CREATE_DIRECTORY
+	Inode *EditLogInode `protobuf:"bytes,4,opt,name=inode,proto3" json:"inode,omitempty"`
+	// Set for RENAME, which moves path
+	Destination string `protobuf:"bytes,5,opt,name=destination,proto3" json:"destination,omitempty"`
+	Overwrite   bool   `protobuf:"varint,6,opt,name=overwrite,proto3" json:"overwrite,omitempty"`
+	// Set for SET_OWNER; an empty owner or group is left unchanged
+	Owner string `protobuf:"bytes,7,opt,name=owner,proto3" json:"owner,omitempty"`
+	Group string `protobuf:"bytes,8,opt,name=group,proto3" json:"group,omitempty"`
+	// Set for SET_PERMISSION and SET_ACL
+	Permission uint32             `protobuf:"varint,9,opt,name=permission,proto3" json:"permission,omitempty"`
+	Acl        []*FsImageAclEntry `protobuf:"bytes,10,rep,name=acl,proto3" json:"acl,omitempty"`
+	// Set for SET_QUOTA; 0 clears a quota
+	NamespaceQuota int64 `protobuf:"varint,11,opt,name=namespace_quota,json=namespaceQuota,proto3" json:"namespace_quota,omitempty"`
+	SpaceQuota     int64 `protobuf:"varint,12,opt,name=space_quota,json=spaceQuota,proto3" json:"space_quota,omitempty"`
+	// Set for CREATE_SNAPSHOT, DELETE_SNAPSHOT and RENAME_SNAPSHOT
+	SnapshotName    string `protobuf:"bytes,13,opt,name=snapshot_name,json=snapshotName,proto3" json:"snapshot_name,omitempty"`
+	SnapshotNewName string `protobuf:"bytes,14,opt,name=snapshot_new_name,json=snapshotNewName,proto3" json:"snapshot_new_name,omitempty"`
+}
+
+func (x *EditLogOp) Reset() {
+	*x = EditLogOp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_editlog_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EditLogOp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditLogOp) ProtoMessage() {}
+
+func (x *EditLogOp) ProtoReflect() protoreflect.Message {
+	mi := &file_editlog_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditLogOp.ProtoReflect.Descriptor instead.
+func (*EditLogOp) Descriptor() ([]byte, []int) {
+	return file_editlog_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *EditLogOp) GetType() EditLogOp_Type {
+	if x != nil {
+		return x.Type
+	}
+	return EditLogOp_UNKNOWN
+}
+
+func (x *EditLogOp) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *EditLogOp) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *EditLogOp) GetInode() *EditLogInode {
+	if x != nil {
+		return x.Inode
+	}
+	return nil
+}
+
+func (x *EditLogOp) GetDestination() string {
+	if x != nil {
+		return x.Destination
+	}
+	return ""
+}
+
+func (x *EditLogOp) GetOverwrite() bool {
+	if x != nil {
+		return x.Overwrite
+	}
+	return false
+}
+
+func (x *EditLogOp) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *EditLogOp) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *EditLogOp) GetPermission() uint32 {
+	if x != nil {
+		return x.Permission
+	}
+	return 0
+}
+
+func (x *EditLogOp) GetAcl() []*FsImageAclEntry {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+func (x *EditLogOp) GetNamespaceQuota() int64 {
+	if x != nil {
+		return x.NamespaceQuota
+	}
+	return 0
+}
+
+func (x *EditLogOp) GetSpaceQuota() int64 {
+	if x != nil {
+		return x.SpaceQuota
+	}
+	return 0
+}
+
+func (x *EditLogOp) GetSnapshotName() string {
+	if x != nil {
+		return x.SnapshotName
+	}
+	return ""
+}
+
+func (x *EditLogOp) GetSnapshotNewName() string {
+	if x != nil {
+		return x.SnapshotNewName
+	}
+	return ""
+}
+
+// EditLogInode is an inode created by a transaction. Unlike FsImageInode it
+// holds its strings and blocks, as there are no tables to refer to.
+type EditLogInode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id    int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Owner string `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Group string `protobuf:"bytes,4,opt,name=group,proto3" json:"group,omitempty"`
+	IsDir bool   `protobuf:"varint,5,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	Size  int64  `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	// Modification time, in nanoseconds since the epoch
+	Timestamp   int64              `protobuf:"varint,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Replication int32              `protobuf:"varint,8,opt,name=replication,proto3" json:"replication,omitempty"`
+	Permission  uint32             `protobuf:"varint,9,opt,name=permission,proto3" json:"permission,omitempty"`
+	Acl         []*FsImageAclEntry `protobuf:"bytes,10,rep,name=acl,proto3" json:"acl,omitempty"`
+	Blocks      []*EditLogBlock    `protobuf:"bytes,11,rep,name=blocks,proto3" json:"blocks,omitempty"`
+}
+
+func (x *EditLogInode) Reset() {
+	*x = EditLogInode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_editlog_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EditLogInode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditLogInode) ProtoMessage() {}
+
+func (x *EditLogInode) ProtoReflect() protoreflect.Message {
+	mi := &file_editlog_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditLogInode.ProtoReflect.Descriptor instead.
+func (*EditLogInode) Descriptor() ([]byte, []int) {
+	return file_editlog_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *EditLogInode) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *EditLogInode) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *EditLogInode) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *EditLogInode) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *EditLogInode) GetIsDir() bool {
+	if x != nil {
+		return x.IsDir
+	}
+	return false
+}
+
+func (x *EditLogInode) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *EditLogInode) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *EditLogInode) GetReplication() int32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+func (x *EditLogInode) GetPermission() uint32 {
+	if x != nil {
+		return x.Permission
+	}
+	return 0
+}
+
+func (x *EditLogInode) GetAcl() []*FsImageAclEntry {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+func (x *EditLogInode) GetBlocks() []*EditLogBlock {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+type EditLogBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BlockId string `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	// The DataNodes the block was written to
+	Locations []string `protobuf:"bytes,2,rep,name=locations,proto3" json:"locations,omitempty"`
+}
+
+func (x *EditLogBlock) Reset() {
+	*x = EditLogBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_editlog_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EditLogBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditLogBlock) ProtoMessage() {}
+
+func (x *EditLogBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_editlog_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditLogBlock.ProtoReflect.Descriptor instead.
+func (*EditLogBlock) Descriptor() ([]byte, []int) {
+	return file_editlog_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EditLogBlock) GetBlockId() string {
+	if x != nil {
+		return x.BlockId
+	}
+	return ""
+}
+
+func (x *EditLogBlock) GetLocations() []string {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+var File_editlog_proto protoreflect.FileDescriptor
+
+var file_editlog_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x65, 0x64, 0x69, 0x74, 0x6c, 0x6f, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x04, 0x68, 0x64, 0x66, 0x73, 0x1a, 0x0d, 0x66, 0x73, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0xfa, 0x05, 0x0a, 0x09, 0x45, 0x64, 0x69, 0x74, 0x4c, 0x6f, 0x67,
+	0x4f, 0x70, 0x12, 0x28, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
+	0x32, 0x14, 0x2e, 0x68, 0x64, 0x66, 0x73, 0x2e, 0x45, 0x64, 0x69, 0x74, 0x4c, 0x6f, 0x67, 0x4f,
+	0x70, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x28,
+	0x0a, 0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x68, 0x64, 0x66, 0x73, 0x2e, 0x45, 0x64, 0x69, 0x74, 0x4c, 0x6f, 0x67, 0x49, 0x6e, 0x6f, 0x64,
+	0x65, 0x52, 0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x74,
+	0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64,
+	0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x6f, 0x76,
+	0x65, 0x72, 0x77, 0x72, 0x69, 0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x6f,
+	0x76, 0x65, 0x72, 0x77, 0x72, 0x69, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65,
+	0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x14,
+	0x0a, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x67,
+	0x72, 0x6f, 0x75, 0x70, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x03, 0x61, 0x63, 0x6c, 0x18, 0x0a, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x15, 0x2e, 0x68, 0x64, 0x66, 0x73, 0x2e, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65,
+	0x41, 0x63, 0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x12, 0x27, 0x0a,
+	0x0f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x71, 0x75, 0x6f, 0x74, 0x61,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f,
+	0x71, 0x75, 0x6f, 0x74, 0x61, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c,
+	0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x2a, 0x0a, 0x11,
+	0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x6e, 0x65, 0x77, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
+	0x74, 0x4e, 0x65, 0x77, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x96, 0x02, 0x0a, 0x04, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0f,
+	0x0a, 0x0b, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x46, 0x49, 0x4c, 0x45, 0x10, 0x01, 0x12,
+	0x0f, 0x0a, 0x0b, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45, 0x5f, 0x46, 0x49, 0x4c, 0x45, 0x10, 0x02,
+	0x12, 0x14, 0x0a, 0x10, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x44, 0x49, 0x52, 0x45, 0x43,
+	0x54, 0x4f, 0x52, 0x59, 0x10, 0x03, 0x12, 0x14, 0x0a, 0x10, 0x44, 0x45, 0x4c, 0x45, 0x54, 0x45,
+	0x5f, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x4f, 0x52, 0x59, 0x10, 0x04, 0x12, 0x0a, 0x0a, 0x06,
+	0x52, 0x45, 0x4e, 0x41, 0x4d, 0x45, 0x10, 0x05, 0x12, 0x12, 0x0a, 0x0e, 0x53, 0x45, 0x54, 0x5f,
+	0x50, 0x45, 0x52, 0x4d, 0x49, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x06, 0x12, 0x0d, 0x0a, 0x09,
+	0x53, 0x45, 0x54, 0x5f, 0x4f, 0x57, 0x4e, 0x45, 0x52, 0x10, 0x07, 0x12, 0x0b, 0x0a, 0x07, 0x53,
+	0x45, 0x54, 0x5f, 0x41, 0x43, 0x4c, 0x10, 0x08, 0x12, 0x0d, 0x0a, 0x09, 0x53, 0x45, 0x54, 0x5f,
+	0x51, 0x55, 0x4f, 0x54, 0x41, 0x10, 0x09, 0x12, 0x12, 0x0a, 0x0e, 0x41, 0x4c, 0x4c, 0x4f, 0x57,
+	0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f, 0x54, 0x10, 0x0a, 0x12, 0x15, 0x0a, 0x11, 0x44,
+	0x49, 0x53, 0x41, 0x4c, 0x4c, 0x4f, 0x57, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f, 0x54,
+	0x10, 0x0b, 0x12, 0x13, 0x0a, 0x0f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x53, 0x4e, 0x41,
+	0x50, 0x53, 0x48, 0x4f, 0x54, 0x10, 0x0c, 0x12, 0x13, 0x0a, 0x0f, 0x44, 0x45, 0x4c, 0x45, 0x54,
+	0x45, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f, 0x54, 0x10, 0x0d, 0x12, 0x13, 0x0a, 0x0f,
+	0x52, 0x45, 0x4e, 0x41, 0x4d, 0x45, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f, 0x54, 0x10,
+	0x0e, 0x22, 0xbe, 0x02, 0x0a, 0x0c, 0x45, 0x64, 0x69, 0x74, 0x4c, 0x6f, 0x67, 0x49, 0x6e, 0x6f,
+	0x64, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x12, 0x15, 0x0a, 0x06, 0x69, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x05, 0x69, 0x73, 0x44, 0x69, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x1c, 0x0a,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x20, 0x0a, 0x0b, 0x72,
+	0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x0a,
+	0x0a, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0a, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x27, 0x0a,
+	0x03, 0x61, 0x63, 0x6c, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x68, 0x64, 0x66,
+	0x73, 0x2e, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41, 0x63, 0x6c, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x12, 0x2a, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
+	0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x68, 0x64, 0x66, 0x73, 0x2e, 0x45, 0x64,
+	0x69, 0x74, 0x4c, 0x6f, 0x67, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x73, 0x22, 0x47, 0x0a, 0x0c, 0x45, 0x64, 0x69, 0x74, 0x4c, 0x6f, 0x67, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x1c, 0x0a,
+	0x09, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x42, 0x2e, 0x5a, 0x2c, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x61, 0x72, 0x72, 0x61, 0x73,
+	0x73, 0x65, 0x61, 0x79, 0x6f, 0x75, 0x62, 0x30, 0x31, 0x2f, 0x64, 0x61, 0x74, 0x61, 0x6e, 0x6f,
+	0x64, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_editlog_proto_rawDescOnce sync.Once
+	file_editlog_proto_rawDescData = file_editlog_proto_rawDesc
+)
+
+func file_editlog_proto_rawDescGZIP() []byte {
+	file_editlog_proto_rawDescOnce.Do(func() {
+		file_editlog_proto_rawDescData = protoimpl.X.CompressGZIP(file_editlog_proto_rawDescData)
+	})
+	return file_editlog_proto_rawDescData
+}
+
+var file_editlog_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_editlog_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_editlog_proto_goTypes = []interface{}{
+	(EditLogOp_Type)(0),     // 0: hdfs.EditLogOp.Type
+	(*EditLogOp)(nil),       // 1: hdfs.EditLogOp
+	(*EditLogInode)(nil),    // 2: hdfs.EditLogInode
+	(*EditLogBlock)(nil),    // 3: hdfs.EditLogBlock
+	(*FsImageAclEntry)(nil), // 4: hdfs.FsImageAclEntry
+}
+var file_editlog_proto_depIdxs = []int32{
+	0, // 0: hdfs.EditLogOp.type:type_name -> hdfs.EditLogOp.Type
+	2, // 1: hdfs.EditLogOp.inode:type_name -> hdfs.EditLogInode
+	4, // 2: hdfs.EditLogOp.acl:type_name -> hdfs.FsImageAclEntry
+	4, // 3: hdfs.EditLogInode.acl:type_name -> hdfs.FsImageAclEntry
+	3, // 4: hdfs.EditLogInode.blocks:type_name -> hdfs.EditLogBlock
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_editlog_proto_init() }
+func file_editlog_proto_init() {
+	if File_editlog_proto != nil {
+		return
+	}
+	file_fsimage_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_editlog_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EditLogOp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_editlog_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EditLogInode); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_editlog_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EditLogBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_editlog_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_editlog_proto_goTypes,
+		DependencyIndexes: file_editlog_proto_depIdxs,
+		EnumInfos:         file_editlog_proto_enumTypes,
+		MessageInfos:      file_editlog_proto_msgTypes,
+	}.Build()
+	File_editlog_proto = out.File
+	file_editlog_proto_rawDesc = nil
+	file_editlog_proto_goTypes = nil
+	file_editlog_proto_depIdxs = nil
+}
@@ -0,0 +1,1089 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.25.1
+// source: fsimage.proto
+
+package protobuf
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FsImageHeader struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	LayoutVersion int32 `protobuf:"varint,1,opt,name=layout_version,json=layoutVersion,proto3" json:"layout_version,omitempty"`
+	// The ID of the last transaction the image includes
+	LastTxId int64 `protobuf:"varint,2,opt,name=last_tx_id,json=lastTxId,proto3" json:"last_tx_id,omitempty"`
+	// The names of the sections following the header, in order
+	Sections []string `protobuf:"bytes,3,rep,name=sections,proto3" json:"sections,omitempty"`
+}
+
+func (x *FsImageHeader) Reset() {
+	*x = FsImageHeader{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageHeader) ProtoMessage() {}
+
+func (x *FsImageHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageHeader.ProtoReflect.Descriptor instead.
+func (*FsImageHeader) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *FsImageHeader) GetLayoutVersion() int32 {
+	if x != nil {
+		return x.LayoutVersion
+	}
+	return 0
+}
+
+func (x *FsImageHeader) GetLastTxId() int64 {
+	if x != nil {
+		return x.LastTxId
+	}
+	return 0
+}
+
+func (x *FsImageHeader) GetSections() []string {
+	if x != nil {
+		return x.Sections
+	}
+	return nil
+}
+
+// "strings": owners, groups, names and DataNode addresses used more than once
+type FsImageStringTable struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Strings []string `protobuf:"bytes,1,rep,name=strings,proto3" json:"strings,omitempty"`
+}
+
+func (x *FsImageStringTable) Reset() {
+	*x = FsImageStringTable{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageStringTable) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageStringTable) ProtoMessage() {}
+
+func (x *FsImageStringTable) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageStringTable.ProtoReflect.Descriptor instead.
+func (*FsImageStringTable) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FsImageStringTable) GetStrings() []string {
+	if x != nil {
+		return x.Strings
+	}
+	return nil
+}
+
+// "blocks": the blocks of every file, live or snapshotted
+type FsImageBlockSection struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Blocks []*FsImageBlock `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+}
+
+func (x *FsImageBlockSection) Reset() {
+	*x = FsImageBlockSection{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageBlockSection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageBlockSection) ProtoMessage() {}
+
+func (x *FsImageBlockSection) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageBlockSection.ProtoReflect.Descriptor instead.
+func (*FsImageBlockSection) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FsImageBlockSection) GetBlocks() []*FsImageBlock {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+type FsImageBlock struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BlockId string `protobuf:"bytes,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	// Positions in the string table of the DataNodes the block was written to
+	Locations []uint32 `protobuf:"varint,2,rep,packed,name=locations,proto3" json:"locations,omitempty"`
+}
+
+func (x *FsImageBlock) Reset() {
+	*x = FsImageBlock{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageBlock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageBlock) ProtoMessage() {}
+
+func (x *FsImageBlock) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageBlock.ProtoReflect.Descriptor instead.
+func (*FsImageBlock) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FsImageBlock) GetBlockId() string {
+	if x != nil {
+		return x.BlockId
+	}
+	return ""
+}
+
+func (x *FsImageBlock) GetLocations() []uint32 {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+// "inodes": every file and directory; the first is the root directory
+type FsImageInodeSection struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Inodes []*FsImageInode `protobuf:"bytes,1,rep,name=inodes,proto3" json:"inodes,omitempty"`
+}
+
+func (x *FsImageInodeSection) Reset() {
+	*x = FsImageInodeSection{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageInodeSection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageInodeSection) ProtoMessage() {}
+
+func (x *FsImageInodeSection) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageInodeSection.ProtoReflect.Descriptor instead.
+func (*FsImageInodeSection) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FsImageInodeSection) GetInodes() []*FsImageInode {
+	if x != nil {
+		return x.Inodes
+	}
+	return nil
+}
+
+type FsImageInode struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Positions in the string table
+	Name  uint32 `protobuf:"varint,2,opt,name=name,proto3" json:"name,omitempty"`
+	Owner uint32 `protobuf:"varint,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Group uint32 `protobuf:"varint,4,opt,name=group,proto3" json:"group,omitempty"`
+	IsDir bool   `protobuf:"varint,5,opt,name=is_dir,json=isDir,proto3" json:"is_dir,omitempty"`
+	Size  int64  `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`
+	// Modification time, in nanoseconds since the epoch
+	Timestamp   int64              `protobuf:"varint,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Replication int32              `protobuf:"varint,8,opt,name=replication,proto3" json:"replication,omitempty"`
+	Permission  uint32             `protobuf:"varint,9,opt,name=permission,proto3" json:"permission,omitempty"`
+	Acl         []*FsImageAclEntry `protobuf:"bytes,10,rep,name=acl,proto3" json:"acl,omitempty"`
+	// Positions in the block section
+	Blocks []uint32 `protobuf:"varint,11,rep,packed,name=blocks,proto3" json:"blocks,omitempty"`
+	// Set for directories only
+	Quota         *FsImageQuota `protobuf:"bytes,12,opt,name=quota,proto3" json:"quota,omitempty"`
+	Snapshottable bool          `protobuf:"varint,13,opt,name=snapshottable,proto3" json:"snapshottable,omitempty"`
+}
+
+func (x *FsImageInode) Reset() {
+	*x = FsImageInode{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageInode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageInode) ProtoMessage() {}
+
+func (x *FsImageInode) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageInode.ProtoReflect.Descriptor instead.
+func (*FsImageInode) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FsImageInode) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *FsImageInode) GetName() uint32 {
+	if x != nil {
+		return x.Name
+	}
+	return 0
+}
+
+func (x *FsImageInode) GetOwner() uint32 {
+	if x != nil {
+		return x.Owner
+	}
+	return 0
+}
+
+func (x *FsImageInode) GetGroup() uint32 {
+	if x != nil {
+		return x.Group
+	}
+	return 0
+}
+
+func (x *FsImageInode) GetIsDir() bool {
+	if x != nil {
+		return x.IsDir
+	}
+	return false
+}
+
+func (x *FsImageInode) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FsImageInode) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *FsImageInode) GetReplication() int32 {
+	if x != nil {
+		return x.Replication
+	}
+	return 0
+}
+
+func (x *FsImageInode) GetPermission() uint32 {
+	if x != nil {
+		return x.Permission
+	}
+	return 0
+}
+
+func (x *FsImageInode) GetAcl() []*FsImageAclEntry {
+	if x != nil {
+		return x.Acl
+	}
+	return nil
+}
+
+func (x *FsImageInode) GetBlocks() []uint32 {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *FsImageInode) GetQuota() *FsImageQuota {
+	if x != nil {
+		return x.Quota
+	}
+	return nil
+}
+
+func (x *FsImageInode) GetSnapshottable() bool {
+	if x != nil {
+		return x.Snapshottable
+	}
+	return false
+}
+
+type FsImageAclEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Scope      string `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"`
+	Type       string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Name       string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Permission uint32 `protobuf:"varint,4,opt,name=permission,proto3" json:"permission,omitempty"`
+}
+
+func (x *FsImageAclEntry) Reset() {
+	*x = FsImageAclEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageAclEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageAclEntry) ProtoMessage() {}
+
+func (x *FsImageAclEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageAclEntry.ProtoReflect.Descriptor instead.
+func (*FsImageAclEntry) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *FsImageAclEntry) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *FsImageAclEntry) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *FsImageAclEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FsImageAclEntry) GetPermission() uint32 {
+	if x != nil {
+		return x.Permission
+	}
+	return 0
+}
+
+type FsImageQuota struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NamespaceQuota int64 `protobuf:"varint,1,opt,name=namespace_quota,json=namespaceQuota,proto3" json:"namespace_quota,omitempty"`
+	SpaceQuota     int64 `protobuf:"varint,2,opt,name=space_quota,json=spaceQuota,proto3" json:"space_quota,omitempty"`
+}
+
+func (x *FsImageQuota) Reset() {
+	*x = FsImageQuota{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageQuota) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageQuota) ProtoMessage() {}
+
+func (x *FsImageQuota) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageQuota.ProtoReflect.Descriptor instead.
+func (*FsImageQuota) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *FsImageQuota) GetNamespaceQuota() int64 {
+	if x != nil {
+		return x.NamespaceQuota
+	}
+	return 0
+}
+
+func (x *FsImageQuota) GetSpaceQuota() int64 {
+	if x != nil {
+		return x.SpaceQuota
+	}
+	return 0
+}
+
+// "directories": the children of every directory holding any
+type FsImageDirectorySection struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Directories []*FsImageDirectory `protobuf:"bytes,1,rep,name=directories,proto3" json:"directories,omitempty"`
+}
+
+func (x *FsImageDirectorySection) Reset() {
+	*x = FsImageDirectorySection{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageDirectorySection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageDirectorySection) ProtoMessage() {}
+
+func (x *FsImageDirectorySection) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageDirectorySection.ProtoReflect.Descriptor instead.
+func (*FsImageDirectorySection) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *FsImageDirectorySection) GetDirectories() []*FsImageDirectory {
+	if x != nil {
+		return x.Directories
+	}
+	return nil
+}
+
+type FsImageDirectory struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Positions in the inode section
+	Inode    uint32   `protobuf:"varint,1,opt,name=inode,proto3" json:"inode,omitempty"`
+	Children []uint32 `protobuf:"varint,2,rep,packed,name=children,proto3" json:"children,omitempty"`
+}
+
+func (x *FsImageDirectory) Reset() {
+	*x = FsImageDirectory{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageDirectory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageDirectory) ProtoMessage() {}
+
+func (x *FsImageDirectory) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageDirectory.ProtoReflect.Descriptor instead.
+func (*FsImageDirectory) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FsImageDirectory) GetInode() uint32 {
+	if x != nil {
+		return x.Inode
+	}
+	return 0
+}
+
+func (x *FsImageDirectory) GetChildren() []uint32 {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+// "snapshots": the snapshots of snapshottable directories
+type FsImageSnapshotSection struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Snapshots []*FsImageSnapshot `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"`
+}
+
+func (x *FsImageSnapshotSection) Reset() {
+	*x = FsImageSnapshotSection{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageSnapshotSection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageSnapshotSection) ProtoMessage() {}
+
+func (x *FsImageSnapshotSection) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageSnapshotSection.ProtoReflect.Descriptor instead.
+func (*FsImageSnapshotSection) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *FsImageSnapshotSection) GetSnapshots() []*FsImageSnapshot {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+type FsImageSnapshot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Position in the inode section of the snapshotted directory
+	Directory uint32 `protobuf:"varint,1,opt,name=directory,proto3" json:"directory,omitempty"`
+	Name      string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Timestamp int64  `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Position in the inode section of the root of the snapshot, a copy of the
+	// directory whose children are listed in the directory section
+	Root uint32 `protobuf:"varint,4,opt,name=root,proto3" json:"root,omitempty"`
+}
+
+func (x *FsImageSnapshot) Reset() {
+	*x = FsImageSnapshot{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_fsimage_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FsImageSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsImageSnapshot) ProtoMessage() {}
+
+func (x *FsImageSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_fsimage_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsImageSnapshot.ProtoReflect.Descriptor instead.
+func (*FsImageSnapshot) Descriptor() ([]byte, []int) {
+	return file_fsimage_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FsImageSnapshot) GetDirectory() uint32 {
+	if x != nil {
+		return x.Directory
+	}
+	return 0
+}
+
+func (x *FsImageSnapshot) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FsImageSnapshot) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *FsImageSnapshot) GetRoot() uint32 {
+	if x != nil {
+		return x.Root
+	}
+	return 0
+}
+
+var File_fsimage_proto protoreflect.FileDescriptor
+
+var file_fsimage_proto_rawDesc = []byte{
+	0x0a, 0x0d, 0x66, 0x73, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x04, 0x68, 0x64, 0x66, 0x73, 0x22, 0x70, 0x0a, 0x0d, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65,
+	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x0e, 0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74,
+	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d,
+	0x6c, 0x61, 0x79, 0x6f, 0x75, 0x74, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a,
+	0x0a, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x74, 0x78, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x08, 0x6c, 0x61, 0x73, 0x74, 0x54, 0x78, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x73,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x73,
+	0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x2e, 0x0a, 0x12, 0x46, 0x73, 0x49, 0x6d, 0x61,
+	0x67, 0x65, 0x53, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07,
+	0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x73, 0x22, 0x41, 0x0a, 0x13, 0x46, 0x73, 0x49, 0x6d, 0x61,
+	0x67, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a,
+	0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12,
+	0x2e, 0x68, 0x64, 0x66, 0x73, 0x2e, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x52, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x22, 0x47, 0x0a, 0x0c, 0x46, 0x73,
+	0x49, 0x6d, 0x61, 0x67, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x09, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x22, 0x41, 0x0a, 0x13, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x49, 0x6e,
+	0x6f, 0x64, 0x65, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x06, 0x69, 0x6e,
+	0x6f, 0x64, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x68, 0x64, 0x66,
+	0x73, 0x2e, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x49, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x06,
+	0x69, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0xfa, 0x02, 0x0a, 0x0c, 0x46, 0x73, 0x49, 0x6d, 0x61,
+	0x67, 0x65, 0x49, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6f,
+	0x77, 0x6e, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65,
+	0x72, 0x12, 0x14, 0x0a, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x05, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x15, 0x0a, 0x06, 0x69, 0x73, 0x5f, 0x64, 0x69,
+	0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x69, 0x73, 0x44, 0x69, 0x72, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69,
+	0x7a, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x12, 0x20, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x03, 0x61, 0x63, 0x6c, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x68, 0x64, 0x66, 0x73, 0x2e, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41, 0x63,
+	0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x03, 0x61, 0x63, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x06, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x12, 0x28, 0x0a, 0x05, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x18, 0x0c, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x12, 0x2e, 0x68, 0x64, 0x66, 0x73, 0x2e, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67,
+	0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x05, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x24, 0x0a,
+	0x0d, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x74, 0x61,
+	0x62, 0x6c, 0x65, 0x22, 0x6f, 0x0a, 0x0f, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x41, 0x63,
+	0x6c, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x22, 0x58, 0x0a, 0x0c, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x51,
+	0x75, 0x6f, 0x74, 0x61, 0x12, 0x27, 0x0a, 0x0f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
+	0x65, 0x5f, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x6e,
+	0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x1f, 0x0a,
+	0x0b, 0x73, 0x70, 0x61, 0x63, 0x65, 0x5f, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0a, 0x73, 0x70, 0x61, 0x63, 0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x22, 0x53,
+	0x0a, 0x17, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x44, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f,
+	0x72, 0x79, 0x53, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x38, 0x0a, 0x0b, 0x64, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x68, 0x64, 0x66, 0x73, 0x2e, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x44, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x52, 0x0b, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x69, 0x65, 0x73, 0x22, 0x44, 0x0a, 0x10, 0x46, 0x73, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x44, 0x69,
+	0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d, 0x52,
+	0x08, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x22, 0x4d, 0x0a, 0x16, 0x46, 0x73, 0x49,
+	0x6d, 0x61, 0x67, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x53, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x33, 0x0a, 0x09, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x68, 0x64, 0x66, 0x73, 0x2e, 0x46, 0x73,
+	0x49, 0x6d, 0x61, 0x67, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x09, 0x73,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x22, 0x75, 0x0a, 0x0f, 0x46, 0x73, 0x49, 0x6d,
+	0x61, 0x67, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x64,
+	0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09,
+	0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x72,
+	0x6f, 0x6f, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x72, 0x6f, 0x6f, 0x74, 0x42,
+	0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x61,
+	0x72, 0x72, 0x61, 0x73, 0x73, 0x65, 0x61, 0x79, 0x6f, 0x75, 0x62, 0x30, 0x31, 0x2f, 0x64, 0x61,
+	0x74, 0x61, 0x6e, 0x6f, 0x64, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x62,
+	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_fsimage_proto_rawDescOnce sync.Once
+	file_fsimage_proto_rawDescData = file_fsimage_proto_rawDesc
+)
+
+func file_fsimage_proto_rawDescGZIP() []byte {
+	file_fsimage_proto_rawDescOnce.Do(func() {
+		file_fsimage_proto_rawDescData = protoimpl.X.CompressGZIP(file_fsimage_proto_rawDescData)
+	})
+	return file_fsimage_proto_rawDescData
+}
+
+var file_fsimage_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_fsimage_proto_goTypes = []interface{}{
+	(*FsImageHeader)(nil),           // 0: hdfs.FsImageHeader
+	(*FsImageStringTable)(nil),      // 1: hdfs.FsImageStringTable
+	(*FsImageBlockSection)(nil),     // 2: hdfs.FsImageBlockSection
+	(*FsImageBlock)(nil),            // 3: hdfs.FsImageBlock
+	(*FsImageInodeSection)(nil),     // 4: hdfs.FsImageInodeSection
+	(*FsImageInode)(nil),            // 5: hdfs.FsImageInode
+	(*FsImageAclEntry)(nil),         // 6: hdfs.FsImageAclEntry
+	(*FsImageQuota)(nil),            // 7: hdfs.FsImageQuota
+	(*FsImageDirectorySection)(nil), // 8: hdfs.FsImageDirectorySection
+	(*FsImageDirectory)(nil),        // 9: hdfs.FsImageDirectory
+	(*FsImageSnapshotSection)(nil),  // 10: hdfs.FsImageSnapshotSection
+	(*FsImageSnapshot)(nil),         // 11: hdfs.FsImageSnapshot
+}
+var file_fsimage_proto_depIdxs = []int32{
+	3,  // 0: hdfs.FsImageBlockSection.blocks:type_name -> hdfs.FsImageBlock
+	5,  // 1: hdfs.FsImageInodeSection.inodes:type_name -> hdfs.FsImageInode
+	6,  // 2: hdfs.FsImageInode.acl:type_name -> hdfs.FsImageAclEntry
+	7,  // 3: hdfs.FsImageInode.quota:type_name -> hdfs.FsImageQuota
+	9,  // 4: hdfs.FsImageDirectorySection.directories:type_name -> hdfs.FsImageDirectory
+	11, // 5: hdfs.FsImageSnapshotSection.snapshots:type_name -> hdfs.FsImageSnapshot
+	6,  // [6:6] is the sub-list for method output_type
+	6,  // [6:6] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_fsimage_proto_init() }
+func file_fsimage_proto_init() {
+	if File_fsimage_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_fsimage_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageHeader); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fsimage_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageStringTable); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fsimage_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageBlockSection); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fsimage_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageBlock); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fsimage_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageInodeSection); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fsimage_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageInode); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fsimage_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageAclEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fsimage_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageQuota); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fsimage_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageDirectorySection); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fsimage_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageDirectory); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fsimage_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageSnapshotSection); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_fsimage_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FsImageSnapshot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_fsimage_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_fsimage_proto_goTypes,
+		DependencyIndexes: file_fsimage_proto_depIdxs,
+		MessageInfos:      file_fsimage_proto_msgTypes,
+	}.Build()
+	File_fsimage_proto = out.File
+	file_fsimage_proto_rawDesc = nil
+	file_fsimage_proto_goTypes = nil
+	file_fsimage_proto_depIdxs = nil
+}
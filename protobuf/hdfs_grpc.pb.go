@@ -19,8 +19,13 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	NameNodeService_RegisterDataNode_FullMethodName = "/hdfs.NameNodeService/RegisterDataNode"
-	NameNodeService_SendHeartbeat_FullMethodName    = "/hdfs.NameNodeService/SendHeartbeat"
+	NameNodeService_RegisterDataNode_FullMethodName        = "/hdfs.NameNodeService/RegisterDataNode"
+	NameNodeService_SendHeartbeat_FullMethodName           = "/hdfs.NameNodeService/SendHeartbeat"
+	NameNodeService_ReadFile_FullMethodName                = "/hdfs.NameNodeService/ReadFile"
+	NameNodeService_BlockReport_FullMethodName             = "/hdfs.NameNodeService/BlockReport"
+	NameNodeService_BlockReceivedAndDeleted_FullMethodName = "/hdfs.NameNodeService/BlockReceivedAndDeleted"
+	NameNodeService_Rename_FullMethodName                  = "/hdfs.NameNodeService/Rename"
+	NameNodeService_GetContentSummary_FullMethodName       = "/hdfs.NameNodeService/GetContentSummary"
 )
 
 // NameNodeServiceClient is the client API for NameNodeService service.
@@ -29,6 +34,11 @@ const (
 type NameNodeServiceClient interface {
 	RegisterDataNode(ctx context.Context, in *RegisterDataNodeRequest, opts ...grpc.CallOption) (*RegisterDataNodeResponse, error)
 	SendHeartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	ReadFile(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (NameNodeService_ReadFileClient, error)
+	BlockReport(ctx context.Context, in *BlockReportRequest, opts ...grpc.CallOption) (*BlockReportResponse, error)
+	BlockReceivedAndDeleted(ctx context.Context, in *IncrementalBlockReportRequest, opts ...grpc.CallOption) (*BlockReportResponse, error)
+	Rename(ctx context.Context, in *RenameRequest, opts ...grpc.CallOption) (*RenameResponse, error)
+	GetContentSummary(ctx context.Context, in *ContentSummaryRequest, opts ...grpc.CallOption) (*ContentSummaryResponse, error)
 }
 
 type nameNodeServiceClient struct {
@@ -57,12 +67,85 @@ func (c *nameNodeServiceClient) SendHeartbeat(ctx context.Context, in *Heartbeat
 	return out, nil
 }
 
+func (c *nameNodeServiceClient) ReadFile(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (NameNodeService_ReadFileClient, error) {
+	stream, err := c.cc.NewStream(ctx, &NameNodeService_ServiceDesc.Streams[0], NameNodeService_ReadFile_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nameNodeServiceReadFileClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type NameNodeService_ReadFileClient interface {
+	Recv() (*ReadFileResponse, error)
+	grpc.ClientStream
+}
+
+type nameNodeServiceReadFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *nameNodeServiceReadFileClient) Recv() (*ReadFileResponse, error) {
+	m := new(ReadFileResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *nameNodeServiceClient) BlockReport(ctx context.Context, in *BlockReportRequest, opts ...grpc.CallOption) (*BlockReportResponse, error) {
+	out := new(BlockReportResponse)
+	err := c.cc.Invoke(ctx, NameNodeService_BlockReport_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nameNodeServiceClient) BlockReceivedAndDeleted(ctx context.Context, in *IncrementalBlockReportRequest, opts ...grpc.CallOption) (*BlockReportResponse, error) {
+	out := new(BlockReportResponse)
+	err := c.cc.Invoke(ctx, NameNodeService_BlockReceivedAndDeleted_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nameNodeServiceClient) Rename(ctx context.Context, in *RenameRequest, opts ...grpc.CallOption) (*RenameResponse, error) {
+	out := new(RenameResponse)
+	err := c.cc.Invoke(ctx, NameNodeService_Rename_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nameNodeServiceClient) GetContentSummary(ctx context.Context, in *ContentSummaryRequest, opts ...grpc.CallOption) (*ContentSummaryResponse, error) {
+	out := new(ContentSummaryResponse)
+	err := c.cc.Invoke(ctx, NameNodeService_GetContentSummary_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // NameNodeServiceServer is the server API for NameNodeService service.
 // All implementations must embed UnimplementedNameNodeServiceServer
 // for forward compatibility
 type NameNodeServiceServer interface {
 	RegisterDataNode(context.Context, *RegisterDataNodeRequest) (*RegisterDataNodeResponse, error)
 	SendHeartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	ReadFile(*ReadFileRequest, NameNodeService_ReadFileServer) error
+	BlockReport(context.Context, *BlockReportRequest) (*BlockReportResponse, error)
+	BlockReceivedAndDeleted(context.Context, *IncrementalBlockReportRequest) (*BlockReportResponse, error)
+	Rename(context.Context, *RenameRequest) (*RenameResponse, error)
+	GetContentSummary(context.Context, *ContentSummaryRequest) (*ContentSummaryResponse, error)
 	mustEmbedUnimplementedNameNodeServiceServer()
 }
 
@@ -76,6 +159,21 @@ func (UnimplementedNameNodeServiceServer) RegisterDataNode(context.Context, *Reg
 func (UnimplementedNameNodeServiceServer) SendHeartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendHeartbeat not implemented")
 }
+func (UnimplementedNameNodeServiceServer) ReadFile(*ReadFileRequest, NameNodeService_ReadFileServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReadFile not implemented")
+}
+func (UnimplementedNameNodeServiceServer) BlockReport(context.Context, *BlockReportRequest) (*BlockReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockReport not implemented")
+}
+func (UnimplementedNameNodeServiceServer) BlockReceivedAndDeleted(context.Context, *IncrementalBlockReportRequest) (*BlockReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockReceivedAndDeleted not implemented")
+}
+func (UnimplementedNameNodeServiceServer) Rename(context.Context, *RenameRequest) (*RenameResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rename not implemented")
+}
+func (UnimplementedNameNodeServiceServer) GetContentSummary(context.Context, *ContentSummaryRequest) (*ContentSummaryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContentSummary not implemented")
+}
 func (UnimplementedNameNodeServiceServer) mustEmbedUnimplementedNameNodeServiceServer() {}
 
 // UnsafeNameNodeServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -125,6 +223,99 @@ func _NameNodeService_SendHeartbeat_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NameNodeService_ReadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NameNodeServiceServer).ReadFile(m, &nameNodeServiceReadFileServer{stream})
+}
+
+type NameNodeService_ReadFileServer interface {
+	Send(*ReadFileResponse) error
+	grpc.ServerStream
+}
+
+type nameNodeServiceReadFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *nameNodeServiceReadFileServer) Send(m *ReadFileResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _NameNodeService_BlockReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NameNodeServiceServer).BlockReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NameNodeService_BlockReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NameNodeServiceServer).BlockReport(ctx, req.(*BlockReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NameNodeService_BlockReceivedAndDeleted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IncrementalBlockReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NameNodeServiceServer).BlockReceivedAndDeleted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NameNodeService_BlockReceivedAndDeleted_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NameNodeServiceServer).BlockReceivedAndDeleted(ctx, req.(*IncrementalBlockReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NameNodeService_Rename_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NameNodeServiceServer).Rename(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NameNodeService_Rename_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NameNodeServiceServer).Rename(ctx, req.(*RenameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NameNodeService_GetContentSummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ContentSummaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NameNodeServiceServer).GetContentSummary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NameNodeService_GetContentSummary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NameNodeServiceServer).GetContentSummary(ctx, req.(*ContentSummaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // NameNodeService_ServiceDesc is the grpc.ServiceDesc for NameNodeService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -140,22 +331,52 @@ var NameNodeService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SendHeartbeat",
 			Handler:    _NameNodeService_SendHeartbeat_Handler,
 		},
+		{
+			MethodName: "BlockReport",
+			Handler:    _NameNodeService_BlockReport_Handler,
+		},
+		{
+			MethodName: "BlockReceivedAndDeleted",
+			Handler:    _NameNodeService_BlockReceivedAndDeleted_Handler,
+		},
+		{
+			MethodName: "Rename",
+			Handler:    _NameNodeService_Rename_Handler,
+		},
+		{
+			MethodName: "GetContentSummary",
+			Handler:    _NameNodeService_GetContentSummary_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ReadFile",
+			Handler:       _NameNodeService_ReadFile_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "hdfs.proto",
 }
 
 const (
 	DataNodeService_StoreBlock_FullMethodName    = "/hdfs.DataNodeService/StoreBlock"
 	DataNodeService_RetrieveBlock_FullMethodName = "/hdfs.DataNodeService/RetrieveBlock"
+	DataNodeService_WriteBlock_FullMethodName    = "/hdfs.DataNodeService/WriteBlock"
+	DataNodeService_ReadBlock_FullMethodName     = "/hdfs.DataNodeService/ReadBlock"
 )
 
 // DataNodeServiceClient is the client API for DataNodeService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type DataNodeServiceClient interface {
+	// Deprecated: Do not use.
+	// StoreBlock and RetrieveBlock carry a whole block in one message, which is
+	// bounded by gRPC's default message size. Use WriteBlock and ReadBlock.
 	StoreBlock(ctx context.Context, in *StoreBlockRequest, opts ...grpc.CallOption) (*StoreBlockResponse, error)
+	// Deprecated: Do not use.
 	RetrieveBlock(ctx context.Context, in *RetrieveBlockRequest, opts ...grpc.CallOption) (*RetrieveBlockResponse, error)
+	WriteBlock(ctx context.Context, opts ...grpc.CallOption) (DataNodeService_WriteBlockClient, error)
+	ReadBlock(ctx context.Context, in *ReadBlockRequest, opts ...grpc.CallOption) (DataNodeService_ReadBlockClient, error)
 }
 
 type dataNodeServiceClient struct {
@@ -166,6 +387,7 @@ func NewDataNodeServiceClient(cc grpc.ClientConnInterface) DataNodeServiceClient
 	return &dataNodeServiceClient{cc}
 }
 
+// Deprecated: Do not use.
 func (c *dataNodeServiceClient) StoreBlock(ctx context.Context, in *StoreBlockRequest, opts ...grpc.CallOption) (*StoreBlockResponse, error) {
 	out := new(StoreBlockResponse)
 	err := c.cc.Invoke(ctx, DataNodeService_StoreBlock_FullMethodName, in, out, opts...)
@@ -175,6 +397,7 @@ func (c *dataNodeServiceClient) StoreBlock(ctx context.Context, in *StoreBlockRe
 	return out, nil
 }
 
+// Deprecated: Do not use.
 func (c *dataNodeServiceClient) RetrieveBlock(ctx context.Context, in *RetrieveBlockRequest, opts ...grpc.CallOption) (*RetrieveBlockResponse, error) {
 	out := new(RetrieveBlockResponse)
 	err := c.cc.Invoke(ctx, DataNodeService_RetrieveBlock_FullMethodName, in, out, opts...)
@@ -184,12 +407,84 @@ func (c *dataNodeServiceClient) RetrieveBlock(ctx context.Context, in *RetrieveB
 	return out, nil
 }
 
+func (c *dataNodeServiceClient) WriteBlock(ctx context.Context, opts ...grpc.CallOption) (DataNodeService_WriteBlockClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DataNodeService_ServiceDesc.Streams[0], DataNodeService_WriteBlock_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataNodeServiceWriteBlockClient{stream}
+	return x, nil
+}
+
+type DataNodeService_WriteBlockClient interface {
+	Send(*WriteBlockRequest) error
+	CloseAndRecv() (*WriteBlockResponse, error)
+	grpc.ClientStream
+}
+
+type dataNodeServiceWriteBlockClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataNodeServiceWriteBlockClient) Send(m *WriteBlockRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *dataNodeServiceWriteBlockClient) CloseAndRecv() (*WriteBlockResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(WriteBlockResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *dataNodeServiceClient) ReadBlock(ctx context.Context, in *ReadBlockRequest, opts ...grpc.CallOption) (DataNodeService_ReadBlockClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DataNodeService_ServiceDesc.Streams[1], DataNodeService_ReadBlock_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &dataNodeServiceReadBlockClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DataNodeService_ReadBlockClient interface {
+	Recv() (*ReadBlockResponse, error)
+	grpc.ClientStream
+}
+
+type dataNodeServiceReadBlockClient struct {
+	grpc.ClientStream
+}
+
+func (x *dataNodeServiceReadBlockClient) Recv() (*ReadBlockResponse, error) {
+	m := new(ReadBlockResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DataNodeServiceServer is the server API for DataNodeService service.
 // All implementations must embed UnimplementedDataNodeServiceServer
 // for forward compatibility
 type DataNodeServiceServer interface {
+	// Deprecated: Do not use.
+	// StoreBlock and RetrieveBlock carry a whole block in one message, which is
+	// bounded by gRPC's default message size. Use WriteBlock and ReadBlock.
 	StoreBlock(context.Context, *StoreBlockRequest) (*StoreBlockResponse, error)
+	// Deprecated: Do not use.
 	RetrieveBlock(context.Context, *RetrieveBlockRequest) (*RetrieveBlockResponse, error)
+	WriteBlock(DataNodeService_WriteBlockServer) error
+	ReadBlock(*ReadBlockRequest, DataNodeService_ReadBlockServer) error
 	mustEmbedUnimplementedDataNodeServiceServer()
 }
 
@@ -203,6 +498,12 @@ func (UnimplementedDataNodeServiceServer) StoreBlock(context.Context, *StoreBloc
 func (UnimplementedDataNodeServiceServer) RetrieveBlock(context.Context, *RetrieveBlockRequest) (*RetrieveBlockResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RetrieveBlock not implemented")
 }
+func (UnimplementedDataNodeServiceServer) WriteBlock(DataNodeService_WriteBlockServer) error {
+	return status.Errorf(codes.Unimplemented, "method WriteBlock not implemented")
+}
+func (UnimplementedDataNodeServiceServer) ReadBlock(*ReadBlockRequest, DataNodeService_ReadBlockServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReadBlock not implemented")
+}
 func (UnimplementedDataNodeServiceServer) mustEmbedUnimplementedDataNodeServiceServer() {}
 
 // UnsafeDataNodeServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -252,6 +553,53 @@ func _DataNodeService_RetrieveBlock_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DataNodeService_WriteBlock_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DataNodeServiceServer).WriteBlock(&dataNodeServiceWriteBlockServer{stream})
+}
+
+type DataNodeService_WriteBlockServer interface {
+	SendAndClose(*WriteBlockResponse) error
+	Recv() (*WriteBlockRequest, error)
+	grpc.ServerStream
+}
+
+type dataNodeServiceWriteBlockServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataNodeServiceWriteBlockServer) SendAndClose(m *WriteBlockResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *dataNodeServiceWriteBlockServer) Recv() (*WriteBlockRequest, error) {
+	m := new(WriteBlockRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _DataNodeService_ReadBlock_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadBlockRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DataNodeServiceServer).ReadBlock(m, &dataNodeServiceReadBlockServer{stream})
+}
+
+type DataNodeService_ReadBlockServer interface {
+	Send(*ReadBlockResponse) error
+	grpc.ServerStream
+}
+
+type dataNodeServiceReadBlockServer struct {
+	grpc.ServerStream
+}
+
+func (x *dataNodeServiceReadBlockServer) Send(m *ReadBlockResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // DataNodeService_ServiceDesc is the grpc.ServiceDesc for DataNodeService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -268,6 +616,17 @@ var DataNodeService_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _DataNodeService_RetrieveBlock_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WriteBlock",
+			Handler:       _DataNodeService_WriteBlock_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ReadBlock",
+			Handler:       _DataNodeService_ReadBlock_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "hdfs.proto",
 }
@@ -0,0 +1,16 @@
+package protobuf
+
+import "hash/crc32"
+
+// PacketSize is the amount of block data carried by a single BlockPacket.
+// Packets are small enough that gRPC's flow control applies backpressure
+// long before a whole block is queued in memory: a sender blocks once the
+// receiver stops draining the stream.
+const PacketSize = 64 * 1024
+
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// PacketChecksum computes the checksum carried by a BlockPacket
+func PacketChecksum(data []byte) uint32 {
+	return crc32.Checksum(data, castagnoliTable)
+}